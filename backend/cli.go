@@ -0,0 +1,730 @@
+// Command line interface. `postgrescrutiniser <command>` runs a single action
+// against the server and exits, which lets checks gate CI pipelines and cron jobs.
+// Running without a command starts the web server.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/viper"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/compare"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/file"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/instance"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/resourceConfig"
+)
+
+// Exit codes returned by commands
+const (
+	exitOk          = 0 // command succeeded and, for `check`, nothing is suggested
+	exitError       = 1 // command failed or a check got an error
+	exitUsage       = 2 // invalid arguments, same code the flag package exits with
+	exitSuggestions = 3 // `check` found settings that have suggestions
+	exitDifferences = 3 // `compare` found settings that differ
+	exitNotApplied  = 3 // `sources` found configuration file entries that aren't in effect
+)
+
+type command struct {
+	usage       string // arguments shown in help
+	description string
+	run         func(args []string) int
+}
+
+var commands map[string]command
+
+// Filled in `init()` since `help` refers back to the map
+func init() {
+	commands = map[string]command{
+		"check":   {"[-format table|json] [-profile name] [-details] [-report json|markdown|html|sarif [-output file]] [offline flags] [setting...]", "Run checks and print suggestions. Exits with 3 if anything is suggested.", runCheckCommand},
+		"apply":   {"[-format table|json] [-profile name] [-dry_run] [-database name] [-role name] [setting...]", "Run checks and apply their suggestions with ALTER SYSTEM, or ALTER DATABASE and ALTER ROLE with -database and -role. Nothing is applied if any suggestion is invalid.", runApplyCommand},
+		"export":  {"[-format sql|conf|ansible|patroni|cloudnativepg] [-profile name] [-output file] [offline flags] [setting...]", "Run checks and write suggestions as configuration for other tools.", runExportCommand},
+		"backups": {"list [-format table|json] | restore <backup>", "List postgresql.auto.conf backups or restore one of them.", runBackupsCommand},
+		"compare": {"[-format table|json] [-all] [-baseline file | -baseline_name name | -save file] [instance...]", "Compare settings of instances, the default instance if none are given, with each other, a snapshot saved with -save or a named baseline. Exits with 3 if settings differ.", runCompareCommand},
+		"sources": {"[-format table|json] [-all] [offline flags]", "List configuration file entries that aren't in effect and why: shadowed, overridden by postgresql.auto.conf or another source, or invalid. Exits with 3 if there are any.", runSourcesCommand},
+		"reset":   {"", "Back up and empty postgresql.auto.conf, discarding all applied suggestions.", runResetCommand},
+		"help":    {"", "Print this help.", func(args []string) int { printUsage(); return exitOk }},
+	}
+}
+
+func printUsage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage:\n  %s [flags]                  start the web server\n  %s <command> [arguments]\n\nCommands:\n", filepath.Base(os.Args[0]), filepath.Base(os.Args[0]))
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(writer, "  %s %s\t%s\n", name, commands[name].usage, commands[name].description)
+	}
+	writer.Flush()
+
+	fmt.Fprintf(out, "\nOffline flags for `check` and `export` analyse a postgresql.conf without a server, see `%s check -h`.\n\nWeb server flags:\n", filepath.Base(os.Args[0]))
+	flag.PrintDefaults()
+}
+
+// Parses @args into @flags. Returns exit code to stop with if parsing didn't succeed.
+func parseFlags(flags *flag.FlagSet, args []string) (int, bool) {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOk, false
+		}
+		return exitUsage, false
+	}
+	return exitOk, true
+}
+
+func validFormat(format string) bool {
+	if format == "table" || format == "json" {
+		return true
+	}
+	fmt.Fprintf(os.Stderr, "invalid format %q, expected table or json\n", format)
+	return false
+}
+
+// Makes sure every name given on the command line is a registered check
+func validCheckNames(names []string) bool {
+	for _, name := range names {
+		if _, ok := resourceConfig.GetCheck(name); !ok {
+			fmt.Fprintf(os.Stderr, "unknown setting %q, available settings are: %v\n", name, resourceConfig.CheckNames())
+			return false
+		}
+	}
+	return true
+}
+
+// Sets up users and database connection for commands working on the live server.
+// Returns path to postgresql.conf along with the environment.
+func connect(logger *utils.Logger) (*environment, string, error) {
+	// Unlike the web server, commands run fine without dev.env
+	config, err := readConfig()
+	var notFound viper.ConfigFileNotFoundError
+	if err != nil && !errors.As(err, &notFound) {
+		logger.LogError(err)
+		return nil, "", err
+	}
+
+	env, err := initEnvironment(config, logger)
+	if err != nil {
+		return nil, "", err
+	}
+	if env.dbHandler == nil {
+		return nil, "", fmt.Errorf("no database connection")
+	}
+	if err := env.dbHandler.Ping(); err != nil {
+		logger.LogError(fmt.Errorf("Could not connect to PostgreSQL: %v", err))
+		utils.CloseDbConnection(env.dbHandler, logger)
+		return nil, "", err
+	}
+
+	configFile, err := utils.FindConfigFile(env.dbHandler, logger)
+	if err != nil {
+		utils.CloseDbConnection(env.dbHandler, logger)
+		return nil, "", err
+	}
+	return env, configFile, nil
+}
+
+// Loads settings from configuration files when offline analysis was requested, otherwise
+// from the server. Returned function closes the database connection, if there is one.
+func loadConfiguration(offline *offlineFlags, profile string, logger *utils.Logger) (*resourceConfig.Configuration, func(), error) {
+	if offline.enabled() {
+		source, err := offline.source(profile)
+		if err != nil {
+			logger.LogError(err)
+			return nil, nil, err
+		}
+		conf, err := resourceConfig.InitOfflineChecks(source, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return conf, func() {}, nil
+	}
+
+	env, configFile, err := connect(logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeConf := func() { utils.CloseDbConnection(env.dbHandler, logger) }
+
+	conf := resourceConfig.InitChecks(configFile, env.backupDir, env.dbHandler, env.dbInfo, env.appUser, env.postgresUser, env.service, logger)
+	if profile != "" {
+		if err := conf.SetProfile(profile, logger); err != nil {
+			closeConf()
+			return nil, nil, err
+		}
+	}
+	return conf, closeConf, nil
+}
+
+// Keeps only results for @names, or all of them if no names were given
+func selectResults(results map[string]resourceConfig.ResourceSetting, names []string) map[string]resourceConfig.ResourceSetting {
+	if len(names) == 0 {
+		return results
+	}
+	selected := make(map[string]resourceConfig.ResourceSetting, len(names))
+	for _, name := range names {
+		selected[name] = results[name]
+	}
+	return selected
+}
+
+// Suggestions of checks that succeeded, in the shape `PATCH /api/resource` accepts
+func collectSuggestions(results map[string]resourceConfig.ResourceSetting) resourceConfig.PatchResourceConfigsJSONBody {
+	suggestions := resourceConfig.PatchResourceConfigsJSONBody{}
+	for _, name := range sortedNames(results) {
+		if setting := results[name]; setting.Status() == resourceConfig.StatusSuggestion {
+			suggestions = append(suggestions, resourceConfig.ResourceConfigPatchSchema{Name: name, SuggestedValue: setting.SuggestedValue})
+		}
+	}
+	return suggestions
+}
+
+func sortedNames(results map[string]resourceConfig.ResourceSetting) []string {
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func printJSON(data any) int {
+	output, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed formatting output: %v\n", err)
+		return exitError
+	}
+	fmt.Println(string(output))
+	return exitOk
+}
+
+func printCheckTable(results map[string]resourceConfig.ResourceSetting, details bool) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tVALUE\tUNIT\tSUGGESTED\tSTATUS")
+	for _, name := range sortedNames(results) {
+		setting := results[name]
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", name, setting.Value, setting.Unit, setting.SuggestedValue, setting.Status())
+	}
+	writer.Flush()
+
+	if !details {
+		return
+	}
+	for _, name := range sortedNames(results) {
+		fmt.Printf("\n%s:\n  %s\n", name, results[name].Details)
+		if setting := results[name]; setting.SourceFile != "" {
+			fmt.Printf("  Set in %s:%d\n", setting.SourceFile, setting.SourceLine)
+		} else if setting.Source != "" {
+			fmt.Printf("  Value comes from: %s\n", setting.Source)
+		}
+		for _, table := range results[name].TableSuggestions {
+			fmt.Printf("  %s\n", table.Statement)
+		}
+	}
+}
+
+// Renders @report in @format and writes it to @output, or standard output if empty
+func writeReport(report *resourceConfig.Report, format string, output string, logger *utils.Logger) int {
+	content, _, err := report.Render(resourceConfig.GetReportParamsFormat(format))
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed rendering %s report: %v", format, err))
+		return exitError
+	}
+	return writeOutput(content, output, logger)
+}
+
+// Writes @content to @output, or standard output if empty
+func writeOutput(content []byte, output string, logger *utils.Logger) int {
+	if output == "" {
+		os.Stdout.Write(content)
+		return exitOk
+	}
+	if err := os.WriteFile(output, content, 0644); err != nil {
+		logger.LogError(fmt.Errorf("Could not write %s: %v", output, err))
+		return exitError
+	}
+	return exitOk
+}
+
+// `check` command. Works against the live server, or against configuration files when -conf is given.
+func runCheckCommand(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	format := flags.String("format", "table", "Output format: table or json.")
+	profile := flags.String("profile", "", "Workload profile to run checks with. Persisted when checking a live server.")
+	details := flags.Bool("details", false, "Print why each suggestion was made below the table.")
+	report := flags.String("report", "", "Print a report instead: json, markdown, html or sarif. Includes host facts suggestions are based on.")
+	output := flags.String("output", "", "Write the report to this file instead of standard output.")
+	offline := registerOfflineFlags(flags)
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if !validFormat(*format) || !validCheckNames(flags.Args()) {
+		return exitUsage
+	}
+	if *report != "" {
+		if err := resourceConfig.ValidateReportFormat(*report); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+	} else if *output != "" {
+		fmt.Fprintln(os.Stderr, "-output can only be used together with -report")
+		return exitUsage
+	}
+
+	// 1. Load settings from configuration files or the server
+	logger := utils.InitCliLogging()
+	conf, closeConf, err := loadConfiguration(offline, *profile, logger)
+	if err != nil {
+		return exitError
+	}
+	defer closeConf()
+
+	// 2. Run checks and print results
+	results := selectResults(*resourceConfig.RunChecks(conf, logger), flags.Args())
+	if *report != "" {
+		if code := writeReport(conf.BuildReport(results, logger), *report, *output, logger); code != exitOk {
+			return code
+		}
+	} else if *format == "json" {
+		if code := printJSON(results); code != exitOk {
+			return code
+		}
+	} else {
+		printCheckTable(results, *details)
+	}
+
+	// 3. Errors take precedence over suggestions so that broken checks aren't mistaken for findings
+	exitCode := exitOk
+	for _, setting := range results {
+		switch setting.Status() {
+		case resourceConfig.StatusError:
+			return exitError
+		case resourceConfig.StatusSuggestion:
+			exitCode = exitSuggestions
+		}
+	}
+	return exitCode
+}
+
+// Prints reload, restart, health check and rollback steps taken after changing configuration
+func printSteps(steps []utils.ChangeStep) {
+	for _, step := range steps {
+		status := "ok"
+		if !step.Success {
+			status = "failed"
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s - %s\n", step.Action, status, step.Details)
+	}
+}
+
+func printValidation(validation *resourceConfig.SuggestionValidation, format string) {
+	if format == "json" {
+		printJSON(validation)
+		return
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tVALUE\tNORMALIZED\tCONTEXT\tVALID\tREASON")
+	for _, verdict := range validation.Items {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%t\t%s\n", verdict.Name, verdict.Value, verdict.NormalizedValue, verdict.Context, verdict.Valid, verdict.Reason)
+	}
+	writer.Flush()
+}
+
+// `apply` command. Applies every suggestion, or only suggestions for the given settings.
+func runApplyCommand(args []string) int {
+	flags := flag.NewFlagSet("apply", flag.ContinueOnError)
+	format := flags.String("format", "table", "Output format: table or json.")
+	profile := flags.String("profile", "", "Workload profile to run checks with. Persisted on the server.")
+	dryRun := flags.Bool("dry_run", false, "Only validate suggestions against pg_settings, nothing is applied.")
+	database := flags.String("database", "", "Apply suggestions for this database only with ALTER DATABASE ... SET.")
+	role := flags.String("role", "", "Apply suggestions for this role only with ALTER ROLE ... SET. Together with -database, only in that database.")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if !validFormat(*format) || !validCheckNames(flags.Args()) {
+		return exitUsage
+	}
+
+	logger := utils.InitCliLogging()
+	env, configFile, err := connect(logger)
+	if err != nil {
+		return exitError
+	}
+	defer utils.CloseDbConnection(env.dbHandler, logger)
+
+	conf := resourceConfig.InitChecks(configFile, env.backupDir, env.dbHandler, env.dbInfo, env.appUser, env.postgresUser, env.service, logger)
+	if *profile != "" {
+		if err := conf.SetProfile(*profile, logger); err != nil {
+			return exitError
+		}
+	}
+
+	// 1. Collect suggestions of checks that succeeded
+	results := selectResults(*resourceConfig.RunChecks(conf, logger), flags.Args())
+	suggestions := collectSuggestions(results)
+	if len(suggestions) == 0 {
+		fmt.Fprintln(os.Stderr, "Nothing to apply")
+		return exitOk
+	}
+	for i := range suggestions {
+		if *database != "" {
+			suggestions[i].Database = database
+		}
+		if *role != "" {
+			suggestions[i].Role = role
+		}
+	}
+
+	// 2. Validate them and, unless this is a dry run, apply them. Nothing is applied if any is invalid.
+	var validation *resourceConfig.SuggestionValidation
+	if *dryRun {
+		validation, err = conf.ValidateSuggestions(&suggestions, logger)
+	} else {
+		validation, err = conf.ApplySuggestions(&suggestions, logger)
+	}
+	if validation != nil && (*dryRun || !validation.Valid) {
+		printValidation(validation, *format)
+		if !validation.Valid {
+			return exitError
+		}
+		return exitOk
+	}
+	if validation != nil {
+		printSteps(validation.Steps)
+	}
+	if err != nil {
+		return exitError
+	}
+	utils.NewAuditLog(utils.AuditLogFile).Record(resourceConfig.SuggestionsAuditEntry(instance.DefaultId, utils.CliActor(), suggestions), logger)
+
+	if *format == "json" {
+		return printJSON(suggestions)
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tPREVIOUS\tAPPLIED\tUNIT")
+	for _, suggestion := range suggestions {
+		setting := results[suggestion.Name]
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", suggestion.Name, setting.Value, suggestion.SuggestedValue, setting.Unit)
+	}
+	writer.Flush()
+	return exitOk
+}
+
+// `export` command. Writes suggestions as configuration for other tools instead of applying them.
+func runExportCommand(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", "sql", "Export format: sql, conf, ansible, patroni or cloudnativepg.")
+	profile := flags.String("profile", "", "Workload profile to run checks with. Persisted when checking a live server.")
+	output := flags.String("output", "", "Write to this file instead of standard output.")
+	offline := registerOfflineFlags(flags)
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if err := resourceConfig.ValidateExportFormat(*format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if !validCheckNames(flags.Args()) {
+		return exitUsage
+	}
+
+	logger := utils.InitCliLogging()
+	conf, closeConf, err := loadConfiguration(offline, *profile, logger)
+	if err != nil {
+		return exitError
+	}
+	defer closeConf()
+
+	results := selectResults(*resourceConfig.RunChecks(conf, logger), flags.Args())
+	content, _, err := conf.ExportSuggestions(collectSuggestions(results), resourceConfig.ExportResourceConfigsParamsFormat(*format))
+	if err != nil {
+		logger.LogError(err)
+		return exitError
+	}
+	return writeOutput(content, *output, logger)
+}
+
+// `backups list` and `backups restore <backup>` commands
+func runBackupsCommand(args []string) int {
+	if len(args) == 0 || (args[0] != "list" && args[0] != "restore") {
+		fmt.Fprintf(os.Stderr, "Usage: %s backups %s\n", filepath.Base(os.Args[0]), commands["backups"].usage)
+		return exitUsage
+	}
+
+	flags := flag.NewFlagSet("backups "+args[0], flag.ContinueOnError)
+	format := flags.String("format", "table", "Output format: table or json. Only used by list.")
+	if code, ok := parseFlags(flags, args[1:]); !ok {
+		return code
+	}
+	if !validFormat(*format) {
+		return exitUsage
+	}
+
+	// Same validation as the web API, so that other files in the backups directory can't be touched
+	backupName := ""
+	if args[0] == "restore" {
+		validate := validator.New()
+		validate.RegisterValidation(`backup`, utils.ValidateAutoConfBackup)
+		if flags.NArg() != 1 || validate.Var(flags.Arg(0), "required,backup") != nil {
+			fmt.Fprintln(os.Stderr, `restore needs a single backup name matching postgresql.auto.conf_(\d{10})$`)
+			return exitUsage
+		}
+		backupName = flags.Arg(0)
+	}
+
+	logger := utils.InitCliLogging()
+	env, configFile, err := connect(logger)
+	if err != nil {
+		return exitError
+	}
+	defer utils.CloseDbConnection(env.dbHandler, logger)
+	currentFile := filepath.Dir(configFile) + "/postgresql.auto.conf"
+
+	if args[0] == "restore" {
+		restore, err := file.RestoreBackup(env.postgresUser.Username, env.backupDir+"/"+backupName, currentFile, env.appUser, env.dbHandler, env.service, logger)
+		if restore != nil {
+			printSteps(restore.Steps)
+		}
+		if err != nil {
+			return exitError
+		}
+		utils.NewAuditLog(utils.AuditLogFile).Record(utils.AuditEntry{Instance: instance.DefaultId, Actor: utils.CliActor(), Action: utils.AuditRestoreBackup, Backup: backupName}, logger)
+		fmt.Printf("Restored %s\n", backupName)
+		return exitOk
+	}
+
+	backups, err := file.ListBackups(env.backupDir, currentFile, logger)
+	if err != nil {
+		return exitError
+	}
+	if *format == "json" {
+		return printJSON(backups)
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tCREATED\tCHANGED LINES\tROLE SETTINGS")
+	for _, backup := range *backups {
+		changed := 0
+		for _, line := range backup.Diff {
+			if line.Type != file.Equal {
+				changed++
+			}
+		}
+		roleSettings := "-"
+		if backup.RoleSettings != nil {
+			roleSettings = strconv.Itoa(len(*backup.RoleSettings))
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%s\n", backup.Name, backup.Time.Format("2006-01-02 15:04:05"), changed, roleSettings)
+	}
+	writer.Flush()
+	return exitOk
+}
+
+// `reset` command
+func runResetCommand(args []string) int {
+	flags := flag.NewFlagSet("reset", flag.ContinueOnError)
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	logger := utils.InitCliLogging()
+	env, configFile, err := connect(logger)
+	if err != nil {
+		return exitError
+	}
+	defer utils.CloseDbConnection(env.dbHandler, logger)
+
+	conf := resourceConfig.InitChecks(configFile, env.backupDir, env.dbHandler, env.dbInfo, env.appUser, env.postgresUser, env.service, logger)
+	if err := conf.DiscardConfigs(logger); err != nil {
+		return exitError
+	}
+	utils.NewAuditLog(utils.AuditLogFile).Record(utils.AuditEntry{Instance: instance.DefaultId, Actor: utils.CliActor(), Action: utils.AuditReset}, logger)
+	fmt.Println("postgresql.auto.conf was reset")
+	return exitOk
+}
+
+// `compare` command
+func runCompareCommand(args []string) int {
+	flags := flag.NewFlagSet("compare", flag.ContinueOnError)
+	format := flags.String("format", "table", "Output format: table or json.")
+	all := flags.Bool("all", false, "List every setting, not only those that differ.")
+	baselineFile := flags.String("baseline", "", "Compare with a snapshot saved with -save.")
+	baselineName := flags.String("baseline_name", "", "Compare with a named baseline saved through the API.")
+	saveFile := flags.String("save", "", "Save a snapshot of the instance to this file instead of comparing.")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if !validFormat(*format) {
+		return exitUsage
+	}
+	instanceIds := flags.Args()
+	if len(instanceIds) == 0 {
+		instanceIds = []string{instance.DefaultId}
+	}
+	if *saveFile != "" && (*baselineFile != "" || *baselineName != "" || len(instanceIds) > 1) {
+		fmt.Fprintln(os.Stderr, "-save takes a snapshot of a single instance and can't be combined with -baseline or -baseline_name")
+		return exitUsage
+	}
+	if *baselineFile != "" && *baselineName != "" {
+		fmt.Fprintln(os.Stderr, "either -baseline or -baseline_name can be given, not both")
+		return exitUsage
+	}
+
+	// 1. Read the baseline before connecting, so a bad one fails fast
+	logger := utils.InitCliLogging()
+	var baseline *compare.SettingsSnapshot
+	if *baselineFile != "" {
+		content, err := os.ReadFile(*baselineFile)
+		if err == nil {
+			baseline = &compare.SettingsSnapshot{}
+			err = json.Unmarshal(content, baseline)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not read baseline %s: %v\n", *baselineFile, err)
+			return exitError
+		}
+	}
+	if *baselineName != "" {
+		stored, err := compare.NewBaselineStore(compare.DefaultBaselineDir, logger).Baseline(*baselineName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not read baseline %s: %v\n", *baselineName, err)
+			return exitError
+		}
+		baseline = &stored.Snapshot
+	}
+
+	env, _, err := connect(logger)
+	if err != nil {
+		return exitError
+	}
+	defer utils.CloseDbConnection(env.dbHandler, logger)
+	registry := newRegistry(env, logger)
+
+	// 2. Save a snapshot
+	if *saveFile != "" {
+		connection, err := registry.Connection(instanceIds[0])
+		if err != nil {
+			logger.LogError(err)
+			return exitError
+		}
+		snapshot, err := compare.TakeSnapshot(connection.DbHandler, instanceIds[0], logger)
+		if err != nil {
+			return exitError
+		}
+		content, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			logger.LogError(err)
+			return exitError
+		}
+		return writeOutput(append(content, '\n'), *saveFile, logger)
+	}
+
+	// 3. Compare
+	comparison, err := compare.CompareInstances(registry, instanceIds, baseline, *all, logger)
+	if err != nil {
+		logger.LogError(err)
+		return exitError
+	}
+	code := exitOk
+	if comparison.Differing > 0 {
+		code = exitDifferences
+	}
+	if *format == "json" {
+		if printJSON(comparison) != exitOk {
+			return exitError
+		}
+		return code
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "NAME\t%s\n", strings.ToUpper(strings.Join(comparison.Sources, "\t")))
+	for _, setting := range comparison.Settings {
+		values := make([]string, len(setting.Values))
+		for i, value := range setting.Values {
+			values[i] = "-"
+			if value.Value != nil {
+				values[i] = *value.Value
+			}
+		}
+		fmt.Fprintf(writer, "%s\t%s\n", setting.Name, strings.Join(values, "\t"))
+	}
+	writer.Flush()
+	fmt.Printf("\n%d of %d settings differ\n", comparison.Differing, comparison.Compared)
+	return code
+}
+
+// `sources` command
+func runSourcesCommand(args []string) int {
+	flags := flag.NewFlagSet("sources", flag.ContinueOnError)
+	format := flags.String("format", "table", "Output format: table or json.")
+	all := flags.Bool("all", false, "Also list entries that are in effect.")
+	offline := registerOfflineFlags(flags)
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if !validFormat(*format) {
+		return exitUsage
+	}
+
+	logger := utils.InitCliLogging()
+	conf, closeConf, err := loadConfiguration(offline, "", logger)
+	if err != nil {
+		return exitError
+	}
+	defer closeConf()
+
+	entries, err := conf.GetFileSettings(*all, logger)
+	if err != nil {
+		return exitError
+	}
+	code := exitOk
+	for _, entry := range entries {
+		if entry.Status != resourceConfig.Applied {
+			code = exitNotApplied
+		}
+	}
+	if *format == "json" {
+		if printJSON(entries) != exitOk {
+			return exitError
+		}
+		return code
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tSETTING\tFILE\tSTATUS\tREASON")
+	for _, entry := range entries {
+		reason := ""
+		if entry.Error != nil {
+			reason = *entry.Error
+		} else if entry.OverriddenBy != nil {
+			reason = *entry.OverriddenBy
+		}
+		if entry.RoleOverrides != nil {
+			scopes := []string{}
+			for _, override := range *entry.RoleOverrides {
+				scopes = append(scopes, override.Scope())
+			}
+			if reason != "" {
+				reason += "; "
+			}
+			reason += "also set for " + strings.Join(scopes, ", ")
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s:%d\t%s\t%s\n", entry.Name, entry.Setting, entry.Sourcefile, entry.Sourceline, entry.Status, reason)
+	}
+	writer.Flush()
+	return code
+}
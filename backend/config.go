@@ -10,24 +10,51 @@ import (
 type Config struct {
 	JWT_secret_key string `mapstructure:"JWT_SECRET_KEY"`
 	Backend_port   int    `mapstructure:"BACKEND_PORT"`
+
+	// How PostgreSQL is restarted, see utils.ServiceConfig
+	Service_controller string `mapstructure:"SERVICE_CONTROLLER"`
+	Systemd_unit       string `mapstructure:"SYSTEMD_UNIT"`
+	Pg_cluster         string `mapstructure:"PG_CLUSTER"`
+	Pg_ctl_path        string `mapstructure:"PG_CTL_PATH"`
+	Pg_data_directory  string `mapstructure:"PG_DATA_DIRECTORY"`
 }
 
 func LoadConfig(logger *utils.Logger) (c Config, err error) {
+	c, err = readConfig()
+	if err != nil {
+		logger.LogFatal(fmt.Errorf("Could not load .env file configs: %v", err))
+	}
+	return
+}
+
+// Reads dev.env from the working directory. Every setting can also be given as an
+// environment variable, so the returned config is usable even if the file is missing.
+func readConfig() (c Config, err error) {
 	viper.AddConfigPath(".")
 	viper.SetConfigName("dev")
 	viper.SetConfigType("env")
 
+	// Environment variables are only looked up for keys viper knows about
+	viper.SetDefault("SERVICE_CONTROLLER", utils.ControllerSystemd)
+	for _, key := range []string{"SYSTEMD_UNIT", "PG_CLUSTER", "PG_CTL_PATH", "PG_DATA_DIRECTORY"} {
+		viper.SetDefault(key, "")
+	}
 	viper.AutomaticEnv()
 
-	err = viper.ReadInConfig()
-	if err != nil {
-		logger.LogFatal(fmt.Errorf("Could not load .env file configs: %v", err))
-		return
-	}
-	err = viper.Unmarshal(&c)
-	if err != nil {
-		logger.LogFatal(fmt.Errorf("Could not unmarshal .env file configs: %v", err))
+	readErr := viper.ReadInConfig()
+	if err = viper.Unmarshal(&c); err != nil {
+		return c, fmt.Errorf("Could not unmarshal .env file configs: %v", err)
 	}
+	return c, readErr
+}
 
-	return
+// Service controller settings as expected by utils.NewServiceController
+func (c Config) ServiceConfig() utils.ServiceConfig {
+	return utils.ServiceConfig{
+		Controller:    c.Service_controller,
+		SystemdUnit:   c.Systemd_unit,
+		Cluster:       c.Pg_cluster,
+		PgCtlPath:     c.Pg_ctl_path,
+		DataDirectory: c.Pg_data_directory,
+	}
 }
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"database/sql"
 	"flag"
 	"fmt"
+	"os"
+	"strconv"
 
 	"github.com/Globys031/PostgreScrutiniser/backend/utils"
 	"github.com/Globys031/PostgreScrutiniser/backend/web"
 	"github.com/Globys031/PostgreScrutiniser/backend/web/auth"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/instance"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/resourceConfig"
 )
 
 var (
@@ -18,13 +23,73 @@ var (
 	backupDir       = "/usr/local/postgrescrutiniser/backups"
 )
 
+// Users and database connection shared by the web server and command line interface
+type environment struct {
+	appUser      *utils.User
+	postgresUser *utils.User
+	dbHandler    *sql.DB
+	dbInfo       *utils.DbConnectionInfo
+	service      utils.ServiceController // restarts PostgreSQL
+	backupDir    string                  // backups of the default instance
+	config       Config
+}
+
 func main() {
+	// Subcommands are handled by the command line interface, see cli.go
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			os.Exit(command.run(os.Args[2:]))
+		}
+	}
+
+	flag.Usage = printUsage
 	flag.Parse() // parses the above flag variables
 
 	////////////////////////
 	// Initialise logging
 	logger := utils.InitLogging()
 
+	//////////////////////////
+	// Loads configs
+	config, _ := LoadConfig(logger)
+
+	env, err := initEnvironment(config, logger)
+	if err != nil {
+		return
+	}
+	appPort := config.Backend_port
+
+	jwt := &auth.JwtWrapper{
+		SecretKey:       config.JWT_secret_key,
+		Issuer:          "postgre-scrutiniser",
+		ExpirationHours: 4, // token expires after 4 hours
+	}
+	//////////////////////////
+
+	// Other instances are registered through the API, see web/instance
+	registry := newRegistry(env, logger)
+	//////////////////////////
+
+	//////////////////////////
+	// Initialise webserver and routes
+	router := web.RegisterRoutes(jwt, registry, env.appUser, backupDir, logger)
+
+	// router := web.RegisterRoutes(authSvc)
+	Addr := fmt.Sprintf(":%d", appPort)
+	if *enableTls {
+		if err := router.RunTLS(Addr, *tlsCertFilePath, *tlsKeyFilePath); err != nil {
+			logger.LogFatal(fmt.Errorf("failed starting https backend server: %v", err))
+		}
+	} else {
+		if err := router.Run(Addr); err != nil {
+			logger.LogFatal(fmt.Errorf("failed starting http backend server: %v", err))
+		}
+	}
+}
+
+// Looks up application and PostgreSQL users, connects to the database
+// and sets up the service controller selected in @config
+func initEnvironment(config Config, logger *utils.Logger) (*environment, error) {
 	////////////////////////
 	// Save main postgres user and our app's user info
 	appUserUid, appUserGid, err := utils.GetUserIds(appUsername, logger)
@@ -35,7 +100,7 @@ func main() {
 	}
 	if err != nil {
 		logger.LogError(fmt.Errorf("Could not find our main application user:  %v", err))
-		return
+		return nil, err
 	}
 
 	_, postgrePort, _, postgreUsername, password, _ := utils.ParsePgpassFile(appUser, logger)
@@ -47,38 +112,95 @@ func main() {
 	}
 	if err != nil {
 		logger.LogError(fmt.Errorf("Could not find main PostgreSql user:  %v", err))
-		return
+		return nil, err
 	}
 
 	////////////////////////
 	// Initialise database connection
+	dbInfo := &utils.DbConnectionInfo{
+		Hostname: hostname,
+		User:     postgresUser.Username,
+		Password: password,
+		Port:     postgrePort,
+	}
 	dbHandler, _ := utils.InitDbConnection(hostname, postgresUser.Username, password, postgrePort, logger)
+	if dbHandler != nil {
+		// Checks use this to skip settings the server doesn't have
+		dbInfo.ServerVersion, _ = utils.GetServerVersion(dbHandler, logger)
+	}
 
-	//////////////////////////
-	// Loads configs
-	config, _ := LoadConfig(logger)
-	appPort := config.Backend_port
+	////////////////////////
+	// Pick how PostgreSQL gets restarted
+	service, err := utils.NewServiceController(config.ServiceConfig(), dbHandler, postgresUser.Username, logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Could not set up service controller: %v", err))
+		utils.CloseDbConnection(dbHandler, logger)
+		return nil, err
+	}
 
-	jwt := &auth.JwtWrapper{
-		SecretKey:       config.JWT_secret_key,
-		Issuer:          "postgre-scrutiniser",
-		ExpirationHours: 4, // token expires after 4 hours
+	////////////////////////
+	// Backups and the workload profile used to be shared, before there were several instances
+	instance.MigrateBackups(backupDir, logger)
+	resourceConfig.MigrateProfile(instance.BackupDir(backupDir, instance.DefaultId), logger)
+
+	return &environment{
+		appUser:      appUser,
+		postgresUser: postgresUser,
+		dbHandler:    dbHandler,
+		dbInfo:       dbInfo,
+		service:      service,
+		backupDir:    instance.BackupDir(backupDir, instance.DefaultId),
+		config:       config,
+	}, nil
+}
+
+// Registry of instances, with the default instance connected to through @env
+func newRegistry(env *environment, logger *utils.Logger) *instance.Registry {
+	return instance.NewRegistry(instance.DefaultStoreFile, backupDir, env.appUser, defaultConnection(env, logger), logger)
+}
+
+// Connection to the instance configured in ~/.pgpass and dev.env, routes without an instance act on it
+func defaultConnection(env *environment, logger *utils.Logger) *instance.Connection {
+	port, _ := strconv.Atoi(env.dbInfo.Port)
+	name := "Local instance"
+
+	// Describe the service controller as configured in dev.env, systemd is what an empty one means
+	serviceConfig := env.config.ServiceConfig()
+	serviceController := &instance.ServiceController{Controller: instance.Systemd}
+	if serviceConfig.Controller != "" {
+		serviceController.Controller = instance.ServiceControllerController(serviceConfig.Controller)
 	}
-	//////////////////////////
+	serviceController.SystemdUnit = optional(serviceConfig.SystemdUnit)
+	serviceController.Cluster = optional(serviceConfig.Cluster)
+	serviceController.PgCtlPath = optional(serviceConfig.PgCtlPath)
 
-	//////////////////////////
-	// Initialise webserver and routes
-	router := web.RegisterRoutes(jwt, dbHandler, appUser, postgresUser, backupDir, logger)
+	configFile := ""
+	if env.dbHandler != nil {
+		configFile, _ = utils.FindConfigFile(env.dbHandler, logger)
+	}
 
-	// router := web.RegisterRoutes(authSvc)
-	Addr := fmt.Sprintf(":%d", appPort)
-	if *enableTls {
-		if err := router.RunTLS(Addr, *tlsCertFilePath, *tlsKeyFilePath); err != nil {
-			logger.LogFatal(fmt.Errorf("failed starting https backend server: %v", err))
-		}
-	} else {
-		if err := router.Run(Addr); err != nil {
-			logger.LogFatal(fmt.Errorf("failed starting http backend server: %v", err))
-		}
+	return &instance.Connection{
+		Instance: instance.Instance{
+			Name:              &name,
+			Host:              env.dbInfo.Hostname,
+			Port:              port,
+			Username:          env.dbInfo.User,
+			CredentialsSource: instance.Pgpass,
+			DataDirectory:     optional(serviceConfig.DataDirectory),
+			ServiceController: serviceController,
+		},
+		DbHandler:    env.dbHandler,
+		DbInfo:       env.dbInfo,
+		PostgresUser: env.postgresUser,
+		Service:      env.service,
+		ConfigFile:   configFile,
+		Local:        true, // configured next to PostgreScrutiniser, with a service controller of its own
+	}
+}
+
+func optional(value string) *string {
+	if value == "" {
+		return nil
 	}
+	return &value
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/resourceConfig"
+)
+
+// Flags for analysing configuration files without a running server
+type offlineFlags struct {
+	conf      *string
+	autoConf  *string
+	hardware  *string
+	memory    *string
+	cpus      *int
+	disk      *string
+	pgVersion *string
+}
+
+func registerOfflineFlags(flags *flag.FlagSet) *offlineFlags {
+	return &offlineFlags{
+		conf:      flags.String("conf", "", "Analyse this postgresql.conf without connecting to a server."),
+		autoConf:  flags.String("auto_conf", "", "postgresql.auto.conf read after -conf. Defaults to the one next to it, if any."),
+		hardware:  flags.String("hardware", "", "JSON file describing the hardware used for offline analysis."),
+		memory:    flags.String("memory", "", "Total memory for offline analysis, e.g. 16GB. Overrides -hardware."),
+		cpus:      flags.Int("cpus", 0, "Number of CPUs for offline analysis. Overrides -hardware."),
+		disk:      flags.String("disk", "", "Storage the data directory is on for offline analysis: ssd, hdd or unknown. Overrides -hardware."),
+		pgVersion: flags.String("pg_version", "", "PostgreSQL major version the configuration is meant for, e.g. 16. Newest version is assumed if empty."),
+	}
+}
+
+// Whether offline analysis was requested
+func (flags *offlineFlags) enabled() bool {
+	return *flags.conf != ""
+}
+
+// Builds what to analyse from flags. Hardware is read from -hardware first, individual flags override it.
+func (flags *offlineFlags) source(profile string) (*resourceConfig.OfflineSource, error) {
+	hardware := &resourceConfig.Hardware{}
+	if *flags.hardware != "" {
+		var err error
+		if hardware, err = resourceConfig.LoadHardware(*flags.hardware); err != nil {
+			return nil, err
+		}
+	}
+	if *flags.memory != "" {
+		hardware.Memory = *flags.memory
+	}
+	if *flags.cpus != 0 {
+		hardware.Cpus = *flags.cpus
+	}
+	if *flags.disk != "" {
+		hardware.Disk = *flags.disk
+	}
+
+	source := &resourceConfig.OfflineSource{
+		ConfigFile:     *flags.conf,
+		AutoConfigFile: *flags.autoConf,
+		Hardware:       hardware,
+		Profile:        profile,
+	}
+	if *flags.pgVersion != "" {
+		version, err := utils.ParseMajorVersion(*flags.pgVersion)
+		if err != nil {
+			return nil, err
+		}
+		source.ServerVersion = version
+	}
+	return source, nil
+}
@@ -0,0 +1,170 @@
+// Activating configuration changes. After postgresql.auto.conf was changed the
+// server is reloaded, restarted if needed and probed. If it doesn't come back,
+// the backup taken before the change is put back so the service isn't left down.
+
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// How long PostgreSQL gets to answer after a reload or restart
+const HealthCheckTimeout = 30 * time.Second
+
+var ErrRolledBack = errors.New("PostgreSQL did not come back after the change, previous configuration was restored")
+
+// Actions reported in `ChangeStep`
+const (
+	StepReload      = "reload"
+	StepRestart     = "restart"
+	StepHealthCheck = "health_check"
+	StepRollback    = "rollback"
+
+	StepRoleSettings = "role_settings" // per-database and per-role settings put back along with a backup
+)
+
+// Step taken while activating a configuration change, reported back in API responses
+type ChangeStep struct {
+	Action  string `json:"action"`  // reload, restart, health_check, rollback or role_settings
+	Success bool   `json:"success"` // whether the step succeeded
+	Details string `json:"details"` // what was done, or why it failed
+}
+
+// Records a step. Failures get @err appended to @details.
+func recordStep(steps []ChangeStep, action string, details string, err error) []ChangeStep {
+	if err != nil {
+		details = fmt.Sprintf("%s: %v", details, err)
+	}
+	return append(steps, ChangeStep{Action: action, Success: err == nil, Details: details})
+}
+
+/*
+Reloads configuration with `pg_reload_conf()` and restarts PostgreSQL if that left settings
+pending restart. Then probes the server and, if it doesn't answer, copies @backupPath over
+@currentFile, restarts again and puts back per-database and per-role settings saved with @backupPath.
+@service - restarts PostgreSQL
+@currentFile - file that was changed (postgresql.auto.conf)
+@backupPath - backup of @currentFile taken before the change
+@postgresUsername - owner of @currentFile
+Returns every step taken and whether PostgreSQL was restarted. Error is `ErrRolledBack` if the change was undone.
+*/
+func ActivateConfiguration(db *sql.DB, service ServiceController, currentFile string, backupPath string, postgresUsername string, logger *Logger) ([]ChangeStep, bool, error) {
+	steps := []ChangeStep{}
+
+	// 1. Reload, which is enough for everything but postmaster context settings.
+	// Settings that can only change at server start are then marked `pending_restart`.
+	_, err := db.Exec("SELECT pg_reload_conf()")
+	steps = recordStep(steps, StepReload, "Reloaded configuration files", err)
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed to reload PostgreSQL configuration: %v", err))
+	}
+
+	// 2. Restart only if reloading left anything pending
+	var pending int
+	restarted := false
+	if err == nil {
+		if err = db.QueryRow("SELECT count(*) FROM pg_settings WHERE pending_restart").Scan(&pending); err != nil {
+			logger.LogError(fmt.Errorf("failed to check for settings pending restart: %v", err))
+		}
+	}
+	if err != nil || pending > 0 {
+		details := fmt.Sprintf("Restarted PostgreSQL with %s, %d setting(s) were pending restart", service.Name(), pending)
+		if err != nil {
+			details = fmt.Sprintf("Restarted PostgreSQL with %s since configuration could not be reloaded", service.Name())
+		}
+		err = service.Restart(logger)
+		if errors.Is(err, ErrRestartUnsupported) {
+			// Managed databases are restarted through their provider. Pending settings stay pending until then.
+			details = fmt.Sprintf("PostgreSQL has to be restarted outside of PostgreScrutiniser, %d setting(s) are pending restart", pending)
+		}
+		steps = recordStep(steps, StepRestart, details, err)
+		restarted = err == nil
+	}
+
+	// 3. Make sure the server answers
+	err = WaitForPostgres(db, HealthCheckTimeout, logger)
+	steps = recordStep(steps, StepHealthCheck, "PostgreSQL answered SELECT 1", err)
+	if err == nil {
+		return steps, restarted, nil
+	}
+
+	// 4. Put previous configuration back and start again
+	err = restoreFile(backupPath, currentFile, postgresUsername)
+	steps = recordStep(steps, StepRollback, fmt.Sprintf("Restored %s from %s", currentFile, backupPath), err)
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed to roll back configuration change: %v", err))
+		return steps, restarted, err
+	}
+	err = service.Restart(logger)
+	steps = recordStep(steps, StepRestart, fmt.Sprintf("Restarted PostgreSQL with %s and previous configuration", service.Name()), err)
+	restarted = restarted || err == nil
+	err = WaitForPostgres(db, HealthCheckTimeout, logger)
+	steps = recordStep(steps, StepHealthCheck, "PostgreSQL answered SELECT 1", err)
+	if err != nil {
+		return steps, restarted, fmt.Errorf("PostgreSQL is still down after restoring previous configuration: %v", err)
+	}
+
+	// 5. Per-database and per-role settings changed along with the file are put back too
+	if steps, err = RestoreRoleSettings(db, backupPath, steps, logger); err != nil {
+		return steps, restarted, fmt.Errorf("restored previous configuration, but not per-database and per-role settings: %v", err)
+	}
+	logger.LogError(ErrRolledBack)
+	return steps, restarted, ErrRolledBack
+}
+
+/*
+Undoes a change that was written but not activated yet: copies @backupPath over @currentFile
+and puts back per-database and per-role settings saved with it. Nothing is reloaded since
+the server never read the change.
+@reason - why the change is undone, reported in the `rollback` step
+@steps - steps taken so far, rollback steps are appended
+*/
+func RevertChange(db *sql.DB, currentFile string, backupPath string, postgresUsername string, reason string, steps []ChangeStep, logger *Logger) ([]ChangeStep, error) {
+	err := restoreFile(backupPath, currentFile, postgresUsername)
+	steps = recordStep(steps, StepRollback, fmt.Sprintf("%s. Restored %s from %s without reloading, nothing was applied", reason, currentFile, backupPath), err)
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed to revert configuration change: %v", err))
+		return steps, err
+	}
+	return RestoreRoleSettings(db, backupPath, steps, logger)
+}
+
+// Probes PostgreSQL with `SELECT 1` until it answers or @timeout passes.
+// Broken connections are dropped by database/sql, so this also reconnects.
+func WaitForPostgres(db *sql.DB, timeout time.Duration, logger *Logger) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for {
+		var one int
+		err := db.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			logger.LogError(fmt.Errorf("PostgreSQL did not answer within %s: %v", timeout, err))
+			return fmt.Errorf("no answer within %s: %v", timeout, err)
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// Copies @backupPath over @currentFile. The backup is kept so it can still be listed and restored.
+func restoreFile(backupPath string, currentFile string, owner string) error {
+	cmd := exec.Command("sudo", "cp", backupPath, currentFile)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running %s: %v", strings.Join(cmd.Args, " "), err)
+	}
+	cmd = exec.Command("sudo", "chown", owner+".", currentFile)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running %s: %v", strings.Join(cmd.Args, " "), err)
+	}
+	return nil
+}
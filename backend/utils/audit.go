@@ -0,0 +1,118 @@
+// Audit log of configuration changes made through PostgreScrutiniser, one JSON
+// object per line. Drift detection reads it back to tell changes made here
+// apart from ones made by hand.
+
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const AuditLogFile = "/var/log/postgrescrutiniser/audit.log"
+
+// Actions recorded in the audit log
+const (
+	AuditApplySuggestions = "apply_suggestions"
+	AuditReset            = "reset"
+	AuditRestoreBackup    = "restore_backup"
+)
+
+type AuditEntry struct {
+	Time     time.Time         `json:"time"`
+	Instance string            `json:"instance"`
+	Actor    string            `json:"actor"` // user that made the change
+	Action   string            `json:"action"`
+	Settings map[string]string `json:"settings,omitempty"` // values set, left out when all of postgresql.auto.conf was replaced
+	Backup   string            `json:"backup,omitempty"`   // backup that was restored
+}
+
+type AuditLog struct {
+	mutex sync.Mutex
+	path  string
+}
+
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Appends @entry. Not being able to write is only logged since the change itself already went through.
+// Does nothing on a nil log, so callers without one don't have to check.
+func (audit *AuditLog) Record(entry AuditEntry, logger *Logger) {
+	if audit == nil {
+		return
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		logger.LogError(fmt.Errorf("could not format audit entry: %v", err))
+		return
+	}
+
+	audit.mutex.Lock()
+	defer audit.mutex.Unlock()
+	if err := os.MkdirAll(filepath.Dir(audit.path), 0755); err != nil {
+		logger.LogError(fmt.Errorf("could not create directory for %s: %v", audit.path, err))
+		return
+	}
+	file, err := os.OpenFile(audit.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		logger.LogError(fmt.Errorf("could not open audit log %s: %v", audit.path, err))
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		logger.LogError(fmt.Errorf("could not write to audit log %s: %v", audit.path, err))
+	}
+}
+
+// Entries of @instance recorded at or after @since, oldest first. Lines that can't be parsed are skipped.
+func (audit *AuditLog) Entries(instance string, since time.Time) ([]AuditEntry, error) {
+	entries := []AuditEntry{}
+	if audit == nil {
+		return entries, nil
+	}
+
+	audit.mutex.Lock()
+	defer audit.mutex.Unlock()
+	file, err := os.Open(audit.path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := AuditEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if entry.Instance == instance && !entry.Time.Before(since) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// Name changes made from the command line are recorded under. Commands are usually
+// run with sudo, in which case the user that ran sudo is the one that matters.
+func CliActor() string {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		return sudoUser
+	}
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return "unknown"
+}
@@ -3,7 +3,6 @@
 package utils
 
 import (
-	"database/sql"
 	"fmt"
 	"os/exec"
 	"path/filepath"
@@ -15,11 +14,11 @@ import (
 
 /*
 Creates a backup of any file and adds a unix timestamp.
-Used to backup postgresql.auto.conf
+Used to backup postgresql.auto.conf. Returns path to the backup
 @srcPath - path to the file being backed up (postgresql.auto.conf)
 @backupDir - path to directory to backup file in (/usr/local/postgrescrutiniser/backups)
 */
-func BackupFile(srcPath string, backupDir string, appUser *User, logger *Logger) error {
+func BackupFile(srcPath string, backupDir string, appUser *User, logger *Logger) (string, error) {
 	// 1. Get the filename from the source path
 	filename := filepath.Base(srcPath)
 
@@ -31,17 +30,17 @@ func BackupFile(srcPath string, backupDir string, appUser *User, logger *Logger)
 	cmd := exec.Command("sudo", "cp", srcPath, destPath)
 	if err := cmd.Run(); err != nil {
 		logger.LogError(fmt.Errorf("error creating backup: %s", strings.Join(cmd.Args, " ")))
-		return err
+		return "", err
 	}
 
 	// 4. Change owner to postgrescrutiniser
 	cmd = exec.Command("sudo", "chown", appUser.Username+".", destPath) // sudo chown user. /path
 	if err := cmd.Run(); err != nil {
 		logger.LogError(fmt.Errorf("error creating backup: %s", strings.Join(cmd.Args, " ")))
-		return err
+		return "", err
 	}
 
-	return nil
+	return destPath, nil
 }
 
 // Function for getting when a backup was created
@@ -60,15 +59,3 @@ func GetDateTime(path string, logger *Logger) (*time.Time, error) {
 
 	return &datetime, nil
 }
-
-// Reloads postgresql.conf and postgresql.auto.conf
-func ReloadConfiguration(db *sql.DB, logger *Logger) error {
-	// Not using `pg_reload_conf()` as some settings only take effect after restarting postgresql itself
-	cmd := exec.Command("sudo", "systemctl", "restart", "postgresql*")
-	if err := cmd.Run(); err != nil {
-		logger.LogError(fmt.Errorf("failed to restart PostgreSQL service: %v", err))
-		return err
-	}
-
-	return nil
-}
@@ -0,0 +1,230 @@
+// File for reading cgroup v1 and v2 resource limits, so that memory and CPU
+// suggestions respect containers and systemd slices instead of host totals
+
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+type CgroupLimits struct {
+	Version     int     // 1 or 2, 0 if process is not in a cgroup we could read
+	MemoryPath  string  // cgroup the memory limit was read from
+	MemoryLimit uint64  // bytes, 0 if unlimited
+	MemoryUsage uint64  // bytes currently charged to the cgroup
+	CpuPath     string  // cgroup the CPU quota was read from
+	CpuQuota    float64 // cores worth of CPU time per period, 0 if unlimited
+	CpusetPath  string  // cgroup the cpuset was read from
+	CpusetCores int     // number of CPUs in cpuset, 0 if unrestricted
+}
+
+// Reads cgroup limits that apply to process @pid (0 for this process).
+// Limits of parent cgroups apply to their children as well, so the
+// lowest limit between the process cgroup and the root is returned.
+func GetCgroupLimits(pid int) (*CgroupLimits, error) {
+	procFile := "/proc/self/cgroup"
+	if pid > 0 {
+		procFile = fmt.Sprintf("/proc/%d/cgroup", pid)
+	}
+	content, err := os.ReadFile(procFile)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read %s: %v", procFile, err)
+	}
+
+	// Each line is `hierarchy-ID:controller-list:cgroup-path`. cgroup v2 has
+	// a single line with ID 0 and an empty controller list
+	limits := &CgroupLimits{}
+	v1Paths := make(map[string]string)
+	v2Path := ""
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] == "0" && fields[1] == "" {
+			v2Path = fields[2]
+			continue
+		}
+		for _, controller := range strings.Split(fields[1], ",") {
+			v1Paths[controller] = fields[2]
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err == nil && v2Path != "" {
+		limits.Version = 2
+		readCgroupV2(limits, v2Path)
+	} else if len(v1Paths) > 0 {
+		limits.Version = 1
+		readCgroupV1(limits, v1Paths)
+	}
+	return limits, nil
+}
+
+func readCgroupV2(limits *CgroupLimits, path string) {
+	for _, dir := range cgroupDirs(cgroupRoot, path) {
+		// memory.max is either a number of bytes or "max"
+		if value, err := readCgroupFile(dir, "memory.max"); err == nil && value != "max" {
+			if bytes, err := strconv.ParseUint(value, 10, 64); err == nil && (limits.MemoryLimit == 0 || bytes < limits.MemoryLimit) {
+				limits.MemoryLimit = bytes
+				limits.MemoryPath = cgroupName(cgroupRoot, dir)
+				if usage, err := readCgroupFile(dir, "memory.current"); err == nil {
+					limits.MemoryUsage, _ = strconv.ParseUint(usage, 10, 64)
+				}
+			}
+		}
+
+		// cpu.max is `$QUOTA $PERIOD` where quota may be "max"
+		if value, err := readCgroupFile(dir, "cpu.max"); err == nil {
+			fields := strings.Fields(value)
+			if len(fields) == 2 && fields[0] != "max" {
+				setCpuQuota(limits, fields[0], fields[1], cgroupName(cgroupRoot, dir))
+			}
+		}
+
+		if limits.CpusetCores == 0 {
+			if value, err := readCgroupFile(dir, "cpuset.cpus.effective"); err == nil && value != "" {
+				limits.CpusetCores = countCpuList(value)
+				limits.CpusetPath = cgroupName(cgroupRoot, dir)
+			}
+		}
+	}
+}
+
+func readCgroupV1(limits *CgroupLimits, paths map[string]string) {
+	if path, ok := paths["memory"]; ok {
+		root := filepath.Join(cgroupRoot, "memory")
+		for _, dir := range cgroupDirs(root, path) {
+			// No limit is reported as a huge page aligned number close to max int64
+			value, err := readCgroupFile(dir, "memory.limit_in_bytes")
+			if err != nil {
+				continue
+			}
+			bytes, err := strconv.ParseUint(value, 10, 64)
+			if err != nil || bytes >= math.MaxInt64/2 {
+				continue
+			}
+			if limits.MemoryLimit == 0 || bytes < limits.MemoryLimit {
+				limits.MemoryLimit = bytes
+				limits.MemoryPath = cgroupName(root, dir)
+				if usage, err := readCgroupFile(dir, "memory.usage_in_bytes"); err == nil {
+					limits.MemoryUsage, _ = strconv.ParseUint(usage, 10, 64)
+				}
+			}
+		}
+	}
+
+	if path, ok := paths["cpu"]; ok {
+		root := filepath.Join(cgroupRoot, "cpu")
+		if _, err := os.Stat(root); err != nil {
+			root = filepath.Join(cgroupRoot, "cpu,cpuacct")
+		}
+		for _, dir := range cgroupDirs(root, path) {
+			quota, err := readCgroupFile(dir, "cpu.cfs_quota_us")
+			if err != nil || quota == "-1" {
+				continue
+			}
+			period, err := readCgroupFile(dir, "cpu.cfs_period_us")
+			if err != nil {
+				continue
+			}
+			setCpuQuota(limits, quota, period, cgroupName(root, dir))
+		}
+	}
+
+	if path, ok := paths["cpuset"]; ok {
+		root := filepath.Join(cgroupRoot, "cpuset")
+		for _, dir := range cgroupDirs(root, path) {
+			value, err := readCgroupFile(dir, "cpuset.effective_cpus")
+			if err != nil {
+				value, err = readCgroupFile(dir, "cpuset.cpus")
+			}
+			if err == nil && value != "" {
+				limits.CpusetCores = countCpuList(value)
+				limits.CpusetPath = cgroupName(root, dir)
+				break
+			}
+		}
+	}
+}
+
+// Keeps the lowest quota seen so far
+func setCpuQuota(limits *CgroupLimits, quota string, period string, path string) {
+	quotaValue, err := strconv.ParseFloat(quota, 64)
+	if err != nil {
+		return
+	}
+	periodValue, err := strconv.ParseFloat(period, 64)
+	if err != nil || periodValue <= 0 {
+		return
+	}
+	cores := quotaValue / periodValue
+	if limits.CpuQuota == 0 || cores < limits.CpuQuota {
+		limits.CpuQuota = cores
+		limits.CpuPath = path
+	}
+}
+
+// Returns directories from the cgroup of the process up to @root. If the
+// process cgroup is not visible (e.g. inside a cgroup namespace where the
+// process cgroup is mounted as root), only @root is returned.
+func cgroupDirs(root string, path string) []string {
+	dir := filepath.Join(root, path)
+	if _, err := os.Stat(dir); err != nil {
+		return []string{root}
+	}
+
+	dirs := []string{}
+	for {
+		dirs = append(dirs, dir)
+		if dir == root || !strings.HasPrefix(dir, root) {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	return dirs
+}
+
+func cgroupName(root string, dir string) string {
+	name := strings.TrimPrefix(dir, root)
+	if name == "" {
+		return "/"
+	}
+	return name
+}
+
+func readCgroupFile(dir string, name string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// Counts CPUs in a list such as `0-3,8,10-11`
+func countCpuList(list string) int {
+	count := 0
+	scanner := bufio.NewScanner(strings.NewReader(strings.ReplaceAll(list, ",", "\n")))
+	for scanner.Scan() {
+		bounds := strings.SplitN(strings.TrimSpace(scanner.Text()), "-", 2)
+		start, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			continue
+		}
+		end := start
+		if len(bounds) == 2 {
+			if end, err = strconv.Atoi(bounds[1]); err != nil {
+				continue
+			}
+		}
+		count += end - start + 1
+	}
+	return count
+}
@@ -0,0 +1,135 @@
+// File for CPU topology info
+
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+type CpuInfo struct {
+	Sockets        int     // physical CPU packages
+	PhysicalCores  int     // cores across all sockets, not counting hyperthreads
+	LogicalCores   int     // hardware threads as listed in /proc/cpuinfo
+	AvailableCores int     // logical cores the process is allowed to run on (sched_getaffinity, cpuset)
+	QuotaCores     float64 // cores worth of CPU time allowed by cgroup cpu quota, 0 if unlimited
+	QuotaSource    string  // cgroup the quota was read from
+}
+
+// Reads CPU topology from /proc/cpuinfo and the number of CPUs
+// available to this process from its affinity mask
+func GetCpuInfo() (*CpuInfo, error) {
+	return GetCpuInfoForProcess(0)
+}
+
+// Same as `GetCpuInfo`, but CPU restrictions are read for process @pid (0 for this process)
+func GetCpuInfoForProcess(pid int) (*CpuInfo, error) {
+	// 1. Parse /proc/cpuinfo
+	file, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return nil, fmt.Errorf("Could not read /proc/cpuinfo: %v", err)
+	}
+	defer file.Close()
+
+	info := &CpuInfo{}
+	sockets := make(map[string]bool)
+	cores := make(map[string]bool)
+	physicalId, coreId := "", ""
+
+	// Each processor is described by a block of `key : value` lines separated by an empty line
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			if coreId != "" {
+				cores[physicalId+":"+coreId] = true
+			}
+			physicalId, coreId = "", ""
+			continue
+		}
+
+		fields := strings.SplitN(line, ":", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		key, value := strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1])
+		switch key {
+		case "processor":
+			info.LogicalCores++
+		case "physical id":
+			physicalId = value
+			sockets[value] = true
+		case "core id":
+			coreId = value
+		}
+	}
+	if coreId != "" {
+		cores[physicalId+":"+coreId] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Could not read /proc/cpuinfo: %v", err)
+	}
+
+	// Some architectures and virtual machines don't expose topology fields
+	info.Sockets = len(sockets)
+	if info.Sockets == 0 {
+		info.Sockets = 1
+	}
+	info.PhysicalCores = len(cores)
+	if info.PhysicalCores == 0 {
+		info.PhysicalCores = info.LogicalCores
+	}
+
+	// 2. Get CPUs the process may be scheduled on (taskset, cpuset, etc...)
+	var affinity unix.CPUSet
+	if err := unix.SchedGetaffinity(pid, &affinity); err != nil {
+		return nil, fmt.Errorf("Could not get CPU affinity: %v", err)
+	}
+	info.AvailableCores = affinity.Count()
+	if info.AvailableCores == 0 || (info.LogicalCores > 0 && info.AvailableCores > info.LogicalCores) {
+		info.AvailableCores = info.LogicalCores
+	}
+
+	// 3. Apply cgroup cpuset and cpu quota. Not being able to read cgroups is not an error
+	if limits, err := GetCgroupLimits(pid); err == nil {
+		if limits.CpusetCores > 0 && limits.CpusetCores < info.AvailableCores {
+			info.AvailableCores = limits.CpusetCores
+		}
+		if limits.CpuQuota > 0 {
+			info.QuotaCores = limits.CpuQuota
+			info.QuotaSource = fmt.Sprintf("cgroup v%d %s", limits.Version, limits.CpuPath)
+		}
+	}
+
+	return info, nil
+}
+
+// Number of cores worth planning parallelism around. Physical cores
+// unless the process is restricted to fewer CPUs or less CPU time than that.
+func (info *CpuInfo) UsableCores() int {
+	cores := info.PhysicalCores
+	if info.AvailableCores > 0 && info.AvailableCores < cores {
+		cores = info.AvailableCores
+	}
+	// A quota of 1.5 cores still lets two workers make progress
+	if info.QuotaCores > 0 {
+		quota := int(math.Ceil(info.QuotaCores))
+		if quota < cores {
+			cores = quota
+		}
+	}
+	return cores
+}
+
+func (info *CpuInfo) String() string {
+	description := fmt.Sprintf("%d socket(s), %d physical core(s), %d logical core(s), %d available to PostgreSQL", info.Sockets, info.PhysicalCores, info.LogicalCores, info.AvailableCores)
+	if info.QuotaCores > 0 {
+		description += fmt.Sprintf(", limited to %g core(s) of CPU time by %s", info.QuotaCores, info.QuotaSource)
+	}
+	return description
+}
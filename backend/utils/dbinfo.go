@@ -42,8 +42,84 @@ func ParsePgpassFile(appUser *User, logger *Logger) (string, string, string, str
 	return fields[0], fields[1], fields[2], fields[3], fields[4], nil
 }
 
+/*
+Returns the password of the first ~/.pgpass line matching the connection, the way libpq picks it.
+Fields of a line may be `*` to match anything, `\:` and `\\` are an escaped colon and backslash.
+@appUser - user whose ~/.pgpass is read
+*/
+func LookupPgpassPassword(appUser *User, hostname string, port string, dbname string, username string, logger *Logger) (string, error) {
+	usr, err := user.Lookup(appUser.Username)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed to find %s home directory: %v", appUser.Username, err))
+		return "", err
+	}
+	content, err := os.ReadFile(usr.HomeDir + "/.pgpass")
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed to read %s/.pgpass: %v", usr.HomeDir, err))
+		return "", err
+	}
+
+	wanted := []string{hostname, port, dbname, username}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := splitPgpassLine(line)
+		if len(fields) != 5 {
+			continue
+		}
+		matches := true
+		for i, value := range wanted {
+			if fields[i] != "*" && fields[i] != value {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return fields[4], nil
+		}
+	}
+	return "", fmt.Errorf("no line in %s/.pgpass matches %s:%s:%s:%s", usr.HomeDir, hostname, port, dbname, username)
+}
+
+// Splits a ~/.pgpass line on colons that aren't escaped
+func splitPgpassLine(line string) []string {
+	fields := []string{}
+	var field strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line):
+			i++
+			field.WriteByte(line[i])
+		case line[i] == ':':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteByte(line[i])
+		}
+	}
+	return append(fields, strings.TrimRight(field.String(), "\r"))
+}
+
+// Details needed to open connections to the PostgreSQL server. Kept around so that
+// checks can connect to databases other than the one `dbHandler` points at.
+type DbConnectionInfo struct {
+	Hostname string
+	User     string
+	Password string
+	Port     string
+
+	ServerVersion int // server_version_num detected at startup, 0 if unknown
+}
+
 // Intitiate new database connection and return handler for it.
 func InitDbConnection(hostname string, user string, passwd string, port string, logger *Logger) (*sql.DB, error) {
+	return InitDbConnectionToDatabase(hostname, user, passwd, port, "", logger)
+}
+
+// Same as `InitDbConnection`, but connects to a specific database.
+// Leaving @dbname empty connects to the user's default database.
+func InitDbConnectionToDatabase(hostname string, user string, passwd string, port string, dbname string, logger *Logger) (*sql.DB, error) {
 	if hostname == "" || user == "" || passwd == "" || port == "" {
 		err := fmt.Errorf("Could not initiate database connection bcause one of the fields was empty")
 		logger.LogError(err)
@@ -51,6 +127,11 @@ func InitDbConnection(hostname string, user string, passwd string, port string,
 	}
 
 	connString := fmt.Sprintf("host=%s user=%s password=%s port=%s sslmode=disable", hostname, user, passwd, port)
+	if dbname != "" {
+		// Quote the name since database names may contain spaces or quotes
+		escaper := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+		connString += fmt.Sprintf(" dbname='%s'", escaper.Replace(dbname))
+	}
 
 	dbConn, err := sql.Open("postgres", connString)
 	if err != nil {
@@ -61,6 +142,11 @@ func InitDbConnection(hostname string, user string, passwd string, port string,
 	return dbConn, nil
 }
 
+// Opens a connection to @dbname on the same server
+func (info *DbConnectionInfo) Connect(dbname string, logger *Logger) (*sql.DB, error) {
+	return InitDbConnectionToDatabase(info.Hostname, info.User, info.Password, info.Port, dbname, logger)
+}
+
 func CloseDbConnection(dbHandler *sql.DB, logger *Logger) error {
 	if dbHandler == nil {
 		return nil
@@ -93,3 +179,71 @@ func FindConfigFile(dbHandler *sql.DB, logger *Logger) (string, error) {
 
 	return filePath, nil
 }
+
+// Returns path to the data directory PostgreSQL is running with
+func FindDataDirectory(dbHandler *sql.DB, logger *Logger) (string, error) {
+	row := dbHandler.QueryRow("SHOW data_directory")
+
+	var dataDirectory string
+	if err := row.Scan(&dataDirectory); err != nil {
+		logger.LogError(fmt.Errorf("Failed finding data directory: %v", err))
+		return "", err
+	}
+	return dataDirectory, nil
+}
+
+// Returns server_version_num, e.g. 150004 for PostgreSQL 15.4
+func GetServerVersion(dbHandler *sql.DB, logger *Logger) (int, error) {
+	row := dbHandler.QueryRow("SHOW server_version_num")
+
+	var version string
+	if err := row.Scan(&version); err != nil {
+		logger.LogError(fmt.Errorf("Failed getting server version: %v", err))
+		return 0, err
+	}
+	versionNum, err := StringToInt(version)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed parsing server version %s: %v", version, err))
+		return 0, err
+	}
+	return versionNum, nil
+}
+
+// Formats server_version_num the way PostgreSQL prints versions.
+// Versions before 10 have a two part major version (90624 -> 9.6.24).
+func FormatServerVersion(version int) string {
+	if version < 100000 {
+		return fmt.Sprintf("%d.%d.%d", version/10000, version/100%100, version%100)
+	}
+	return fmt.Sprintf("%d.%d", version/10000, version%10000)
+}
+
+// Formats only the major version part of server_version_num (150004 -> 15, 90624 -> 9.6)
+func FormatMajorVersion(version int) string {
+	if version < 100000 {
+		return fmt.Sprintf("%d.%d", version/10000, version/100%100)
+	}
+	return fmt.Sprint(version / 10000)
+}
+
+// Parses a major version as printed by `FormatMajorVersion` back into server_version_num (16 -> 160000, 9.6 -> 90600)
+func ParseMajorVersion(version string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	major, err := StringToInt(parts[0])
+	if err != nil || major <= 0 || len(parts) > 2 {
+		return 0, fmt.Errorf("invalid PostgreSQL version %q", version)
+	}
+	if major >= 10 {
+		return major * 10000, nil
+	}
+
+	// Versions before 10 need the minor part to identify the major release
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid PostgreSQL version %q: versions before 10 need two parts, e.g. 9.6", version)
+	}
+	minor, err := StringToInt(parts[1])
+	if err != nil || minor < 0 || minor > 99 {
+		return 0, fmt.Errorf("invalid PostgreSQL version %q", version)
+	}
+	return major*10000 + minor*100, nil
+}
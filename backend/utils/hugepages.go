@@ -0,0 +1,84 @@
+// File for huge page related kernel info
+
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Get default huge page size in bytes as reported by `Hugepagesize` in /proc/meminfo
+func GetHugePageSize() (uint64, error) {
+	size, err := readMeminfoField("Hugepagesize:")
+	if errors.Is(err, errMeminfoFieldNotFound) {
+		return 0, fmt.Errorf("Hugepagesize not found in /proc/meminfo, kernel may not support huge pages")
+	}
+	return size, err
+}
+
+// Get memory reserved for huge pages in bytes, `HugePages_Total` pages of `Hugepagesize`.
+// Zero if the kernel doesn't support huge pages.
+func GetReservedHugePagesSize() (uint64, error) {
+	pages, err := readMeminfoField("HugePages_Total:")
+	if errors.Is(err, errMeminfoFieldNotFound) {
+		return 0, nil
+	}
+	if err != nil || pages == 0 {
+		return 0, err
+	}
+	size, err := GetHugePageSize()
+	if err != nil {
+		return 0, err
+	}
+	return pages * size, nil
+}
+
+var errMeminfoFieldNotFound = errors.New("field not found in /proc/meminfo")
+
+// Reads field @name of /proc/meminfo, converted to bytes if it's in kB
+func readMeminfoField(name string) (uint64, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, fmt.Errorf("Could not read /proc/meminfo: %v", err)
+	}
+	defer file.Close()
+
+	// Line format: `Hugepagesize:       2048 kB`, counts like `HugePages_Total:       0` have no unit
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != name {
+			continue
+		}
+		value, err := StringToUint64(fields[1])
+		if err != nil {
+			return 0, fmt.Errorf("Could not parse %s %v", name, err)
+		}
+		if len(fields) == 3 && fields[2] == "kB" {
+			value *= 1024
+		}
+		return value, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("Could not read /proc/meminfo: %v", err)
+	}
+	return 0, errMeminfoFieldNotFound
+}
+
+// Get transparent huge pages mode (always, madvise or never). The kernel lists all
+// modes with the active one in brackets, e.g. `always [madvise] never`
+func GetTransparentHugePages() (string, error) {
+	content, err := os.ReadFile("/sys/kernel/mm/transparent_hugepage/enabled")
+	if err != nil {
+		return "", fmt.Errorf("Could not read transparent huge pages mode: %v", err)
+	}
+	for _, mode := range strings.Fields(string(content)) {
+		if strings.HasPrefix(mode, "[") && strings.HasSuffix(mode, "]") {
+			return strings.Trim(mode, "[]"), nil
+		}
+	}
+	return "", fmt.Errorf("Could not find active transparent huge pages mode in %q", strings.TrimSpace(string(content)))
+}
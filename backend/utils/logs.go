@@ -4,6 +4,7 @@
 package utils
 
 import (
+	"io"
 	"log"
 	"os"
 )
@@ -40,6 +41,23 @@ func InitLogging() *Logger {
 	}
 }
 
+// Same as `InitLogging`, but console output goes to stderr so that it doesn't mix with
+// command output. Command line use may lack access to the log directory, in which case
+// only the console is logged to.
+func InitCliLogging() *Logger {
+	console := log.New(os.Stderr, "", log.Ldate|log.Ltime)
+	logFile, err := os.OpenFile("/var/log/postgrescrutiniser/error.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return &Logger{console: console, file: log.New(io.Discard, "", 0)}
+	}
+
+	return &Logger{
+		logDir:  "/var/log/postgrescrutiniser",
+		console: console,
+		file:    log.New(logFile, "", log.Ldate|log.Ltime),
+	}
+}
+
 // Log warnings to both console and error.log
 func (logger *Logger) LogWarning(message error) {
 	logger.console.Println("WARNING:", message.Error())
@@ -0,0 +1,211 @@
+// Parser for postgresql.conf style configuration files. Follows the same rules
+// PostgreSQL does: `name = value` lines (the `=` is optional), `#` comments,
+// single quoted values with '' and backslash escapes and the `include`,
+// `include_if_exists` and `include_dir` directives.
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Same limit as PostgreSQL's CONF_FILE_MAX_DEPTH
+const maxConfigIncludeDepth = 10
+
+const configWhitespace = " \t\r\f\v"
+
+// A single `name = value` line. When a setting appears more than once, the last entry wins.
+type ConfigEntry struct {
+	Name  string // setting name in lower case, names are case insensitive
+	Value string // value with quotes and escapes removed
+	File  string // absolute path of the file the entry was read from
+	Line  int    // line number within File
+}
+
+// Parses configuration file @path along with every file it includes.
+// Entries are returned in the order PostgreSQL would apply them.
+func ParseConfigFile(path string) ([]ConfigEntry, error) {
+	return parseConfigFile(path, 0)
+}
+
+func parseConfigFile(path string, depth int) ([]ConfigEntry, error) {
+	if depth > maxConfigIncludeDepth {
+		return nil, fmt.Errorf("could not open configuration file %s: maximum nesting depth exceeded", path)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve configuration file %s: %v", path, err)
+	}
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open configuration file %s: %v", absPath, err)
+	}
+
+	var entries []ConfigEntry
+	for i, line := range strings.Split(string(content), "\n") {
+		name, value, err := parseConfigLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", absPath, i+1, err)
+		}
+		if name == "" {
+			continue
+		}
+
+		// 1. Regular settings
+		if name != "include" && name != "include_if_exists" && name != "include_dir" {
+			entries = append(entries, ConfigEntry{Name: name, Value: value, File: absPath, Line: i + 1})
+			continue
+		}
+
+		// 2. Included files. Relative paths are relative to the including file
+		if value == "" {
+			return nil, fmt.Errorf("%s:%d: empty path for %s", absPath, i+1, name)
+		}
+		includePath := value
+		if !filepath.IsAbs(includePath) {
+			includePath = filepath.Join(filepath.Dir(absPath), includePath)
+		}
+
+		var included []ConfigEntry
+		switch name {
+		case "include":
+			included, err = parseConfigFile(includePath, depth+1)
+		case "include_if_exists":
+			if _, statErr := os.Stat(includePath); os.IsNotExist(statErr) {
+				continue
+			}
+			included, err = parseConfigFile(includePath, depth+1)
+		case "include_dir":
+			included, err = parseConfigDir(includePath, depth+1)
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, included...)
+	}
+	return entries, nil
+}
+
+// Parses every `.conf` file in @dir in file name order. Hidden files are skipped.
+func parseConfigDir(dir string, depth int) ([]ConfigEntry, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not open configuration directory %s: %v", dir, err)
+	}
+
+	var entries []ConfigEntry
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") || !strings.HasSuffix(file.Name(), ".conf") {
+			continue
+		}
+		included, err := parseConfigFile(filepath.Join(dir, file.Name()), depth)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, included...)
+	}
+	return entries, nil
+}
+
+// Splits a line into setting name and value. Empty and comment lines return an empty name.
+func parseConfigLine(line string) (string, string, error) {
+	rest := strings.TrimLeft(line, configWhitespace)
+	if rest == "" || rest[0] == '#' {
+		return "", "", nil
+	}
+
+	// 1. Setting name. Custom settings are qualified with a dot, e.g. `pg_stat_statements.max`
+	end := 0
+	for end < len(rest) && isConfigNameChar(rest[end]) {
+		end++
+	}
+	if end == 0 || (rest[0] >= '0' && rest[0] <= '9') {
+		return "", "", fmt.Errorf("syntax error near %q", rest)
+	}
+	name := strings.ToLower(rest[:end])
+
+	// 2. Optional `=`
+	rest = strings.TrimLeft(rest[end:], configWhitespace)
+	if strings.HasPrefix(rest, "=") {
+		rest = strings.TrimLeft(rest[1:], configWhitespace)
+	}
+	if rest == "" || rest[0] == '#' {
+		return "", "", fmt.Errorf("missing value for %s", name)
+	}
+
+	// 3. Value, either quoted or running up to whitespace or a comment
+	var value string
+	if rest[0] == '\'' {
+		var err error
+		value, rest, err = parseQuotedConfigValue(rest)
+		if err != nil {
+			return "", "", fmt.Errorf("%v in value of %s", err, name)
+		}
+	} else {
+		end = strings.IndexAny(rest, configWhitespace+"#")
+		if end == -1 {
+			end = len(rest)
+		}
+		value, rest = rest[:end], rest[end:]
+	}
+
+	// 4. Only a comment may follow the value
+	rest = strings.TrimLeft(rest, configWhitespace)
+	if rest != "" && rest[0] != '#' {
+		return "", "", fmt.Errorf("syntax error near %q after value of %s", rest, name)
+	}
+	return name, value, nil
+}
+
+func isConfigNameChar(c byte) bool {
+	return c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
+
+// Reads a single quoted value from the start of @s. A quote is escaped by doubling
+// it or with a backslash, which also supports \b, \f, \n, \r, \t and octal escapes.
+// Returns the unescaped value and whatever follows the closing quote.
+func parseQuotedConfigValue(s string) (string, string, error) {
+	var value strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			value.WriteByte('\'')
+			i++
+		case c == '\'':
+			return value.String(), s[i+1:], nil
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'b':
+				value.WriteByte('\b')
+			case 'f':
+				value.WriteByte('\f')
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				// Up to three octal digits
+				octal := 0
+				for digits := 0; digits < 3 && i < len(s) && s[i] >= '0' && s[i] <= '7'; digits++ {
+					octal = octal*8 + int(s[i]-'0')
+					i++
+				}
+				i--
+				value.WriteByte(byte(octal))
+			default:
+				value.WriteByte(s[i])
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quoted string")
+}
@@ -0,0 +1,303 @@
+// Per-database and per-role setting overrides, set with `ALTER DATABASE ... SET`
+// and `ALTER ROLE ... [IN DATABASE ...] SET` and stored in `pg_db_role_setting`.
+// Neither ALTER SYSTEM RESET nor replacing postgresql.auto.conf touches them, so
+// backups of postgresql.auto.conf keep a copy of them in a file next to the backup.
+
+package utils
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Appended to the path of a postgresql.auto.conf backup to get the file overrides are kept in
+const RoleSettingsSuffix = ".role_settings.json"
+
+// Setting names end up unquoted in statements. Extension settings contain a dot.
+var settingNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+// Settings PostgreSQL marks GUC_LIST_QUOTE, same list as pg_dump's `variable_is_guc_list_quote`.
+// Their value is a list of identifiers, stored with each element quoted as an identifier
+// if needed, e.g. `"$user", public`. Passing it back as one string literal would turn
+// the whole list into a single element.
+var listQuoteSettings = map[string]bool{
+	"local_preload_libraries":   true,
+	"search_path":               true,
+	"session_preload_libraries": true,
+	"shared_preload_libraries":  true,
+	"temp_tablespaces":          true,
+	"unix_socket_directories":   true,
+}
+
+// A setting overridden for a database, a role or a role in a database
+type RoleSetting struct {
+	Database string `json:"database,omitempty"` // empty if the override applies in every database
+	Role     string `json:"role,omitempty"`     // empty if the override applies to every role
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+}
+
+// Identifies the override, two overrides with the same key can't exist at once
+func (setting RoleSetting) key() string {
+	return setting.Database + "\x00" + setting.Role + "\x00" + setting.Name
+}
+
+// Where the override applies, e.g. `role reporting in database sales`
+func (setting RoleSetting) Scope() string {
+	switch {
+	case setting.Database != "" && setting.Role != "":
+		return fmt.Sprintf("role %s in database %s", setting.Role, setting.Database)
+	case setting.Database != "":
+		return "database " + setting.Database
+	case setting.Role != "":
+		return "role " + setting.Role
+	}
+	return "all roles"
+}
+
+/*
+Returns the statement that sets setting @name to @value where @database and @role say,
+or resets it if @value is nil. Without either of them the setting applies to every role.
+*/
+func RoleSettingStatement(database string, role string, name string, value *string) (string, error) {
+	if !settingNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid setting name: %q", name)
+	}
+
+	var target string
+	switch {
+	case database != "" && role != "":
+		target = fmt.Sprintf("ROLE %s IN DATABASE %s", pq.QuoteIdentifier(role), pq.QuoteIdentifier(database))
+	case database != "":
+		target = "DATABASE " + pq.QuoteIdentifier(database)
+	case role != "":
+		target = "ROLE " + pq.QuoteIdentifier(role)
+	default:
+		target = "ROLE ALL"
+	}
+
+	if value == nil {
+		return fmt.Sprintf("ALTER %s RESET %s", target, name), nil
+	}
+	literal, err := SettingValueSql(name, *value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("ALTER %s SET %s = %s", target, name, literal), nil
+}
+
+/*
+Returns @value of setting @name as it goes after `SET name =` in ALTER SYSTEM, ALTER DATABASE
+and ALTER ROLE. Values of list settings like search_path are split into their elements, each
+one a string literal, the way pg_dump does. Everything else is a single string literal.
+*/
+func SettingValueSql(name string, value string) (string, error) {
+	if !listQuoteSettings[strings.ToLower(name)] {
+		return quoteLiteral(value), nil
+	}
+	elements, err := splitSettingList(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid value of %s: %v", name, err)
+	}
+	if len(elements) == 0 {
+		return "''", nil
+	}
+	for i := range elements {
+		elements[i] = quoteLiteral(elements[i])
+	}
+	return strings.Join(elements, ", "), nil
+}
+
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// Splits a comma separated list of identifiers like PostgreSQL's SplitIdentifierString.
+// Double quoted elements may contain commas and "" for a quote, others are lower cased.
+func splitSettingList(value string) ([]string, error) {
+	elements := []string{}
+	rest := strings.TrimSpace(value)
+	if rest == "" {
+		return elements, nil
+	}
+	for {
+		var element string
+		if strings.HasPrefix(rest, `"`) {
+			// Quoted, runs until a quote that isn't doubled
+			var builder strings.Builder
+			i := 1
+			for ; i < len(rest); i++ {
+				if rest[i] != '"' {
+					builder.WriteByte(rest[i])
+				} else if i+1 < len(rest) && rest[i+1] == '"' {
+					builder.WriteByte('"')
+					i++
+				} else {
+					break
+				}
+			}
+			if i >= len(rest) {
+				return nil, fmt.Errorf("unterminated quoted identifier")
+			}
+			element = builder.String()
+			rest = strings.TrimSpace(rest[i+1:])
+		} else {
+			end := strings.IndexByte(rest, ',')
+			if end < 0 {
+				end = len(rest)
+			}
+			element = strings.ToLower(strings.TrimSpace(rest[:end]))
+			if element == "" || strings.ContainsAny(element, " \t\"") {
+				return nil, fmt.Errorf("invalid list element %q", rest[:end])
+			}
+			rest = rest[end:]
+		}
+		elements = append(elements, element)
+
+		if rest == "" {
+			return elements, nil
+		}
+		if rest[0] != ',' {
+			return nil, fmt.Errorf("expected a comma after %q", element)
+		}
+		rest = strings.TrimSpace(rest[1:])
+	}
+}
+
+// Reads every override from `pg_db_role_setting`, ordered by database, role and name
+func GetRoleSettings(db *sql.DB, logger *Logger) ([]RoleSetting, error) {
+	rows, err := db.Query(`SELECT coalesce(d.datname, ''), coalesce(r.rolname, ''), c.setting
+		FROM pg_db_role_setting s
+		LEFT JOIN pg_database d ON d.oid = s.setdatabase
+		LEFT JOIN pg_roles r ON r.oid = s.setrole
+		CROSS JOIN LATERAL unnest(s.setconfig) AS c(setting)
+		ORDER BY 1, 2, 3`)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed querying pg_db_role_setting: %v", err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := []RoleSetting{}
+	for rows.Next() {
+		var setting RoleSetting
+		var config string
+		if err := rows.Scan(&setting.Database, &setting.Role, &config); err != nil {
+			logger.LogError(fmt.Errorf("Failed scanning row: %v", err))
+			return nil, err
+		}
+		// Stored as `name=value`
+		setting.Name, setting.Value, _ = strings.Cut(config, "=")
+		settings = append(settings, setting)
+	}
+	if err := rows.Err(); err != nil {
+		logger.LogError(fmt.Errorf("Failed querying pg_db_role_setting: %v", err))
+		return nil, err
+	}
+	return settings, nil
+}
+
+// Saves current overrides next to postgresql.auto.conf backup @backupPath
+func BackupRoleSettings(db *sql.DB, backupPath string, logger *Logger) error {
+	settings, err := GetRoleSettings(db, logger)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(backupPath+RoleSettingsSuffix, content, 0644); err != nil {
+		logger.LogError(fmt.Errorf("error backing up per-database and per-role settings: %v", err))
+		return err
+	}
+	return nil
+}
+
+// Reads overrides saved along with postgresql.auto.conf backup @backupPath.
+// Returns nil if there are none, like for backups taken before overrides were saved.
+func ReadRoleSettingsBackup(backupPath string) ([]RoleSetting, error) {
+	content, err := os.ReadFile(backupPath + RoleSettingsSuffix)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	settings := []RoleSetting{}
+	if err := json.Unmarshal(content, &settings); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", backupPath+RoleSettingsSuffix, err)
+	}
+	return settings, nil
+}
+
+/*
+Puts back overrides saved along with @backupPath: overrides that weren't there are
+reset and the others are set to the saved value. Nothing is done for backups that
+have no overrides saved. Statements failing, e.g. for a role that no longer exists,
+don't stop the others and are reported in the step rather than as an error.
+@steps - steps taken so far, the `role_settings` step is appended
+*/
+func RestoreRoleSettings(db *sql.DB, backupPath string, steps []ChangeStep, logger *Logger) ([]ChangeStep, error) {
+	// 1. Read what was saved and what there is now
+	saved, err := ReadRoleSettingsBackup(backupPath)
+	if err != nil {
+		logger.LogError(err)
+		return recordStep(steps, StepRoleSettings, "Could not read saved per-database and per-role settings", err), err
+	}
+	if saved == nil {
+		return steps, nil
+	}
+	current, err := GetRoleSettings(db, logger)
+	if err != nil {
+		return recordStep(steps, StepRoleSettings, "Could not read per-database and per-role settings", err), err
+	}
+
+	// 2. Work out statements that turn current overrides into saved ones
+	wanted := make(map[string]RoleSetting, len(saved))
+	for _, setting := range saved {
+		wanted[setting.key()] = setting
+	}
+	statements := []string{}
+	for _, setting := range current {
+		if savedSetting, ok := wanted[setting.key()]; ok && savedSetting.Value == setting.Value {
+			delete(wanted, setting.key())
+		} else if !ok {
+			statement, err := RoleSettingStatement(setting.Database, setting.Role, setting.Name, nil)
+			if err == nil {
+				statements = append(statements, statement)
+			}
+		}
+	}
+	for _, setting := range saved {
+		if _, ok := wanted[setting.key()]; !ok {
+			continue
+		}
+		value := setting.Value
+		statement, err := RoleSettingStatement(setting.Database, setting.Role, setting.Name, &value)
+		if err != nil {
+			logger.LogWarning(fmt.Errorf("skipping saved override of %s: %v", setting.Scope(), err))
+			continue
+		}
+		statements = append(statements, statement)
+	}
+
+	// 3. Run them, one failing doesn't stop the others
+	failed := []string{}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			logger.LogWarning(fmt.Errorf("could not restore per-database or per-role setting with `%s`: %v", statement, err))
+			failed = append(failed, statement)
+		}
+	}
+	if len(failed) > 0 {
+		return recordStep(steps, StepRoleSettings, fmt.Sprintf("Restored per-database and per-role settings with %d statements", len(statements)), fmt.Errorf("%d failed: %s", len(failed), strings.Join(failed, "; "))), nil
+	}
+	return recordStep(steps, StepRoleSettings, fmt.Sprintf("Restored per-database and per-role settings with %d statements", len(statements)), nil), nil
+}
@@ -0,0 +1,229 @@
+// Controlling the PostgreSQL service. Distributions and hosting setups start
+// PostgreSQL differently, so restarts go through a controller picked in dev.env
+// rather than a single hard coded command.
+
+package utils
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Controllers that can be selected with SERVICE_CONTROLLER
+const (
+	ControllerSystemd      = "systemd"
+	ControllerPgCtlCluster = "pg_ctlcluster"
+	ControllerPgCtl        = "pg_ctl"
+	ControllerNone         = "none"
+)
+
+var ErrRestartUnsupported = errors.New("restarting PostgreSQL is not supported by the configured service controller")
+
+// Restarts PostgreSQL. Reloads always go through `pg_reload_conf()`.
+type ServiceController interface {
+	Name() string // controller and what it acts on, shown in change steps
+	Restart(logger *Logger) error
+}
+
+// How PostgreSQL is controlled, empty fields are detected from the running server
+type ServiceConfig struct {
+	Controller    string // systemd, pg_ctlcluster, pg_ctl or none. Defaults to systemd.
+	SystemdUnit   string // e.g. postgresql@15-main.service
+	Cluster       string // pg_ctlcluster version and name, e.g. 15/main
+	PgCtlPath     string // path to pg_ctl binary
+	DataDirectory string // data directory passed to `pg_ctl -D`
+}
+
+/*
+Creates the controller selected in @config. Details that were left out are looked up with @db.
+@postgresUsername - user pg_ctl is run as
+*/
+func NewServiceController(config ServiceConfig, db *sql.DB, postgresUsername string, logger *Logger) (ServiceController, error) {
+	switch config.Controller {
+	case "", ControllerSystemd:
+		unit := config.SystemdUnit
+		if unit == "" {
+			detected, err := detectSystemdUnit(db)
+			if err != nil {
+				return nil, fmt.Errorf("could not detect systemd unit of PostgreSQL, set SYSTEMD_UNIT: %v", err)
+			}
+			unit = detected
+		}
+		return &systemdController{unit: unit}, nil
+	case ControllerPgCtlCluster:
+		cluster := config.Cluster
+		if cluster == "" {
+			detected, err := detectCluster(db)
+			if err != nil {
+				return nil, fmt.Errorf("could not detect cluster for pg_ctlcluster, set PG_CLUSTER: %v", err)
+			}
+			cluster = detected
+		}
+		version, name, ok := strings.Cut(cluster, "/")
+		if !ok || version == "" || name == "" {
+			return nil, fmt.Errorf("invalid cluster %q, expected version/name, e.g. 15/main", cluster)
+		}
+		return &pgCtlClusterController{version: version, cluster: name}, nil
+	case ControllerPgCtl:
+		dataDirectory := config.DataDirectory
+		if dataDirectory == "" {
+			if db == nil {
+				return nil, fmt.Errorf("could not detect data directory for pg_ctl without a database connection, set PG_DATA_DIRECTORY")
+			}
+			detected, err := FindDataDirectory(db, logger)
+			if err != nil {
+				return nil, fmt.Errorf("could not detect data directory for pg_ctl, set PG_DATA_DIRECTORY: %v", err)
+			}
+			dataDirectory = detected
+		}
+		pgCtl := config.PgCtlPath
+		if pgCtl == "" {
+			pgCtl = "pg_ctl"
+		}
+		return &pgCtlController{pgCtl: pgCtl, dataDirectory: dataDirectory, username: postgresUsername}, nil
+	case ControllerNone:
+		return noopController{}, nil
+	}
+	return nil, fmt.Errorf("unknown service controller %q, expected %s, %s, %s or %s", config.Controller,
+		ControllerSystemd, ControllerPgCtlCluster, ControllerPgCtl, ControllerNone)
+}
+
+// Runs @name with @args, including the command's output in returned error
+func runServiceCommand(logger *Logger, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		err = fmt.Errorf("error running %s: %v %s", strings.Join(cmd.Args, " "), err, strings.TrimSpace(string(output)))
+		logger.LogError(fmt.Errorf("failed to restart PostgreSQL service: %v", err))
+		return err
+	}
+	return nil
+}
+
+////////////////////////
+// systemd
+
+type systemdController struct {
+	unit string
+}
+
+func (controller *systemdController) Name() string {
+	return fmt.Sprintf("systemd unit %s", controller.unit)
+}
+
+func (controller *systemdController) Restart(logger *Logger) error {
+	return runServiceCommand(logger, "sudo", "systemctl", "restart", controller.unit)
+}
+
+// Finds the unit PostgreSQL runs in from the cgroup of our backend process,
+// which is shared with the postmaster, e.g. postgresql@15-main.service on Debian
+func detectSystemdUnit(db *sql.DB) (string, error) {
+	if db == nil {
+		return "", fmt.Errorf("no database connection")
+	}
+	var pid int
+	if err := db.QueryRow("SELECT pg_backend_pid()").Scan(&pid); err != nil {
+		return "", err
+	}
+
+	// The pid may not be visible to us, e.g. when PostgreSQL runs on another host,
+	// or belong to an unrelated process whose unit must not be restarted
+	comm, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil || !strings.HasPrefix(strings.TrimSpace(string(comm)), "postgres") {
+		return "", fmt.Errorf("PostgreSQL backend %d is not a process on this host", pid)
+	}
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+
+	// Each line is `hierarchy-ID:controller-list:cgroup-path`, the unit is the
+	// innermost path element ending in .service
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		elements := strings.Split(fields[2], "/")
+		for i := len(elements) - 1; i >= 0; i-- {
+			if strings.HasSuffix(elements[i], ".service") {
+				return elements[i], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("PostgreSQL backend %d is not running in a systemd service", pid)
+}
+
+////////////////////////
+// pg_ctlcluster (Debian and Ubuntu postgresql-common)
+
+type pgCtlClusterController struct {
+	version string
+	cluster string
+}
+
+func (controller *pgCtlClusterController) Name() string {
+	return fmt.Sprintf("pg_ctlcluster %s %s", controller.version, controller.cluster)
+}
+
+func (controller *pgCtlClusterController) Restart(logger *Logger) error {
+	return runServiceCommand(logger, "sudo", "pg_ctlcluster", controller.version, controller.cluster, "restart")
+}
+
+var clusterPattern = regexp.MustCompile(`^\d+(\.\d+)?/[^/\s]+$`)
+
+// postgresql-common sets cluster_name to version/name. Clusters that don't
+// have it are recognised by the /var/lib/postgresql/version/name data directory.
+func detectCluster(db *sql.DB) (string, error) {
+	if db == nil {
+		return "", fmt.Errorf("no database connection")
+	}
+	var clusterName, dataDirectory string
+	if err := db.QueryRow("SELECT current_setting('cluster_name'), current_setting('data_directory')").Scan(&clusterName, &dataDirectory); err != nil {
+		return "", err
+	}
+	if clusterPattern.MatchString(clusterName) {
+		return clusterName, nil
+	}
+	cluster := filepath.Base(filepath.Dir(dataDirectory)) + "/" + filepath.Base(dataDirectory)
+	if strings.HasPrefix(dataDirectory, "/var/lib/postgresql/") && clusterPattern.MatchString(cluster) {
+		return cluster, nil
+	}
+	return "", fmt.Errorf("neither cluster_name %q nor data directory %s name a cluster", clusterName, dataDirectory)
+}
+
+////////////////////////
+// pg_ctl
+
+type pgCtlController struct {
+	pgCtl         string
+	dataDirectory string
+	username      string
+}
+
+func (controller *pgCtlController) Name() string {
+	return fmt.Sprintf("pg_ctl -D %s", controller.dataDirectory)
+}
+
+// Fast shutdown doesn't wait for clients to disconnect, `-w` waits for the server to accept connections again
+func (controller *pgCtlController) Restart(logger *Logger) error {
+	return runServiceCommand(logger, "sudo", "-u", controller.username, controller.pgCtl, "-D", controller.dataDirectory, "-m", "fast", "-w", "restart")
+}
+
+////////////////////////
+// none, for managed databases where only SQL is available
+
+type noopController struct{}
+
+func (noopController) Name() string {
+	return "no service controller"
+}
+
+func (noopController) Restart(logger *Logger) error {
+	return ErrRestartUnsupported
+}
@@ -0,0 +1,96 @@
+// File for detecting what kind of storage a path resides on
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+type StorageType string
+
+const (
+	StorageSSD     StorageType = "ssd"
+	StorageHDD     StorageType = "hdd"
+	StorageUnknown StorageType = "unknown"
+)
+
+type StorageInfo struct {
+	Device string      // block device(s) the path resides on, e.g. `sda` or `nvme0n1`
+	Type   StorageType // ssd if none of the underlying devices are rotational
+}
+
+/*
+Resolves the block device @path is on and reads `/sys/block/<device>/queue/rotational`.
+Partitions are resolved to their parent disk and device mapper/md devices (LVM, RAID, LUKS)
+to the disks backing them. If any backing disk is rotational, storage is reported as hdd.
+*/
+func GetStorageInfo(path string) (*StorageInfo, error) {
+	// 1. Find device number of the filesystem @path is on
+	var stat unix.Stat_t
+	if err := unix.Stat(path, &stat); err != nil {
+		return nil, fmt.Errorf("Could not stat %s: %v", path, err)
+	}
+	major := unix.Major(uint64(stat.Dev))
+	minor := unix.Minor(uint64(stat.Dev))
+
+	// 2. Resolve it to a device name in sysfs
+	sysPath, err := filepath.EvalSymlinks(fmt.Sprintf("/sys/dev/block/%d:%d", major, minor))
+	if err != nil {
+		// Happens for filesystems without a backing block device (overlayfs, tmpfs, nfs, etc...)
+		return &StorageInfo{Device: fmt.Sprintf("%d:%d", major, minor), Type: StorageUnknown}, nil
+	}
+
+	// 3. Walk down to the physical disks and check whether they are rotational
+	disks := resolveDisks(sysPath)
+	if len(disks) == 0 {
+		return &StorageInfo{Device: filepath.Base(sysPath), Type: StorageUnknown}, nil
+	}
+
+	storageType := StorageSSD
+	for _, disk := range disks {
+		rotational, err := os.ReadFile(filepath.Join(disk, "queue", "rotational"))
+		if err != nil {
+			storageType = StorageUnknown
+			continue
+		}
+		if strings.TrimSpace(string(rotational)) == "1" {
+			storageType = StorageHDD
+			break
+		}
+	}
+
+	var names []string
+	for _, disk := range disks {
+		names = append(names, filepath.Base(disk))
+	}
+	return &StorageInfo{Device: strings.Join(names, ","), Type: storageType}, nil
+}
+
+// Returns sysfs paths of whole disks backing the device at @sysPath
+func resolveDisks(sysPath string) []string {
+	// Partitions have a `partition` file, their parent directory is the disk
+	if _, err := os.Stat(filepath.Join(sysPath, "partition")); err == nil {
+		sysPath = filepath.Dir(sysPath)
+	}
+
+	// Device mapper and md devices list the devices they're built on in `slaves`
+	slaves, _ := filepath.Glob(filepath.Join(sysPath, "slaves", "*"))
+	if len(slaves) == 0 {
+		return []string{sysPath}
+	}
+
+	var disks []string
+	for _, slave := range slaves {
+		resolved, err := filepath.EvalSymlinks(slave)
+		if err != nil {
+			continue
+		}
+		disks = append(disks, resolveDisks(resolved)...)
+	}
+	return disks
+}
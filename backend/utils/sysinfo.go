@@ -29,6 +29,63 @@ func GetAvailableMemory() (uint64, error) {
 	return info.Freeram, nil
 }
 
+// Get how much swap space server has
+func GetTotalSwap() (uint64, error) {
+	var info syscall.Sysinfo_t
+	err := syscall.Sysinfo(&info)
+	if err != nil {
+		return 0, fmt.Errorf("Could not get total swap space: %v", err)
+	}
+
+	return info.Totalswap * uint64(info.Unit), nil
+}
+
+type MemoryInfo struct {
+	Total     uint64 // bytes PostgreSQL may use in total
+	Available uint64 // bytes currently free for PostgreSQL to use
+	Source    string // where the figures come from
+}
+
+// Get memory limits that apply to process @pid (0 for this process). Host memory
+// as reported by sysinfo is lowered to the cgroup memory limit if there is one.
+func GetEffectiveMemory(pid int) (*MemoryInfo, error) {
+	total, err := GetTotalMemory()
+	if err != nil {
+		return nil, err
+	}
+	available, err := GetAvailableMemory()
+	if err != nil {
+		return nil, err
+	}
+	info := &MemoryInfo{Total: total, Available: available, Source: "host memory (sysinfo)"}
+
+	// Not being able to read cgroups is not an error, host figures are still correct then
+	limits, err := GetCgroupLimits(pid)
+	if err != nil || limits.MemoryLimit == 0 || limits.MemoryLimit >= total {
+		return info, nil
+	}
+
+	info.Total = limits.MemoryLimit
+	var cgroupAvailable uint64
+	if limits.MemoryUsage < limits.MemoryLimit {
+		cgroupAvailable = limits.MemoryLimit - limits.MemoryUsage
+	}
+	if cgroupAvailable < info.Available {
+		info.Available = cgroupAvailable
+	}
+	limitFile := "memory.max"
+	if limits.Version == 1 {
+		limitFile = "memory.limit_in_bytes"
+	}
+	info.Source = fmt.Sprintf("cgroup v%d %s of %s", limits.Version, limitFile, limits.MemoryPath)
+	return info, nil
+}
+
+// Sentence explaining where memory figures used in a suggestion come from
+func (info *MemoryInfo) Describe() string {
+	return fmt.Sprintf("Memory figures are based on %s: %.0fMB total, %.0fMB available. ", info.Source, float64(info.Total)/1024/1024, float64(info.Available)/1024/1024)
+}
+
 // Get maximum stack depth set on system (equivalent would be `ulimit -s`)
 func GetStackSize() (uint64, error) {
 	var rlimit syscall.Rlimit
@@ -5,6 +5,8 @@ package utils
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"unicode"
 )
 
 func ConvertBasedOnUnit(value string, unitToConvertFrom string, unitToConvertTo string) (float32, error) {
@@ -117,6 +119,158 @@ func ConvertTo8KB(value string, unit string) (float32, error) {
 	return float32(size), nil
 }
 
+// Multipliers of units PostgreSQL accepts in configuration files.
+// Memory units are in bytes, time units in milliseconds. Unit names are case sensitive.
+var (
+	memoryUnitMultipliers = map[string]float64{"B": 1, "kB": 1024, "MB": 1024 * 1024, "GB": 1024 * 1024 * 1024, "TB": 1024 * 1024 * 1024 * 1024}
+	timeUnitMultipliers   = map[string]float64{"us": 0.001, "ms": 1, "s": 1000, "min": 60 * 1000, "h": 60 * 60 * 1000, "d": 24 * 60 * 60 * 1000}
+)
+
+/*
+Parses a configuration file value such as `4GB`, `15min` or `0.9`.
+@value - value as written in postgresql.conf. Values without a unit are already in @baseUnit
+@baseUnit - unit `pg_settings` reports the setting in (8kB, kB, ms, s, etc...). Empty for unitless settings
+*/
+func ParseValueWithUnit(value string, baseUnit string) (float64, error) {
+	value = strings.TrimSpace(value)
+
+	// 1. Split number from unit. Whitespace between the two is allowed
+	end := 0
+	for end < len(value) && strings.ContainsRune("0123456789.+-eE", rune(value[end])) {
+		// `e` only belongs to the number when it's followed by an exponent
+		if (value[end] == 'e' || value[end] == 'E') && (end+1 >= len(value) || !strings.ContainsRune("0123456789+-", rune(value[end+1]))) {
+			break
+		}
+		end++
+	}
+	number, err := strconv.ParseFloat(value[:end], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q: not a number", value)
+	}
+	unit := strings.TrimSpace(value[end:])
+	if unit == "" {
+		return number, nil
+	}
+
+	// 2. Convert to base unit. Memory can't be converted to time and vice versa
+	if baseUnit == "" {
+		return 0, fmt.Errorf("invalid value %q: setting does not accept units", value)
+	}
+	from, fromMemory := unitMultiplier(unit)
+	to, toMemory := unitMultiplier(baseUnit)
+	if from == 0 || unit[0] < 'A' || fromMemory != toMemory {
+		validUnits := `"B", "kB", "MB", "GB" and "TB"`
+		if !toMemory {
+			validUnits = `"us", "ms", "s", "min", "h" and "d"`
+		}
+		return 0, fmt.Errorf("invalid value %q: valid units for this setting are %s", value, validUnits)
+	}
+	if to == 0 {
+		return 0, fmt.Errorf("unsupported unit: %s", baseUnit)
+	}
+	return number * from / to, nil
+}
+
+// Returns size of @unit (in bytes or milliseconds) and whether it's a memory unit.
+// Units with a count, like `8kB` as reported by pg_settings, are multiples of the plain unit.
+func unitMultiplier(unit string) (float64, bool) {
+	count := 1.0
+	digits := 0
+	for digits < len(unit) && unit[digits] >= '0' && unit[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 {
+		count, _ = strconv.ParseFloat(unit[:digits], 64)
+		unit = unit[digits:]
+	}
+
+	if multiplier, ok := memoryUnitMultipliers[unit]; ok {
+		return count * multiplier, true
+	}
+	if multiplier, ok := timeUnitMultipliers[unit]; ok {
+		return count * multiplier, false
+	}
+	return 0, false
+}
+
+/*
+Converts a setting's value to bytes for memory settings and milliseconds for time settings,
+so that values written with different units compare equal. Unitless settings are returned as is.
+@value - value as `pg_settings` reports it (16384 for an 8kB setting) or as written in postgresql.conf (128MB)
+@baseUnit - unit `pg_settings` reports the setting in
+*/
+func NormalizeValue(value string, baseUnit string) (float64, error) {
+	number, err := ParseValueWithUnit(value, baseUnit)
+	if err != nil {
+		return 0, err
+	}
+	if multiplier, _ := unitMultiplier(baseUnit); multiplier != 0 {
+		number *= multiplier
+	}
+	return number, nil
+}
+
+// Next larger unit postgresql.conf accepts and how many of the smaller unit it holds
+var largerUnits = map[string]struct {
+	unit   string
+	factor int64
+}{
+	"B":   {"kB", 1024},
+	"kB":  {"MB", 1024},
+	"MB":  {"GB", 1024},
+	"GB":  {"TB", 1024},
+	"us":  {"ms", 1000},
+	"ms":  {"s", 1000},
+	"s":   {"min", 60},
+	"min": {"h", 60},
+	"h":   {"d", 24},
+}
+
+/*
+Writes an integer @value reported in @baseUnit the way it would be written by hand, with the
+largest unit that keeps it exact, e.g. 851968 in 8kB is 6656MB. Other values are returned as is.
+*/
+func FormatValueWithUnit(value string, baseUnit string) string {
+	number, err := strconv.ParseInt(value, 10, 64)
+	if baseUnit == "" || err != nil || number < 0 {
+		return value
+	}
+
+	// Units like 8kB (pages) or 16MB (WAL segments) are multiples of a plain unit
+	unit := baseUnit
+	if digits := strings.IndexFunc(unit, func(r rune) bool { return !unicode.IsDigit(r) }); digits > 0 {
+		multiplier, err := strconv.ParseInt(unit[:digits], 10, 64)
+		if err != nil {
+			return value
+		}
+		number *= multiplier
+		unit = unit[digits:]
+	}
+
+	// Move to larger units as long as the value stays exact, e.g. 6815744kB is written as 6656MB
+	for number != 0 {
+		next, ok := largerUnits[unit]
+		if !ok || number%next.factor != 0 {
+			break
+		}
+		number /= next.factor
+		unit = next.unit
+	}
+	return fmt.Sprintf("%d%s", number, unit)
+}
+
+// Parses a memory size such as `16GB` into bytes. Values without a unit are taken as bytes.
+func ParseMemorySize(value string) (uint64, error) {
+	size, err := ParseValueWithUnit(value, "B")
+	if err != nil {
+		return 0, err
+	}
+	if size < 0 {
+		return 0, fmt.Errorf("invalid memory size %q: must not be negative", value)
+	}
+	return uint64(size), nil
+}
+
 func RoundToPowerOf2(n uint64) uint64 {
 	// This if statement does not round to power of 2
 	// However, due to nature of the app, if we do pass a value of 0, we want 0 returned
@@ -96,3 +96,13 @@ func (wrapper *JwtWrapper) ValidateTokenMiddleware(logger *utils.Logger) Middlew
 		c.Next()
 	}
 }
+
+// Name of the user a request was authenticated as by `ValidateTokenMiddleware`, empty if it wasn't
+func Username(c *gin.Context) string {
+	if claims, ok := c.Get("bearerAuth.Scopes"); ok {
+		if jwtClaims, ok := claims.(*JwtClaims); ok {
+			return jwtClaims.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+// Named baselines: snapshots of an instance's settings kept on the server, e.g.
+// "golden prod config". Each baseline is a JSON file named after it, and the
+// instance it was taken of is checked for drift against it.
+package compare
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+const DefaultBaselineDir = "/usr/local/postgrescrutiniser/baselines"
+
+var (
+	ErrBaselineNotFound = errors.New("baseline not found")
+	ErrInvalidName      = errors.New("invalid baseline name")
+)
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+type BaselineStore struct {
+	mutex  sync.Mutex
+	dir    string
+	logger *utils.Logger
+}
+
+func NewBaselineStore(dir string, logger *utils.Logger) *BaselineStore {
+	return &BaselineStore{dir: dir, logger: logger}
+}
+
+// Lists baselines by name
+func (store *BaselineStore) Baselines() ([]Baseline, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(store.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	baselines := []Baseline{}
+	for _, file := range files {
+		baseline, err := store.read(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			store.logger.LogWarning(fmt.Errorf("skipping baseline %s: %v", file, err))
+			continue
+		}
+		baselines = append(baselines, *baseline)
+	}
+	return baselines, nil
+}
+
+// Returns baseline @name
+func (store *BaselineStore) Baseline(name string) (*Baseline, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: %s", ErrBaselineNotFound, name)
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	return store.read(name)
+}
+
+// Caller has to hold the mutex
+func (store *BaselineStore) read(name string) (*Baseline, error) {
+	content, err := os.ReadFile(filepath.Join(store.dir, name+".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrBaselineNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	baseline := &Baseline{}
+	if err := json.Unmarshal(content, baseline); err != nil {
+		return nil, err
+	}
+	return baseline, nil
+}
+
+/*
+Saves @snapshot of instance @instanceId as baseline @name, replacing one with the same name.
+@createdBy - user saving it, empty if unknown
+*/
+func (store *BaselineStore) Save(name string, instanceId string, snapshot *SettingsSnapshot, createdBy string) (*Baseline, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: has to match %s", ErrInvalidName, namePattern)
+	}
+	if err := snapshot.validate(); err != nil {
+		return nil, err
+	}
+	baseline := &Baseline{Name: name, Instance: instanceId, CreatedAt: time.Now().UTC(), Snapshot: *snapshot}
+	if createdBy != "" {
+		baseline.CreatedBy = &createdBy
+	}
+	content, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if err := os.MkdirAll(store.dir, 0755); err != nil {
+		store.logger.LogError(fmt.Errorf("could not create baseline directory %s: %v", store.dir, err))
+		return nil, err
+	}
+	path := filepath.Join(store.dir, name+".json")
+	if err := os.WriteFile(path+".tmp", content, 0644); err != nil {
+		store.logger.LogError(fmt.Errorf("could not save baseline to %s: %v", path, err))
+		return nil, err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		store.logger.LogError(fmt.Errorf("could not save baseline to %s: %v", path, err))
+		return nil, err
+	}
+	return baseline, nil
+}
+
+// Removes baseline @name
+func (store *BaselineStore) Delete(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: %s", ErrBaselineNotFound, name)
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	err := os.Remove(filepath.Join(store.dir, name+".json"))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", ErrBaselineNotFound, name)
+	}
+	if err != nil {
+		store.logger.LogError(fmt.Errorf("could not remove baseline %s: %v", name, err))
+	}
+	return err
+}
+
+// Baseline without its settings
+func (baseline *Baseline) summary() BaselineSummary {
+	return BaselineSummary{
+		Name:      baseline.Name,
+		Instance:  baseline.Instance,
+		CreatedAt: baseline.CreatedAt,
+		CreatedBy: baseline.CreatedBy,
+		Settings:  len(baseline.Snapshot.Settings),
+	}
+}
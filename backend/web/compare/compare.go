@@ -0,0 +1,200 @@
+// Comparison of `pg_settings` across instances and saved snapshots. Values are
+// compared in bytes and milliseconds rather than as reported, so that only
+// settings that really differ show up, whichever unit each source used.
+package compare
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/instance"
+)
+
+// Name the baseline is listed under in comparisons
+const BaselineSource = "baseline"
+
+var (
+	ErrNotEnoughSources = errors.New("at least two sources are needed, compare more instances or pass a baseline")
+	ErrInvalidBaseline  = errors.New("invalid baseline")
+)
+
+// Reads every setting of the server @dbHandler is connected to
+func TakeSnapshot(dbHandler *sql.DB, instanceId string, logger *utils.Logger) (*SettingsSnapshot, error) {
+	if dbHandler == nil {
+		return nil, fmt.Errorf("instance %s: no database connection", instanceId)
+	}
+	rows, err := dbHandler.Query("SELECT name, setting, coalesce(unit, ''), vartype FROM pg_settings ORDER BY name")
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed reading pg_settings of instance %s: %v", instanceId, err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshot := &SettingsSnapshot{Instance: &instanceId, TakenAt: time.Now().UTC(), Settings: []SnapshotSetting{}}
+	for rows.Next() {
+		var name, value, unit, vartype string
+		if err := rows.Scan(&name, &value, &unit, &vartype); err != nil {
+			logger.LogError(fmt.Errorf("Failed reading pg_settings of instance %s: %v", instanceId, err))
+			return nil, err
+		}
+		setting := SnapshotSetting{Name: name, Value: value, Vartype: &vartype}
+		if unit != "" {
+			setting.Unit = &unit
+		}
+		if name == "server_version_num" {
+			snapshot.ServerVersion = &value
+		}
+		snapshot.Settings = append(snapshot.Settings, setting)
+	}
+	if err := rows.Err(); err != nil {
+		logger.LogError(fmt.Errorf("Failed reading pg_settings of instance %s: %v", instanceId, err))
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+/*
+Takes snapshots of @instanceIds and compares them with each other and @baseline.
+@baseline - saved snapshot listed last, nil to compare instances only
+@all - whether settings with equal values are returned as well
+*/
+func CompareInstances(registry *instance.Registry, instanceIds []string, baseline *SettingsSnapshot, all bool, logger *utils.Logger) (*Comparison, error) {
+	sources := []string{}
+	snapshots := []*SettingsSnapshot{}
+	for _, id := range instanceIds {
+		connection, err := registry.Connection(id)
+		if err != nil {
+			return nil, err
+		}
+		snapshot, err := TakeSnapshot(connection.DbHandler, id, logger)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, id)
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if baseline != nil {
+		if err := baseline.validate(); err != nil {
+			return nil, err
+		}
+		sources = append(sources, BaselineSource)
+		snapshots = append(snapshots, baseline)
+	}
+	return Compare(sources, snapshots, all)
+}
+
+/*
+Compares settings of @snapshots with each other. A setting differs if values don't
+match once normalised, or if some of the snapshots don't have it.
+@sources - name each snapshot is listed under
+@all - whether settings with equal values are returned as well
+*/
+func Compare(sources []string, snapshots []*SettingsSnapshot, all bool) (*Comparison, error) {
+	if len(snapshots) < 2 {
+		return nil, ErrNotEnoughSources
+	}
+
+	// 1. Index settings of every snapshot by name
+	indexed := make([]map[string]SnapshotSetting, len(snapshots))
+	seen := map[string]bool{}
+	names := []string{}
+	for i, snapshot := range snapshots {
+		indexed[i] = make(map[string]SnapshotSetting, len(snapshot.Settings))
+		for _, setting := range snapshot.Settings {
+			if !seen[setting.Name] {
+				seen[setting.Name] = true
+				names = append(names, setting.Name)
+			}
+			indexed[i][setting.Name] = setting
+		}
+	}
+	sort.Strings(names)
+
+	// 2. Compare each setting across snapshots
+	comparison := &Comparison{Sources: sources, Compared: len(names), Settings: []SettingComparison{}}
+	for _, name := range names {
+		result := SettingComparison{Name: name, Values: make([]SourceValue, len(snapshots))}
+		first := ""
+		for i := range snapshots {
+			result.Values[i].Source = sources[i]
+			setting, ok := indexed[i][name]
+			if !ok {
+				result.Differs = true
+				continue
+			}
+			value := utils.FormatValueWithUnit(setting.Value, valueOf(setting.Unit))
+			result.Values[i].Value = &value
+
+			normalized := setting.normalize()
+			if i == 0 {
+				first = normalized
+			} else if normalized != first {
+				result.Differs = true
+			}
+		}
+
+		if result.Differs {
+			comparison.Differing++
+		}
+		if result.Differs || all {
+			comparison.Settings = append(comparison.Settings, result)
+		}
+	}
+	return comparison, nil
+}
+
+// Value in a form equal values share regardless of unit or spelling
+func (setting SnapshotSetting) normalize() string {
+	value := strings.TrimSpace(setting.Value)
+	switch valueOf(setting.Vartype) {
+	case "string":
+		return setting.Value
+	case "enum":
+		return strings.ToLower(value)
+	case "bool":
+		switch strings.ToLower(value) {
+		case "on", "true", "yes", "1":
+			return "on"
+		case "off", "false", "no", "0":
+			return "off"
+		}
+		return value
+	}
+
+	// Integers and reals. Snapshots written by hand may leave out the type
+	if number, err := utils.NormalizeValue(value, valueOf(setting.Unit)); err == nil {
+		return utils.Float64ToString(number)
+	}
+	return value
+}
+
+// Makes sure a baseline sent by a client can be compared
+func (snapshot *SettingsSnapshot) validate() error {
+	if len(snapshot.Settings) == 0 {
+		return fmt.Errorf("%w: it has no settings", ErrInvalidBaseline)
+	}
+	seen := make(map[string]bool, len(snapshot.Settings))
+	for _, setting := range snapshot.Settings {
+		if setting.Name == "" {
+			return fmt.Errorf("%w: every setting needs a name", ErrInvalidBaseline)
+		}
+		if seen[setting.Name] {
+			return fmt.Errorf("%w: %s is listed more than once", ErrInvalidBaseline, setting.Name)
+		}
+		seen[setting.Name] = true
+	}
+	return nil
+}
+
+func valueOf(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,271 @@
+// Package compare provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.12.4 DO NOT EDIT.
+package compare
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/deepmap/oapi-codegen/pkg/runtime"
+	"github.com/gin-gonic/gin"
+)
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+
+	// (GET /baselines)
+	GetBaselines(c *gin.Context)
+
+	// (DELETE /baselines/{baseline_name})
+	DeleteBaseline(c *gin.Context, baselineName BaselineName)
+
+	// (GET /baselines/{baseline_name})
+	GetBaseline(c *gin.Context, baselineName BaselineName)
+
+	// (PUT /baselines/{baseline_name})
+	PutBaseline(c *gin.Context, baselineName BaselineName)
+
+	// (GET /baselines/{baseline_name}/drift)
+	GetBaselineDrift(c *gin.Context, baselineName BaselineName)
+
+	// (POST /compare)
+	CompareSettings(c *gin.Context)
+
+	// (GET /drift-events)
+	GetDriftEvents(c *gin.Context, params GetDriftEventsParams)
+
+	// (GET /settings-snapshot)
+	GetSettingsSnapshot(c *gin.Context, params GetSettingsSnapshotParams)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandler       func(*gin.Context, error, int)
+}
+
+type MiddlewareFunc func(c *gin.Context)
+
+// GetBaselines operation middleware
+func (siw *ServerInterfaceWrapper) GetBaselines(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetBaselines(c)
+}
+
+// DeleteBaseline operation middleware
+func (siw *ServerInterfaceWrapper) DeleteBaseline(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "baseline_name" -------------
+	var baselineName BaselineName
+
+	err = runtime.BindStyledParameter("simple", false, "baseline_name", c.Param("baseline_name"), &baselineName)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter baseline_name: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.DeleteBaseline(c, baselineName)
+}
+
+// GetBaseline operation middleware
+func (siw *ServerInterfaceWrapper) GetBaseline(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "baseline_name" -------------
+	var baselineName BaselineName
+
+	err = runtime.BindStyledParameter("simple", false, "baseline_name", c.Param("baseline_name"), &baselineName)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter baseline_name: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetBaseline(c, baselineName)
+}
+
+// PutBaseline operation middleware
+func (siw *ServerInterfaceWrapper) PutBaseline(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "baseline_name" -------------
+	var baselineName BaselineName
+
+	err = runtime.BindStyledParameter("simple", false, "baseline_name", c.Param("baseline_name"), &baselineName)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter baseline_name: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.PutBaseline(c, baselineName)
+}
+
+// GetBaselineDrift operation middleware
+func (siw *ServerInterfaceWrapper) GetBaselineDrift(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "baseline_name" -------------
+	var baselineName BaselineName
+
+	err = runtime.BindStyledParameter("simple", false, "baseline_name", c.Param("baseline_name"), &baselineName)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter baseline_name: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetBaselineDrift(c, baselineName)
+}
+
+// CompareSettings operation middleware
+func (siw *ServerInterfaceWrapper) CompareSettings(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.CompareSettings(c)
+}
+
+// GetDriftEvents operation middleware
+func (siw *ServerInterfaceWrapper) GetDriftEvents(c *gin.Context) {
+
+	var err error
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetDriftEventsParams
+
+	// ------------- Optional query parameter "instance" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "instance", c.Request.URL.Query(), &params.Instance)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter instance: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "external" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "external", c.Request.URL.Query(), &params.External)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter external: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetDriftEvents(c, params)
+}
+
+// GetSettingsSnapshot operation middleware
+func (siw *ServerInterfaceWrapper) GetSettingsSnapshot(c *gin.Context) {
+
+	var err error
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetSettingsSnapshotParams
+
+	// ------------- Optional query parameter "instance" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "instance", c.Request.URL.Query(), &params.Instance)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter instance: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetSettingsSnapshot(c, params)
+}
+
+// GinServerOptions provides options for the Gin server.
+type GinServerOptions struct {
+	BaseURL      string
+	Middlewares  []MiddlewareFunc
+	ErrorHandler func(*gin.Context, error, int)
+}
+
+// RegisterHandlers creates http.Handler with routing matching OpenAPI spec.
+func RegisterHandlers(router *gin.Engine, si ServerInterface) *gin.Engine {
+	return RegisterHandlersWithOptions(router, si, GinServerOptions{})
+}
+
+// RegisterHandlersWithOptions creates http.Handler with additional options
+func RegisterHandlersWithOptions(router *gin.Engine, si ServerInterface, options GinServerOptions) *gin.Engine {
+
+	errorHandler := options.ErrorHandler
+
+	if errorHandler == nil {
+		errorHandler = func(c *gin.Context, err error, statusCode int) {
+			c.JSON(statusCode, gin.H{"msg": err.Error()})
+		}
+	}
+
+	wrapper := ServerInterfaceWrapper{
+		Handler:            si,
+		HandlerMiddlewares: options.Middlewares,
+		ErrorHandler:       errorHandler,
+	}
+
+	router.GET(options.BaseURL+"/baselines", wrapper.GetBaselines)
+
+	router.DELETE(options.BaseURL+"/baselines/:baseline_name", wrapper.DeleteBaseline)
+
+	router.GET(options.BaseURL+"/baselines/:baseline_name", wrapper.GetBaseline)
+
+	router.PUT(options.BaseURL+"/baselines/:baseline_name", wrapper.PutBaseline)
+
+	router.GET(options.BaseURL+"/baselines/:baseline_name/drift", wrapper.GetBaselineDrift)
+
+	router.POST(options.BaseURL+"/compare", wrapper.CompareSettings)
+
+	router.GET(options.BaseURL+"/drift-events", wrapper.GetDriftEvents)
+
+	router.GET(options.BaseURL+"/settings-snapshot", wrapper.GetSettingsSnapshot)
+
+	return router
+}
@@ -0,0 +1,203 @@
+/*
+This is where the implementation of automatically
+generated comparison route goes
+*/
+package compare
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/auth"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/instance"
+	"github.com/gin-gonic/gin"
+)
+
+type CompareImpl struct {
+	Registry  *instance.Registry
+	Baselines *BaselineStore
+	Drift     *DriftDetector
+	Logger    *utils.Logger
+}
+
+// Compares settings of instances with each other and an optional baseline
+func (impl *CompareImpl) CompareSettings(c *gin.Context) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	request := ComparisonRequest{}
+	if err := c.BindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, &ErrorMessage{ErrorMessage: "incorrect payload format"})
+		return
+	}
+
+	if request.BaselineName != nil {
+		if request.Baseline != nil {
+			c.JSON(http.StatusBadRequest, &ErrorMessage{ErrorMessage: "either baseline or baseline_name can be given, not both"})
+			return
+		}
+		baseline, err := impl.Baselines.Baseline(*request.BaselineName)
+		if err != nil {
+			impl.writeError(c, err)
+			return
+		}
+		request.Baseline = &baseline.Snapshot
+	}
+
+	all := request.All != nil && *request.All
+	comparison, err := CompareInstances(impl.Registry, request.Instances, request.Baseline, all, impl.Logger)
+	if err != nil {
+		impl.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, comparison)
+}
+
+// Returns every setting of an instance
+func (impl *CompareImpl) GetSettingsSnapshot(c *gin.Context, params GetSettingsSnapshotParams) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	id := instance.DefaultId
+	if params.Instance != nil && *params.Instance != "" {
+		id = *params.Instance
+	}
+	connection, err := impl.Registry.Connection(id)
+	if err != nil {
+		impl.writeError(c, err)
+		return
+	}
+	snapshot, err := TakeSnapshot(connection.DbHandler, id, impl.Logger)
+	if err != nil {
+		impl.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, snapshot)
+}
+
+// Lists named baselines without their settings
+func (impl *CompareImpl) GetBaselines(c *gin.Context) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	baselines, err := impl.Baselines.Baselines()
+	if err != nil {
+		impl.writeError(c, err)
+		return
+	}
+	summaries := make([]BaselineSummary, 0, len(baselines))
+	for _, baseline := range baselines {
+		summaries = append(summaries, baseline.summary())
+	}
+	c.JSON(http.StatusAccepted, summaries)
+}
+
+// Returns a named baseline with its settings
+func (impl *CompareImpl) GetBaseline(c *gin.Context, baselineName BaselineName) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	baseline, err := impl.Baselines.Baseline(baselineName)
+	if err != nil {
+		impl.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, baseline)
+}
+
+// Saves current settings of an instance, or a snapshot from the body, as a named baseline
+func (impl *CompareImpl) PutBaseline(c *gin.Context, baselineName BaselineName) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	request := BaselineRequest{}
+	if err := c.BindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, &ErrorMessage{ErrorMessage: "incorrect payload format"})
+		return
+	}
+
+	// 1. Make sure the instance exists, the baseline is checked for drift against it
+	id := instance.DefaultId
+	if request.Instance != nil && *request.Instance != "" {
+		id = *request.Instance
+	}
+	connection, err := impl.Registry.Connection(id)
+	if err != nil {
+		impl.writeError(c, err)
+		return
+	}
+
+	// 2. Take a snapshot unless one was sent
+	snapshot := request.Snapshot
+	if snapshot == nil {
+		if snapshot, err = TakeSnapshot(connection.DbHandler, id, impl.Logger); err != nil {
+			impl.writeError(c, err)
+			return
+		}
+	}
+
+	baseline, err := impl.Baselines.Save(baselineName, id, snapshot, auth.Username(c))
+	if err != nil {
+		impl.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, baseline)
+}
+
+// Removes a named baseline
+func (impl *CompareImpl) DeleteBaseline(c *gin.Context, baselineName BaselineName) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	if err := impl.Baselines.Delete(baselineName); err != nil {
+		impl.writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Compares the instance of a baseline with it
+func (impl *CompareImpl) GetBaselineDrift(c *gin.Context, baselineName BaselineName) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	report, err := impl.Drift.Report(baselineName)
+	if err != nil {
+		impl.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, report)
+}
+
+// Lists setting changes drift detection saw, newest first
+func (impl *CompareImpl) GetDriftEvents(c *gin.Context, params GetDriftEventsParams) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	instanceId := ""
+	if params.Instance != nil {
+		instanceId = *params.Instance
+	}
+	c.JSON(http.StatusAccepted, impl.Drift.Events(instanceId, params.External != nil && *params.External))
+}
+
+func (impl *CompareImpl) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrNotEnoughSources), errors.Is(err, ErrInvalidBaseline), errors.Is(err, ErrInvalidName):
+		c.JSON(http.StatusBadRequest, &ErrorMessage{ErrorMessage: err.Error()})
+	case errors.Is(err, instance.ErrInstanceNotFound), errors.Is(err, ErrBaselineNotFound):
+		c.JSON(http.StatusNotFound, &ErrorMessage{ErrorMessage: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, &ErrorMessage{ErrorMessage: fmt.Sprintf("Could not read settings: %v. See /var/log/postgrescrutiniser/error.log for more details", err)})
+	}
+}
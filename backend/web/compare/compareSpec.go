@@ -0,0 +1,136 @@
+// Package compare provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.12.4 DO NOT EDIT.
+package compare
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Base64 encoded, gzipped, json marshaled Swagger object
+var swaggerSpec = []string{
+
+	"H4sIAAAAAAAC/+xbbXPbuBH+Kxj2Zu4LLcs5X8b1t+SS66STXDNR7m5a25UgckXiTAIMAEpRM/rvHSwA",
+	"vkuiU8fjm/qbRRLYxb48eHYBfwkikReCA9cquPwSFFTSHDRI/LWkCjLG4Reag/kdg4okKzQTPLgMzFMi",
+	"VkSnQPyXQRjAZ5oXGQSXQSKyGPhJIUUchAEzYwqq0yAMOE5YzT/H32Eg4VPJJMTBpZYlhIGKUsip1Utr",
+	"kGaGf1/Rk/+8OPnX9OSv85ObL2fh8/Pdd0EY6G1hplRaMp4Eu93OD8eVvJZSyHegFE1wJYUUBUjNAN+C",
+	"eTvP69fdyZqqXXU+v6lki+UfEOlgF1YL64uKJFAN8Zxq82slZG7+CmKq4UQztEJHeFiNWW4HdDOGVZry",
+	"CIXVxo9hRctMD83Had75uO2p3gDFaaFSgSp/J2EVXAZ/Oa0D59QZ+lSB1ownaua/71rO+bnSOGzaoyHn",
+	"kE0/wKcSlO6btmmHdqS+cW9aoUqYIishQ3zorEX8HIStSAYrTUSpWzF9wKz/k5X2LndW5jmV2wePpD0W",
+	"jFKIbiE2liOxZCtNaELNuP0wcK+R6Gw3gEZlvgRp8Mh/QxjvKuXmY1xDAvLO4emFD4WncTSVTAk+4Cp8",
+	"B/EorauP++qGQcxWK0BjjJlqkwoFZE2zEhSxQwdnbVqVacjVyBD+qV5zHcJUSrrFWUUpIxhw1U9uhcR9",
+	"4R0lZAzSq0slkIwpDTFhfEI+djLXvcqo0oQqsvDvFs3Qu2rEnoRCSKO08V61yF6AtRfRiQ+/ojBoOKl2",
+	"yegI2QthNMv65voAupScwBrk1vs2JFxoIni2JRqdrFOqey5eCpEB5d0d6W7gFHa26UEeENfO0cKHMNkw",
+	"nYaIqUBjG5k8ZjzZ465jye/zciCk3sTKzF990dDiWwZErdGQwxEfX6+BD3k6spp3F/K78eOGKhILDiFZ",
+	"SZFjbtAyZppkwkQZ8DI30mlRZNu5KpMElBmucFUK7OqUFhLmSxrdlkVDvcZmkFKeVBtIVw2wOWk/Qo1y",
+	"Gu/TaOTu4wQut32BvyqQNoiNmIboPRJ7k8egIdKHlmO3K/sdE5woumnJ2aQsSklKiwK4ASfmGQPueWQJ",
+	"KyFh9GLhswbJaTaojE5BEi68dd2SpSiTlLwXSicSZpEsNePM2AU+F5nZZAnTIYFJMiFK5CA4EEk5efH2",
+	"4+sPZPbP2cfX78hyS1LK40EMuAeiuBHydp5DPvR1IWHNRKnmiOD9db91fMpwK2NWBzUkZjH/XhP4zJR2",
+	"VnarpCsNklBOyiKRNG7TivN3L4e0uKtwAaqWTvk2F23ICJ4PCtoDBL62acdjIxr24sQHhKM+UDSBezRS",
+	"OpZ2J2545+jYz8VmbQISlVIC13Znd5uUqtO6wc9GkQ80lxNxFKGbkzdoXW2fI5t2S9he54wNef99FXYp",
+	"XUMzHsdEuOE8c4sco0xld6A7p/PIJVUV06gljUom1HPIGX3a2fOIi67GVt4AwL4BVEql2ZFKO2qfGQZi",
+	"/Dfz3HAOoFHqmGzYZrJiRRb2hVqMDW77PU5+NLY90jCvvNP1gOlqXve15bOvcpESaHoLnIjV6BIZ5Brk",
+	"fA1SOfZTDzv7cTqdPjsGNOOM6HTcCxJhgJrfAR87pq+GH4GPriY9q39NSJacDXCcXznTvngyxNvkqOCA",
+	"hRTjLQ9d3N5l61wUydwvcuL+WEzIb06UZFoD98SDRJRbCKDE6Ol28sXZs4t3LxfXbT3Onv9wcT6sibTP",
+	"urqYZA6JK11DIoFmIbHjiJAEmXFTgvtyHOR4Cww6spGXPSfal2031uXFeEtbTDHOI0wrtB9REc1cv0UC",
+	"jbH2y7pkBuWPQ2B0xFF7uBX1LYHpGJWS6e3MZJvbB4FKkC9Knda/fvZZ9fffPwauGYt4jG9rDVKtC9uv",
+	"ZXwl9nULDNqTF+/fTIj9DYo043JhwFZCwpQGiS0DXwiiNRGjBbJuIe0jes0VXRue71K0imiTML6+N4C+",
+	"3GrzlMckZ1nGFESCxyokSviwxpcLjOYFKWgC6pob8L+4Ne8kEPhU0mxC2pWylXQLhclT5y8DjzhbvQAs",
+	"itClLpuveb8JV7W6ottEipLHmGmaafR5v6Qwse5BODibTCdTE5aiAE4LFlwGP+CjEPv16OHTSmvzK4EB",
+	"AHrLlFaEd9booUinwGTVmgpQmKRm6Js4uAz+BvplJQHL10JwZaU9mz4LsIvGtaulTeXLIhx9+ofjAfVh",
+	"wag9ottf7W+2u7CzQFVGEShFvHJmzPn07E66HVKpdUgxIP+jMLttzpRycJfTzOxdEBtNfpxOH0yTmQ1U",
+	"xWIgeBpiK3C77z+UEiWHzwXWWF4H3NcNT7jyDbrgxjysg/f0S6uZtbNRnIGGob5bLtYmSzsxPSGvMOfA",
+	"8GpFaGZweUskRIb2xVVW92L8FQp6WVckzdO2q2Fj1J+ctk7jdje9JDnvL8HLQrImcT3x44ra8+n5g2ny",
+	"i6gLMNwDdMosYD3lz/78CYfR3nal+9lR05cxWH/vSfDs3sznJf35doLHk1OPNqSLciCkZ3SNfKvuWFUn",
+	"aWJFKK97HbQf96YYKTIaoUNqY4ChmTngxxPyoi6fPWMT8dacZ1ku2jgq0fTWTXXNexn0vrzXDMLTqJci",
+	"3t578viTrt1u173esevl7vRBcre1J6LZbc48HPq/4Wuasbry+L9GjyqnavRgT3z2a/ms7fjuLdGq2nkI",
+	"4JoN6u9VzzHQIMCvsedpx2KBes1RMMSmcgZVj8EDenfMNXAAF2Kxu0mFPQNj2NJgujpvvOb7j8Wu+SFm",
+	"gRz9EdOL5qHPE8P4KoYRGr2YboZqu8x5gpD9EOJ/mWamUIewooURG4HBIKTvOqpLm+f+qo5vW1EehyaZ",
+	"E7YGHhJaOfGa1x23f5gLLIeuK2ExLbHUgJiUPDM5saBZtkDSBHoABJzis7r8+BYMp3+b54E5TtS4fDUW",
+	"Px4uHX6Gjb3QwTFm/E0vIS2PtgSopmlPBMiBm+DVnWpmmswC74m0AXDR2vMXT0h3GOlwpz2x3bojzWtP",
+	"aSxhUYNXhgQfbM7TRiXIYQNKkxWTSg9xlFfVsbzqM5S2XoiQBlwrncTKseT6OgNerf9UgtzWd+sbr2uL",
+	"9w59jovDFW5AgjlZOnJRaY8q1eWXAVWq0/ndzUN0/dsXIv7cDf9HnHJ+Sz9p3ok/2EZs3W4daLe0D/vs",
+	"4aoiTE/IrHWKp/AAemlYBnCNB2Lt+8FEC6IAyCalPsjdXb/hemLWvThxJGHryxICr0e0L02I1aj/N/jq",
+	"hP6WFcvA/y08lS1PrY37hI7G7QJMrea9gqsbE932iNwmXikzd3/g8vQ0ExHNUqH05cX0YnpKCxbsbnb/",
+	"HQCdtGPt4DYAAA==",
+}
+
+// GetSwagger returns the content of the embedded swagger specification file
+// or error if failed to decode
+func decodeSpec() ([]byte, error) {
+	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error base64 decoding spec: %s", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var rawSpec = decodeSpecCached()
+
+// a naive cached of a decoded swagger spec
+func decodeSpecCached() func() ([]byte, error) {
+	data, err := decodeSpec()
+	return func() ([]byte, error) {
+		return data, err
+	}
+}
+
+// Constructs a synthetic filesystem for resolving external references when loading openapi specifications.
+func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
+	var res = make(map[string]func() ([]byte, error))
+	if len(pathToFile) > 0 {
+		res[pathToFile] = rawSpec
+	}
+
+	return res
+}
+
+// GetSwagger returns the Swagger specification corresponding to the generated code
+// in this file. The external references of Swagger specification are resolved.
+// The logic of resolving external references is tightly connected to "import-mapping" feature.
+// Externally referenced files must be embedded in the corresponding golang packages.
+// Urls can be supported but this task was out of the scope.
+func GetSwagger() (swagger *openapi3.T, err error) {
+	var resolvePath = PathToRawSpec("")
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, url *url.URL) ([]byte, error) {
+		var pathToFile = url.String()
+		pathToFile = path.Clean(pathToFile)
+		getSpec, ok := resolvePath[pathToFile]
+		if !ok {
+			err1 := fmt.Errorf("path not found: %s", pathToFile)
+			return nil, err1
+		}
+		return getSpec()
+	}
+	var specData []byte
+	specData, err = rawSpec()
+	if err != nil {
+		return
+	}
+	swagger, err = loader.LoadFromData(specData)
+	if err != nil {
+		return
+	}
+	return
+}
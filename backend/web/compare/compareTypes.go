@@ -0,0 +1,193 @@
+// Package compare provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.12.4 DO NOT EDIT.
+package compare
+
+import (
+	"time"
+)
+
+const (
+	BearerAuthScopes = "bearerAuth.Scopes"
+)
+
+// Defines values for DriftEventAction.
+const (
+	ApplySuggestions DriftEventAction = "apply_suggestions"
+	Reset            DriftEventAction = "reset"
+	RestoreBackup    DriftEventAction = "restore_backup"
+)
+
+// ErrorMessage defines model for ErrorMessage.
+type ErrorMessage struct {
+	ErrorMessage string `json:"error_message"`
+}
+
+// Baseline defines model for baseline.
+type Baseline struct {
+	CreatedAt time.Time        `json:"created_at"`
+	CreatedBy *string          `json:"created_by,omitempty"`
+	Instance  string           `json:"instance"`
+	Name      string           `json:"name"`
+	Snapshot  SettingsSnapshot `json:"snapshot"`
+}
+
+// BaselineRequest defines model for baselineRequest.
+type BaselineRequest struct {
+	// Instance Instance the baseline is for, the default instance if left out
+	Instance *string           `json:"instance,omitempty"`
+	Snapshot *SettingsSnapshot `json:"snapshot,omitempty"`
+}
+
+// BaselineSummary defines model for baselineSummary.
+type BaselineSummary struct {
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy *string   `json:"created_by,omitempty"`
+
+	// Instance Instance checked for drift against the baseline
+	Instance string `json:"instance"`
+	Name     string `json:"name"`
+
+	// Settings Number of settings in the baseline
+	Settings int `json:"settings"`
+}
+
+// Comparison defines model for comparison.
+type Comparison struct {
+	// Compared Number of settings compared
+	Compared int `json:"compared"`
+
+	// Differing Number of settings whose values differ
+	Differing int                 `json:"differing"`
+	Settings  []SettingComparison `json:"settings"`
+
+	// Sources Compared sources in the order values are listed in. The baseline is listed last as `baseline`
+	Sources []string `json:"sources"`
+}
+
+// ComparisonRequest defines model for comparisonRequest.
+type ComparisonRequest struct {
+	// All Return every setting, not only those that differ
+	All      *bool             `json:"all,omitempty"`
+	Baseline *SettingsSnapshot `json:"baseline,omitempty"`
+
+	// BaselineName Named baseline to compare with, instead of sending `baseline`
+	BaselineName *string `json:"baseline_name,omitempty"`
+
+	// Instances Ids of instances to compare
+	Instances []string `json:"instances"`
+}
+
+// DriftEvent defines model for driftEvent.
+type DriftEvent struct {
+	// Action What was done, from the audit log
+	Action *DriftEventAction `json:"action,omitempty"`
+
+	// ChangedAt When the change was made, from the audit log
+	ChangedAt *time.Time `json:"changed_at,omitempty"`
+
+	// ChangedBy User that made the change, from the audit log
+	ChangedBy *string `json:"changed_by,omitempty"`
+
+	// DetectedAt When drift detection saw the change, which happened since the check before
+	DetectedAt time.Time `json:"detected_at"`
+
+	// External Whether no change made through PostgreScrutiniser explains it, e.g. someone ran ALTER SYSTEM by hand
+	External bool   `json:"external"`
+	Instance string `json:"instance"`
+	Name     string `json:"name"`
+
+	// PreviousValue Left out if the setting didn't exist before, e.g. after an upgrade
+	PreviousValue *string `json:"previous_value,omitempty"`
+
+	// Value Left out if the setting doesn't exist anymore
+	Value *string `json:"value,omitempty"`
+}
+
+// DriftEventAction What was done, from the audit log
+type DriftEventAction string
+
+// DriftReport defines model for driftReport.
+type DriftReport struct {
+	Baseline  string    `json:"baseline"`
+	CheckedAt time.Time `json:"checked_at"`
+	Instance  string    `json:"instance"`
+
+	// Settings Settings whose current value differs from the baseline
+	Settings []DriftSetting `json:"settings"`
+}
+
+// DriftSetting defines model for driftSetting.
+type DriftSetting struct {
+	// BaselineValue Left out if the baseline doesn't have the setting
+	BaselineValue *string     `json:"baseline_value,omitempty"`
+	LastChange    *DriftEvent `json:"last_change,omitempty"`
+	Name          string      `json:"name"`
+
+	// Value Left out if the instance doesn't have the setting
+	Value *string `json:"value,omitempty"`
+}
+
+// SettingComparison defines model for settingComparison.
+type SettingComparison struct {
+	Differs bool   `json:"differs"`
+	Name    string `json:"name"`
+
+	// Values Value of each source, in the order of `sources`
+	Values []SourceValue `json:"values"`
+}
+
+// SettingsSnapshot defines model for settingsSnapshot.
+type SettingsSnapshot struct {
+	// Instance Instance the snapshot was taken of
+	Instance      *string           `json:"instance,omitempty"`
+	ServerVersion *string           `json:"server_version,omitempty"`
+	Settings      []SnapshotSetting `json:"settings"`
+	TakenAt       time.Time         `json:"taken_at"`
+}
+
+// SnapshotSetting defines model for snapshotSetting.
+type SnapshotSetting struct {
+	Name string `json:"name"`
+
+	// Unit Unit values without one are in
+	Unit *string `json:"unit,omitempty"`
+
+	// Value `pg_settings.setting`. Values written by hand can have a unit, e.g. `128MB`
+	Value string `json:"value"`
+
+	// Vartype bool, integer, real, string or enum
+	Vartype *string `json:"vartype,omitempty"`
+}
+
+// SourceValue defines model for sourceValue.
+type SourceValue struct {
+	Source string `json:"source"`
+
+	// Value Value with its unit scaled for reading, left out if the source doesn't have the setting
+	Value *string `json:"value,omitempty"`
+}
+
+// BaselineName defines model for baselineName.
+type BaselineName = string
+
+// GetDriftEventsParams defines parameters for GetDriftEvents.
+type GetDriftEventsParams struct {
+	// Instance Only list changes of this instance
+	Instance *string `form:"instance,omitempty" json:"instance,omitempty"`
+
+	// External Only list changes that weren't made through PostgreScrutiniser
+	External *bool `form:"external,omitempty" json:"external,omitempty"`
+}
+
+// GetSettingsSnapshotParams defines parameters for GetSettingsSnapshot.
+type GetSettingsSnapshotParams struct {
+	// Instance Instance to take the snapshot of, the default instance if left out
+	Instance *string `form:"instance,omitempty" json:"instance,omitempty"`
+}
+
+// PutBaselineJSONRequestBody defines body for PutBaseline for application/json ContentType.
+type PutBaselineJSONRequestBody = BaselineRequest
+
+// CompareSettingsJSONRequestBody defines body for CompareSettings for application/json ContentType.
+type CompareSettingsJSONRequestBody = ComparisonRequest
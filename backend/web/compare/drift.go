@@ -0,0 +1,275 @@
+// Drift detection. Instances that have a baseline are snapshotted periodically and
+// every setting that changed since the previous check is recorded as a drift event.
+// Events are attributed to the change in the audit log that explains them, if there
+// is one, and marked external otherwise, e.g. when someone ran ALTER SYSTEM by hand.
+package compare
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/instance"
+)
+
+const DefaultDriftStoreFile = "/usr/local/postgrescrutiniser/confs/drift.json"
+
+// How often instances that have a baseline are checked
+const DriftCheckInterval = 5 * time.Minute
+
+// Number of drift events kept, older ones are dropped
+const maxDriftEvents = 1000
+
+// Audit entries are written after settings were reloaded, so ones from shortly
+// before the previous check may still explain a change it didn't see yet
+const auditSlack = time.Minute
+
+// Contents of the store file
+type driftState struct {
+	Observed map[string]*SettingsSnapshot `json:"observed"` // last snapshot of each checked instance
+	Events   []DriftEvent                 `json:"events"`   // oldest first
+}
+
+type DriftDetector struct {
+	mutex     sync.Mutex
+	path      string
+	registry  *instance.Registry
+	baselines *BaselineStore
+	audit     *utils.AuditLog
+	state     driftState
+	logger    *utils.Logger
+}
+
+// Creates a detector with events and snapshots read from @path. Nothing is checked until `Start()`.
+func NewDriftDetector(path string, registry *instance.Registry, baselines *BaselineStore, audit *utils.AuditLog, logger *utils.Logger) *DriftDetector {
+	detector := &DriftDetector{
+		path:      path,
+		registry:  registry,
+		baselines: baselines,
+		audit:     audit,
+		state:     driftState{Observed: map[string]*SettingsSnapshot{}, Events: []DriftEvent{}},
+		logger:    logger,
+	}
+	detector.load()
+	return detector
+}
+
+func (detector *DriftDetector) load() {
+	content, err := os.ReadFile(detector.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			detector.logger.LogError(fmt.Errorf("could not read drift events from %s: %v", detector.path, err))
+		}
+		return
+	}
+	state := driftState{}
+	if err := json.Unmarshal(content, &state); err != nil {
+		detector.logger.LogError(fmt.Errorf("could not parse drift events in %s: %v", detector.path, err))
+		return
+	}
+	if state.Observed != nil {
+		detector.state.Observed = state.Observed
+	}
+	if state.Events != nil {
+		detector.state.Events = state.Events
+	}
+}
+
+// Writes events and snapshots to the store file. Caller has to hold the mutex.
+func (detector *DriftDetector) save() error {
+	content, err := json.Marshal(detector.state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(detector.path), 0755); err != nil {
+		detector.logger.LogError(fmt.Errorf("could not create directory for %s: %v", detector.path, err))
+		return err
+	}
+	temporary := detector.path + ".tmp"
+	if err := os.WriteFile(temporary, content, 0644); err != nil {
+		detector.logger.LogError(fmt.Errorf("could not save drift events to %s: %v", temporary, err))
+		return err
+	}
+	if err := os.Rename(temporary, detector.path); err != nil {
+		detector.logger.LogError(fmt.Errorf("could not save drift events to %s: %v", detector.path, err))
+		return err
+	}
+	return nil
+}
+
+// Checks instances that have a baseline right away and then every `DriftCheckInterval`
+func (detector *DriftDetector) Start() {
+	go func() {
+		ticker := time.NewTicker(DriftCheckInterval)
+		defer ticker.Stop()
+		for {
+			detector.Check()
+			<-ticker.C
+		}
+	}()
+}
+
+// Checks every instance that has a baseline for settings that changed since its previous check
+func (detector *DriftDetector) Check() {
+	baselines, err := detector.baselines.Baselines()
+	if err != nil {
+		detector.logger.LogError(fmt.Errorf("could not list baselines for drift detection: %v", err))
+		return
+	}
+	watched := map[string]bool{}
+	for _, baseline := range baselines {
+		watched[baseline.Instance] = true
+	}
+
+	for id := range watched {
+		if err := detector.checkInstance(id); err != nil {
+			detector.logger.LogWarning(fmt.Errorf("could not check instance %s for drift: %v", id, err))
+		}
+	}
+
+	detector.mutex.Lock()
+	defer detector.mutex.Unlock()
+	// Instances that got a baseline again later start over instead of reporting everything since
+	for id := range detector.state.Observed {
+		if !watched[id] {
+			delete(detector.state.Observed, id)
+		}
+	}
+	detector.save()
+}
+
+// Records changes of instance @id since its previous check. The first check only records what there is.
+func (detector *DriftDetector) checkInstance(id string) error {
+	// 1. Take a snapshot
+	connection, err := detector.registry.Connection(id)
+	if err != nil {
+		return err
+	}
+	snapshot, err := TakeSnapshot(connection.DbHandler, id, detector.logger)
+	if err != nil {
+		return err
+	}
+
+	detector.mutex.Lock()
+	defer detector.mutex.Unlock()
+	previous := detector.state.Observed[id]
+	detector.state.Observed[id] = snapshot
+	if previous == nil {
+		return nil
+	}
+
+	// 2. Compare with the previous one
+	comparison, err := Compare([]string{"previous", id}, []*SettingsSnapshot{previous, snapshot}, false)
+	if err != nil || comparison.Differing == 0 {
+		return err
+	}
+
+	// 3. Record each change, along with who made it when the audit log knows
+	entries, err := detector.audit.Entries(id, previous.TakenAt.Add(-auditSlack))
+	if err != nil {
+		detector.logger.LogWarning(fmt.Errorf("could not read audit log, drift of instance %s is reported as external: %v", id, err))
+	}
+	for _, setting := range comparison.Settings {
+		event := DriftEvent{
+			Instance:      id,
+			Name:          setting.Name,
+			PreviousValue: setting.Values[0].Value,
+			Value:         setting.Values[1].Value,
+			DetectedAt:    snapshot.TakenAt,
+			External:      true,
+		}
+		if entry := explainingEntry(entries, setting.Name); entry != nil {
+			action := DriftEventAction(entry.Action)
+			changedAt := entry.Time
+			changedBy := entry.Actor
+			event.External = false
+			event.Action = &action
+			event.ChangedAt = &changedAt
+			event.ChangedBy = &changedBy
+		}
+		detector.state.Events = append(detector.state.Events, event)
+	}
+	if excess := len(detector.state.Events) - maxDriftEvents; excess > 0 {
+		detector.state.Events = append([]DriftEvent{}, detector.state.Events[excess:]...)
+	}
+	return nil
+}
+
+// Latest audit entry that changed setting @name. Resets and restores replace
+// postgresql.auto.conf as a whole, so they explain any setting.
+func explainingEntry(entries []utils.AuditEntry, name string) *utils.AuditEntry {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if _, ok := entries[i].Settings[name]; ok || entries[i].Settings == nil {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+// Lists drift events newest first, only those of @instanceId if it isn't empty
+// and only external ones if @externalOnly is set
+func (detector *DriftDetector) Events(instanceId string, externalOnly bool) []DriftEvent {
+	detector.mutex.Lock()
+	defer detector.mutex.Unlock()
+
+	events := []DriftEvent{}
+	for i := len(detector.state.Events) - 1; i >= 0; i-- {
+		event := detector.state.Events[i]
+		if (instanceId == "" || event.Instance == instanceId) && (!externalOnly || event.External) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Compares current settings of the instance of baseline @name with the baseline
+func (detector *DriftDetector) Report(name string) (*DriftReport, error) {
+	baseline, err := detector.baselines.Baseline(name)
+	if err != nil {
+		return nil, err
+	}
+	connection, err := detector.registry.Connection(baseline.Instance)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := TakeSnapshot(connection.DbHandler, baseline.Instance, detector.logger)
+	if err != nil {
+		return nil, err
+	}
+	comparison, err := Compare([]string{BaselineSource, baseline.Instance}, []*SettingsSnapshot{&baseline.Snapshot, snapshot}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DriftReport{Baseline: name, Instance: baseline.Instance, CheckedAt: snapshot.TakenAt, Settings: []DriftSetting{}}
+	for _, setting := range comparison.Settings {
+		report.Settings = append(report.Settings, DriftSetting{
+			Name:          setting.Name,
+			BaselineValue: setting.Values[0].Value,
+			Value:         setting.Values[1].Value,
+			LastChange:    detector.lastChange(baseline.Instance, setting.Name, baseline.CreatedAt),
+		})
+	}
+	return report, nil
+}
+
+// Latest drift event of setting @name on instance @instanceId detected after @since
+func (detector *DriftDetector) lastChange(instanceId string, name string, since time.Time) *DriftEvent {
+	detector.mutex.Lock()
+	defer detector.mutex.Unlock()
+
+	for i := len(detector.state.Events) - 1; i >= 0; i-- {
+		event := detector.state.Events[i]
+		if event.DetectedAt.Before(since) {
+			break
+		}
+		if event.Instance == instanceId && event.Name == name {
+			return &event
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package file
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -27,12 +28,13 @@ func ListBackups(path, currentFile string, logger *utils.Logger) (*[]BackupFile,
 		return nil, err
 	}
 
-	// 2. Return only backup files. Takes into account there being other files as well
+	// 2. Return only backup files. Takes into account there being other files as well,
+	// like per-database and per-role settings saved along with each backup
 	var backups []BackupFile
 	for _, file := range files {
 		filename := file.Name()
 
-		match, _ := regexp.MatchString("postgresql.auto.conf", filename)
+		match, _ := regexp.MatchString(`postgresql.auto.conf_(\d{10})$`, filename)
 		if match {
 			// 3. Get file diff and return final response object
 			fullBackupPath := path + "/" + file.Name()
@@ -47,6 +49,12 @@ func ListBackups(path, currentFile string, logger *utils.Logger) (*[]BackupFile,
 				Time: *datetime,
 				Diff: diff,
 			}
+			roleSettings, err := utils.ReadRoleSettingsBackup(fullBackupPath)
+			if err != nil {
+				logger.LogWarning(fmt.Errorf("failed reading per-database and per-role settings of %s: %v", filename, err))
+			} else if roleSettings != nil {
+				backupFile.RoleSettings = &roleSettings
+			}
 			backups = append(backups, backupFile)
 		}
 	}
@@ -99,32 +107,55 @@ func CompareBackup(backupFile, currentFile string, logger *utils.Logger) ([]File
 }
 
 /*
-Replaces current postgresql.auto.conf file with backup file and reloads configuration
+Replaces current postgresql.auto.conf file with backup file and reloads configuration.
+PostgreSQL is restarted if the backup changes settings that need it. If it doesn't
+come back, the configuration that was in use before is put back and the backup is kept.
+Otherwise per-database and per-role settings saved with the backup are put back as well
+and the backup is removed.
 @backupFile - full path to backup postgresql.auto.conf file
 @currentFile - full path to currently used postgresql.auto.conf file
+@service - restarts PostgreSQL if the restored settings need it
 */
-func RestoreBackup(postgresUsername, backupFile, currentFile string, appUser *utils.User, db *sql.DB, logger *utils.Logger) error {
-	// 1. Create a backup of current postgresql.auto.conf
-	if err := utils.BackupFile(currentFile, path.Dir(backupFile), appUser, logger); err != nil {
-		return err
+func RestoreBackup(postgresUsername, backupFile, currentFile string, appUser *utils.User, db *sql.DB, service utils.ServiceController, logger *utils.Logger) (*BackupRestore, error) {
+	// 1. Create a backup of current postgresql.auto.conf and per-database and per-role settings
+	currentBackup, err := utils.BackupFile(currentFile, path.Dir(backupFile), appUser, logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.BackupRoleSettings(db, currentBackup, logger); err != nil {
+		return nil, err
 	}
 
-	// 2. Restore specified backup
-	cmd := exec.Command("sudo", "mv", backupFile, currentFile)
+	// 2. Restore specified backup. It's copied rather than moved so that it's still there if the change is undone.
+	cmd := exec.Command("sudo", "cp", backupFile, currentFile)
 	if err := cmd.Run(); err != nil {
 		err = fmt.Errorf("error replacing current postgresql.auto.conf with backup: %v", err)
 		logger.LogError(err)
-		return err
+		return nil, err
 	}
 	cmd = exec.Command("sudo", "chown", postgresUsername+".", currentFile)
 	if err := cmd.Run(); err != nil {
 		err = fmt.Errorf("error replacing current postgresql.auto.conf with backup: %v", err)
 		logger.LogError(err)
-		return err
+		return nil, err
 	}
 
-	err := utils.ReloadConfiguration(db, logger)
-	return err
+	// 3. Reload, restart if needed and roll back to the backup made in step 1 if PostgreSQL doesn't come back
+	restore := &BackupRestore{}
+	restore.Steps, restore.Restarted, err = utils.ActivateConfiguration(db, service, currentFile, currentBackup, postgresUsername, logger)
+	restore.RolledBack = errors.Is(err, utils.ErrRolledBack)
+
+	// 4. Put back per-database and per-role settings unless the change was undone.
+	// The backup is removed once it's in use, settings saved with it go as well.
+	// If the change was undone, both are kept so the backup can still be restored.
+	if err == nil {
+		if restore.Steps, err = utils.RestoreRoleSettings(db, backupFile, restore.Steps, logger); err != nil {
+			return restore, err
+		}
+		// The restore went through either way, failing to remove the backup is only logged
+		RemoveBackup(backupFile, logger)
+	}
+	return restore, err
 }
 
 // Removes all backups of postgresql.auto.conf
@@ -144,11 +175,16 @@ func RemoveBackups(backupDir string, logger *utils.Logger) error {
 	return nil
 }
 
-// Removes postgresql.auto.conf backup
+// Removes postgresql.auto.conf backup along with per-database and per-role settings saved with it
 func RemoveBackup(backupFile string, logger *utils.Logger) error {
 	err := os.Remove(backupFile)
 	if err != nil {
 		logger.LogError(fmt.Errorf("error removing %s: %v", backupFile, err))
+		return err
+	}
+	if err := os.Remove(backupFile + utils.RoleSettingsSuffix); err != nil && !os.IsNotExist(err) {
+		logger.LogError(fmt.Errorf("error removing %s: %v", backupFile+utils.RoleSettingsSuffix, err))
+		return err
 	}
-	return err
+	return nil
 }
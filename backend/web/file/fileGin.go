@@ -24,7 +24,7 @@ type ServerInterface interface {
 	DeleteBackup(c *gin.Context, backupName string)
 
 	// (PUT /backup/{backup_name})
-	PutBackup(c *gin.Context, backupName string)
+	PutBackup(c *gin.Context, backupName string, params PutBackupParams)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -99,11 +99,30 @@ func (siw *ServerInterfaceWrapper) PutBackup(c *gin.Context) {
 
 	c.Set(BearerAuthScopes, []string{""})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PutBackupParams
+
+	// ------------- Optional query parameter "apply_at" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "apply_at", c.Request.URL.Query(), &params.ApplyAt)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter apply_at: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "window" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "window", c.Request.URL.Query(), &params.Window)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter window: %s", err), http.StatusBadRequest)
+		return
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
 
-	siw.Handler.PutBackup(c, backupName)
+	siw.Handler.PutBackup(c, backupName, params)
 }
 
 // GinServerOptions provides options for the Gin server.
@@ -3,22 +3,32 @@ package file
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"sync"
 
 	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/auth"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/schedule"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
 type FileImpl struct {
+	Instance         string // id of the instance routes act on
 	BackupDir        string // directory in which backups are saved
 	CurrentFile      string // full path to currently used postgresql.auto.conf
 	PostgresUsername string
 	AppUser           *utils.User
 	Logger           *utils.Logger
 	DbHandler        *sql.DB
+	Service          utils.ServiceController
+	Scheduler        *schedule.Scheduler
 	Validate         *validator.Validate
+	Audit            *utils.AuditLog // restores that went through are recorded here
+	Mutex            *sync.Mutex     // shared with the instance's ResourceConfigImpl, held while backups and postgresql.auto.conf change
 }
 
 type AutoConfBackup struct {
@@ -72,7 +82,7 @@ func (impl *FileImpl) GetBackups(c *gin.Context) {
 }
 
 // Replaces current postgresql.auto.conf file with backup file and reloads configuration
-func (impl *FileImpl) PutBackup(c *gin.Context, backupName string) {
+func (impl *FileImpl) PutBackup(c *gin.Context, backupName string, params PutBackupParams) {
 	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
 		return
 	}
@@ -83,15 +93,75 @@ func (impl *FileImpl) PutBackup(c *gin.Context, backupName string) {
 		return
 	}
 
-	// 2. Replace backup
+	// 2. Restores that have to wait are only scheduled
 	fullPath := impl.BackupDir + "/" + backupName
-	if err := RestoreBackup(impl.PostgresUsername, fullPath, impl.CurrentFile, impl.AppUser, impl.DbHandler, impl.Logger); err != nil {
+	if params.ApplyAt != nil || params.Window != nil {
+		impl.scheduleRestore(c, backupName, fullPath, params)
+		return
+	}
+
+	// 3. Replace backup
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
+	restore, err := RestoreBackup(impl.PostgresUsername, fullPath, impl.CurrentFile, impl.AppUser, impl.DbHandler, impl.Service, impl.Logger)
+	if restore != nil && restore.RolledBack {
+		c.JSON(http.StatusInternalServerError, restore)
+		return
+	}
+	if err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: err.Error(),
+		}
+		c.JSON(http.StatusInternalServerError, &errorMsg)
+		return
+	}
+	impl.Audit.Record(utils.AuditEntry{Instance: impl.Instance, Actor: auth.Username(c), Action: utils.AuditRestoreBackup, Backup: backupName}, impl.Logger)
+	c.JSON(http.StatusOK, restore)
+}
+
+// Schedules restoring backup @backupName at `apply_at` or in `window`
+func (impl *FileImpl) scheduleRestore(c *gin.Context, backupName string, fullPath string, params PutBackupParams) {
+	if _, err := os.Stat(fullPath); err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: fmt.Sprintf("backup %s does not exist", backupName),
+		}
+		c.JSON(http.StatusNotFound, &errorMsg)
+		return
+	}
+
+	instanceId := impl.Instance
+	createdBy := auth.Username(c)
+	change, err := impl.Scheduler.Schedule(schedule.ScheduledChange{Kind: schedule.RestoreBackup, Instance: &instanceId, CreatedBy: &createdBy, BackupName: &backupName}, params.ApplyAt, params.Window)
+	if errors.Is(err, schedule.ErrInvalidSchedule) || errors.Is(err, schedule.ErrWindowNotFound) {
+		c.JSON(http.StatusBadRequest, &ErrorMessage{ErrorMessage: err.Error()})
+		return
+	}
+	if err != nil {
 		errorMsg := &ErrorMessage{
 			ErrorMessage: err.Error(),
 		}
 		c.JSON(http.StatusInternalServerError, &errorMsg)
 		return
 	}
+	c.JSON(http.StatusAccepted, change)
+}
+
+// Restores the backup of a scheduled change, registered with the scheduler for `restore_backup` changes
+func (impl *FileImpl) RunScheduledChange(change *schedule.ScheduledChange) (*schedule.ChangeOutcome, error) {
+	if change.BackupName == nil || impl.Validate.Struct(AutoConfBackup{Name: *change.BackupName}) != nil {
+		return nil, fmt.Errorf("scheduled change %s has no valid backup name", change.Id)
+	}
+
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
+	restore, err := RestoreBackup(impl.PostgresUsername, impl.BackupDir+"/"+*change.BackupName, impl.CurrentFile, impl.AppUser, impl.DbHandler, impl.Service, impl.Logger)
+	if restore == nil {
+		return nil, err
+	}
+	if err == nil {
+		impl.Audit.Record(utils.AuditEntry{Instance: impl.Instance, Actor: schedule.CreatedBy(change), Action: utils.AuditRestoreBackup, Backup: *change.BackupName}, impl.Logger)
+	}
+	return &schedule.ChangeOutcome{Restarted: restore.Restarted, RolledBack: restore.RolledBack, Steps: restore.Steps}, err
 }
 
 func (impl *FileImpl) DeleteBackups(c *gin.Context) {
@@ -99,6 +169,8 @@ func (impl *FileImpl) DeleteBackups(c *gin.Context) {
 		return
 	}
 
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
 	if err := RemoveBackups(impl.BackupDir, impl.Logger); err != nil {
 		errorMsg := &ErrorMessage{
 			ErrorMessage: err.Error(),
@@ -119,7 +191,9 @@ func (impl *FileImpl) DeleteBackup(c *gin.Context, backupName string) {
 		return
 	}
 
-	// 2. Delete backup
+	// 2. Delete backup, not while a change may still roll back to it
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
 	fullPath := impl.BackupDir + "/" + backupName
 	if err := RemoveBackup(fullPath, impl.Logger); err != nil {
 		errorMsg := &ErrorMessage{
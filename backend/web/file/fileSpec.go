@@ -18,24 +18,37 @@ import (
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xXbWvjRhD+K9O9g3yoEsvKG2foh5TkSspdOZpAKYlr1tLI2ou0u9kd2TFG/73Myo7t",
-	"WJcrpQRS8snW7uy8PPPM7OxCpKayRqMmLwYL4dMCKxn+/izTu9p+VCXyFz7IyvLfm4XQskIxENZ4mjj0",
-	"9+WBrMkcpEbno/7J6Yfjk9PkKBaRIBUEkzg53I8P95PD634ySE4HSfxjnAziWDTDSFhnLDpSGKxmKs+f",
-	"2iuVZjXv4NyANgSYKQIqlIdclQiV1LUsy/kPt/odXBLMVFnCGMFM0c2cIkIN4zlQgXD26frid7j68+r6",
-	"4jOkpqqkzg5uNbs6t2zj4r6WpWiiR6OVfBilRmtMSRnt4SfY68fx3q3mkKcyretqNDPublRhxZvJSdI/",
-	"Ot671UU9wZGVEwxnTJ7vbdq51B4dhfgVYRVCf+8w5zB765T0lvnocRrOVZ5/Yq+aRzXSOTnn7zYlC5Gh",
-	"T52y7KsYhFUweQh9HNIZEFu74ckpPQkKVZcCXvUkKwu5cTArUD/VBTPpIXUoCTMRidy4SpIYiEwS7gel",
-	"O8aaSDi8r5XDTAxuWteXDkRt/oePZ8z4K6bEDl44Z9xn9F5OgqPbtEHeHVXr7edtbot3mdsCfMdcuVzd",
-	"RotXgQpJoDyzy0qHGYyRZsjAzUxAzHfCHxaeKvQWU5Ur9Aw9FeggmCikhzGrlFmGWQQOKzPFDIyDWqeF",
-	"1JOQC9R1xcG2nI5WnIvEOZZIm2F/A6UQ5VJoF6MmEh7T2imaXzFLW2TGKB26s5qK9dfHFSl+/eNaRG2P",
-	"YU3t7hqOgsiKhhUrnZuQRUXcA0I2gNOBDnWKcPblUkRiis63QPUP4oOYcTQWtbRKDMRhWIqElVQEz3ot",
-	"a1uUAwA7eLdAepBlCV3dbZP4nEamhOSzl5kYLGFt2ybvOvTWaN/iksRHHfmt0xS9h5UkR3AU91kwNZpQ",
-	"E/+V1pYqDXZ6Xz0fXPXp73WNrZoJwG7bvzZ3qKFS3is9YfpUsuQKxow9OY7jF/PkCt0UHXiVIYTiFEEk",
-	"l3VJL+ZErfHBYkqYrXzgypQTz8WwZM+wicQEaTeXE6R/yZtfkL5JmuQ/C37jOu8I/Y2Jr5CJTbRqar1F",
-	"+zviy7R5rsW16yBhebek3yXss30uNFgnKyR0Pgxr2+Z+655AgAwsPYzWo96zE+VpPxF8MbCYpEKsZh6x",
-	"EbnYvL/I1Rht5MNKInR8/q9OOzfx/ofhoh837ztGluFbO//ftnNbU9co4Mk49FusXc1jGb8nHnm/UyBf",
-	"avpn1RGUdgzpZPj9snQhe7UlcrwL6+OTYVkreV2W83WkoWBejqaXeipLla1TCcv3y1vhvoLbb+MBEmpr",
-	"8+lxM2yGzd8BAAD//4I+TJrdEAAA",
+	"H4sIAAAAAAAC/+xZ/2/bxhX/V96uBbJhlETLToJq2A/pkhYe0i2LDRSD7Skn3qN0NXlH3z1aFgz978O7",
+	"IyVKYhy1yIJ26E82ybv35fO+Pz2KzJaVNWjIi8mjqKSTJRK68CSrqli9Iv5Xoc+crkhbIybifW2AFgjZ",
+	"Qpo5giSghfZAukTQxhNKBTYHp+cLArmUqwQ8IoxkpUc+W6CqC1SDeNuLRGgmelejW4lEGFmimETmU0ki",
+	"EXyllCxGbl0pSUyEkoQD5icSQauKL3hy2szFep2IpTbKLj8pt45PBh8IbJbVzqHJkCUP6pRSG0Ij+V2k",
+	"2K/cRxRohOiKvy/puv0Y4P5WZrd19Z0ukJ/wQZYV/3v12FKsrKe5Q39XDGVNdphZk09PXrz85vmLl+Oz",
+	"lKHQ4eA4HZ8O0tPB+PTyZDwZv5yM0z+n40maivVNIipnK3SkMXBVOs/3+RXaMJmv4LUFYwlQ6cbEuS4Q",
+	"SmlqWRSrP1ybr+CcYKmLAmYI9h7d0mkiNDBbBWxfvb188x4u/n1x+eYHyGxZSqOG12ZrtTd3tSzEOtkw",
+	"LeXDNLPGYMZG8/BXeHaSps+uDat8L7O6LqdL626nJZb8cfxifHL2/Nm1WdRznFZyjuGOzfNnXT7nxqOj",
+	"oL8mLIPqXzvMWc3RNghGjT1GbIbXOs/fslTrDRnpnFzxczTJvoPx2+g/CLNgzoDYoZMmwtkCpx6JtJn7",
+	"Q0rv0A2UJDmTHkEaBRW6Ad+B9g78sZpP1WzaJfQn8PIeFcjCmjksNS26okijrk1VU3iG5QINaALtwaEn",
+	"61AN4S3mBLYmyK1rrnkgecsWxdw6ZHorWKLDyCpgfBSiLOdFFLMP0Oi6+zDwW0+yrIJAQeQ9bGEpPWQO",
+	"JaESybEZwuFdrR0qMbmKpmwESGI83Gzu2NlPmBEL+MY5635A7+U8CLobRshfp+X289M8d4/3sdtxwAN2",
+	"hTY9aPFboIUMRmUTSIcKZkhLZOCWNiDm+9wxvtgn6CvMdK7RM/S0QAeBxUJ6mDFJqRSqBByWlt3OOqhN",
+	"TK5sCzR1ycrGGE/aGEzEayyQump/BKWgZXOoD6PoBu+j9x6C5Nh1HPvFgWI/Nuq8iyn14l9vgx9tb2y4",
+	"zawtUJomZAtUU+Z6FEWllXlGbIjosSGO2X0rh/fa1h76MnoQpI3SXjk8YRUUPCrumKae/y1Y5YKwOgy+",
+	"PdS7IHRVbhn3WeKAyYExZBaBety4hcPCysAkMhSJWKAsaDHNFpjdNtwb1rvp8qbHgxWS1IXvs4ykAKqy",
+	"BhMIeWTFmS+XuuiaekvL11mG3nfieAP/HliNWtsrW0EOcErEw2BuB83LmnThhx3IOp8Huqyso9iS0UJM",
+	"xFzTop4NM1uOvi/sbOXT05NR62uZq0kb7dGNGC40ahSoR2k7effAKm2JOUTtdfMlOCxXdqcVAndlnA60",
+	"SQDv0a1gU6R0DkVTPfogbQtmbyHsadVs8RHWZFvWfPNTbO9lUR+Rj5saEE8fZbj3HVj/R5bbtMrRSQ5B",
+	"iu9hKTWLAWTB1SaBeGiGqu1wW0Lw6t25eFq59ujwYo/5Z9VxibPNIND0wZjVTtOK2ZbRN2coHbpXNS22",
+	"T9+15f3vP162rXUIz/B1q9uCqIqEtcltsL8m9rNQV4ELK8ZuP0Jyj85HTE+G6TBlz7EVGllpMRGn4VUS",
+	"lA2SjWLhiQYJpezANLEkepBF0Z/lOy2MF4Gbk3z3XHHwBapxIPAxQ1bW+IjLOD075NekH2hPsgZn6Qkf",
+	"zKwhNNROdDoLfEY/+ZiOt+PJU1Vkp/sJwO7yv7TcI5bae/ZE66CUBfdiqFiS52n6xSS5QHePDjznjNBm",
+	"xeKQy7qgLyZEbfChwoxQtTJw1ZXc6V81bYu4WSdijj3j9RzpF/rN90gfdZrxZ1O+M6j2qP67J/4GPXGd",
+	"tElt9Bj/Trkkrp9KcfE9SGimhOyTDvtknhPJzvrpap/dP/pna656jYTJdonx5K7k5cm43dmE+rVZ2XQ0",
+	"F93+gFyN3T1OJYnQ8f3/9PK5Sgff3DyepOuve4bPm9/T+f9tOq9q6msFwnzqd7y2nawVb8o2fn8QIO9q",
+	"Oi46AtGe9RNZ3sy1C57fSIgk/dbaAjBqV9NHHG32sD1x9/mceHcR0eNAm01VE9h5XRSrzt6tszUI+zie",
+	"jONmpZnFec4xiApVs8ZzdobqL/xaEyiLfnfV8Ok1g95uGa7DWuFztgj7o0sPJA1aEZX2eDPD8E8KH9pf",
+	"AD5w/tEGPkRLfoi58MtloHNzLwuttlEKccnIYm2HmF9Tfj5Lz76YJN9u8sxmypStY3Mual0TH7SnX1I8",
+	"rMF/5iHj/YwITH6OUjfHlJ0hnMfE2gZtm2FvsaJu/ObOlhCilk3DKz8d1k/XJi7Twr0kkPJ4V7c/OYXd",
+	"Wgx+qp1B1f2xSZooBDQb42vz662C3Wk+mK07x1/drG/W/x0ACB7uTXYcAAA=",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -5,6 +5,9 @@ package file
 
 import (
 	"time"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/schedule"
 )
 
 const (
@@ -25,6 +28,10 @@ type BackupFile struct {
 	// Name name of the backup file
 	Name string `json:"name"`
 
+	// RoleSettings Per-database and per-role settings (pg_db_role_setting) saved along with the backup and
+	// put back when it is restored. Left out for backups taken before they were saved
+	RoleSettings *[]RoleSetting `json:"role_settings,omitempty"`
+
 	// Time timestamp for when the backup file was created
 	Time time.Time `json:"time"`
 }
@@ -45,3 +52,37 @@ type FileDiffLine struct {
 
 // FileDiffLineType specifies whether line has been added, removed or unchanged
 type FileDiffLineType string
+
+// BackupRestore defines model for backupRestore.
+type BackupRestore struct {
+	// Restarted Whether PostgreSQL was restarted
+	Restarted bool `json:"restarted"`
+
+	// RolledBack Whether PostgreSQL didn't come back and the previous postgresql.auto.conf was put back
+	RolledBack bool               `json:"rolled_back"`
+	Steps      []ConfigChangeStep `json:"steps"`
+}
+
+// ConfigChangeStep defines model for configChangeStep.
+type ConfigChangeStep = utils.ChangeStep
+
+// RoleSetting defines model for roleSetting.
+type RoleSetting = utils.RoleSetting
+
+// ScheduledChange Change waiting to run, described in the schedule API
+type ScheduledChange = schedule.ScheduledChange
+
+// ApplyAt defines model for applyAt.
+type ApplyAt = time.Time
+
+// Window defines model for window.
+type Window = string
+
+// PutBackupParams defines parameters for PutBackup.
+type PutBackupParams struct {
+	// ApplyAt Run the change at this time instead of right away, see /api/scheduled-changes
+	ApplyAt *ApplyAt `form:"apply_at,omitempty" json:"apply_at,omitempty"`
+
+	// Window Run the change in the next occurrence of this maintenance window instead of right away
+	Window *Window `form:"window,omitempty" json:"window,omitempty"`
+}
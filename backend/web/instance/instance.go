@@ -0,0 +1,478 @@
+// Registry of PostgreSQL instances managed from this deployment. Instances are
+// kept in a JSON file without their passwords, which are read from the configured
+// credentials source whenever a connection is opened. The default instance comes
+// from ~/.pgpass and dev.env at startup and isn't stored.
+package instance
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+const DefaultStoreFile = "/usr/local/postgrescrutiniser/confs/instances.json"
+
+// Id of the instance routes without an instance act on
+const DefaultId = "default"
+
+var (
+	ErrInstanceNotFound = errors.New("instance not found")
+	ErrInstanceExists   = errors.New("an instance with this id already exists")
+	ErrReadOnlyInstance = errors.New("the default instance is configured through ~/.pgpass and dev.env and can't be changed")
+	ErrInvalidInstance  = errors.New("invalid instance")
+	ErrRemoteInstance   = errors.New("the instance runs on another host, its postgresql.auto.conf can't be changed and it can't be restarted from here")
+)
+
+var idPattern = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+
+// Everything handlers need to work with an instance
+type Connection struct {
+	Instance     Instance
+	DbHandler    *sql.DB
+	DbInfo       *utils.DbConnectionInfo
+	PostgresUser *utils.User             // operating system user PostgreSQL runs as
+	Service      utils.ServiceController // restarts the instance
+	ConfigFile   string                  // postgresql.conf, empty if it couldn't be found
+	BackupDir    string                  // directory backups of the instance are kept in
+	Local        bool                    // whether the instance runs on this host. Others are only read and changed through SQL.
+}
+
+type Registry struct {
+	mutex       sync.Mutex
+	path        string // file registered instances are persisted to
+	backupDir   string // parent of per instance backup directories
+	appUser     *utils.User
+	instances   []Instance             // registered instances, the default instance isn't one of them
+	connections map[string]*Connection // opened on first use
+	logger      *utils.Logger
+}
+
+/*
+Creates a registry with instances read from @path.
+@backupDir - directory each instance gets a backup subdirectory in
+@defaultConnection - connection of the default instance, set up at startup
+*/
+func NewRegistry(path string, backupDir string, appUser *utils.User, defaultConnection *Connection, logger *utils.Logger) *Registry {
+	readOnly := true
+	defaultConnection.Instance.Id = DefaultId
+	defaultConnection.Instance.ReadOnly = &readOnly
+	defaultConnection.BackupDir = BackupDir(backupDir, DefaultId)
+
+	registry := &Registry{
+		path:        path,
+		backupDir:   backupDir,
+		appUser:     appUser,
+		instances:   []Instance{},
+		connections: map[string]*Connection{DefaultId: defaultConnection},
+		logger:      logger,
+	}
+	registry.load()
+	return registry
+}
+
+// Directory backups of instance @id are kept in
+func BackupDir(backupDir string, id string) string {
+	return filepath.Join(backupDir, id)
+}
+
+/*
+Creates the backup directory of the default instance and moves postgresql.auto.conf backups
+taken before instances had their own directories into it, since they were taken of it.
+*/
+func MigrateBackups(backupDir string, logger *utils.Logger) error {
+	defaultDir := BackupDir(backupDir, DefaultId)
+	if err := os.MkdirAll(defaultDir, 0755); err != nil {
+		logger.LogError(fmt.Errorf("could not create %s: %v", defaultDir, err))
+		return err
+	}
+	backups, err := filepath.Glob(backupDir + "/postgresql.auto.conf_*")
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups {
+		if err := os.Rename(backup, filepath.Join(defaultDir, filepath.Base(backup))); err != nil {
+			logger.LogError(fmt.Errorf("could not move %s to %s: %v", backup, defaultDir, err))
+			return err
+		}
+	}
+	return nil
+}
+
+func (registry *Registry) load() {
+	content, err := os.ReadFile(registry.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			registry.logger.LogError(fmt.Errorf("could not read instances from %s: %v", registry.path, err))
+		}
+		return
+	}
+	instances := []Instance{}
+	if err := json.Unmarshal(content, &instances); err != nil {
+		registry.logger.LogError(fmt.Errorf("could not parse instances in %s: %v", registry.path, err))
+		return
+	}
+	for _, instance := range instances {
+		if err := instance.validate(); err != nil {
+			registry.logger.LogWarning(fmt.Errorf("skipping instance %s from %s: %v", instance.Id, registry.path, err))
+			continue
+		}
+		registry.instances = append(registry.instances, instance)
+	}
+}
+
+// Writes registered instances to the store file. Caller has to hold the mutex.
+func (registry *Registry) save(instances []Instance) error {
+	content, err := json.MarshalIndent(instances, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(registry.path), 0755); err != nil {
+		registry.logger.LogError(fmt.Errorf("could not create directory for %s: %v", registry.path, err))
+		return err
+	}
+	temporary := registry.path + ".tmp"
+	if err := os.WriteFile(temporary, content, 0600); err != nil {
+		registry.logger.LogError(fmt.Errorf("could not save instances to %s: %v", temporary, err))
+		return err
+	}
+	if err := os.Rename(temporary, registry.path); err != nil {
+		registry.logger.LogError(fmt.Errorf("could not save instances to %s: %v", registry.path, err))
+		return err
+	}
+	registry.instances = instances
+	return nil
+}
+
+////////////////////////
+// CRUD
+
+// Lists instances, the default instance first and the rest by id
+func (registry *Registry) Instances() []Instance {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	instances := append([]Instance{}, registry.instances...)
+	sort.Slice(instances, func(i, j int) bool { return instances[i].Id < instances[j].Id })
+	return append([]Instance{registry.connections[DefaultId].Instance}, instances...)
+}
+
+// Returns instance @id
+func (registry *Registry) Instance(id string) (*Instance, error) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if id == DefaultId {
+		instance := registry.connections[DefaultId].Instance
+		return &instance, nil
+	}
+	if index := registry.find(id); index >= 0 {
+		instance := registry.instances[index]
+		return &instance, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrInstanceNotFound, id)
+}
+
+// Registers @instance
+func (registry *Registry) Create(instance Instance) (*Instance, error) {
+	instance.ReadOnly = nil
+	if err := instance.validate(); err != nil {
+		return nil, err
+	}
+	if err := instance.validateRemote(); err != nil {
+		return nil, err
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if instance.Id == DefaultId || registry.find(instance.Id) >= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInstanceExists, instance.Id)
+	}
+	if err := registry.save(append(append([]Instance{}, registry.instances...), instance)); err != nil {
+		return nil, err
+	}
+	return &instance, nil
+}
+
+// Replaces instance @id. Its connection is reopened on next use.
+func (registry *Registry) Update(id string, instance Instance) (*Instance, error) {
+	if id == DefaultId {
+		return nil, ErrReadOnlyInstance
+	}
+	instance.Id = id
+	instance.ReadOnly = nil
+	if err := instance.validate(); err != nil {
+		return nil, err
+	}
+	if err := instance.validateRemote(); err != nil {
+		return nil, err
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	index := registry.find(id)
+	if index < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInstanceNotFound, id)
+	}
+	instances := append([]Instance{}, registry.instances...)
+	instances[index] = instance
+	if err := registry.save(instances); err != nil {
+		return nil, err
+	}
+	registry.disconnect(id)
+	return &instance, nil
+}
+
+// Removes instance @id. Its backups are kept.
+func (registry *Registry) Delete(id string) error {
+	if id == DefaultId {
+		return ErrReadOnlyInstance
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	index := registry.find(id)
+	if index < 0 {
+		return fmt.Errorf("%w: %s", ErrInstanceNotFound, id)
+	}
+	instances := append(append([]Instance{}, registry.instances[:index]...), registry.instances[index+1:]...)
+	if err := registry.save(instances); err != nil {
+		return err
+	}
+	registry.disconnect(id)
+	return nil
+}
+
+// Index of registered instance @id, -1 if there's none. Caller has to hold the mutex.
+func (registry *Registry) find(id string) int {
+	for i, instance := range registry.instances {
+		if instance.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (instance *Instance) validate() error {
+	if !idPattern.MatchString(instance.Id) {
+		return fmt.Errorf("%w: id has to match %s", ErrInvalidInstance, idPattern)
+	}
+	if instance.Host == "" || strings.ContainsAny(instance.Host, " '\\") {
+		return fmt.Errorf("%w: host has to be a host name or address", ErrInvalidInstance)
+	}
+	if instance.Port < 1 || instance.Port > 65535 {
+		return fmt.Errorf("%w: port has to be between 1 and 65535", ErrInvalidInstance)
+	}
+	if instance.Username == "" || strings.ContainsAny(instance.Username, " '\\") {
+		return fmt.Errorf("%w: username is required and can't contain spaces, quotes or backslashes", ErrInvalidInstance)
+	}
+	switch instance.CredentialsSource {
+	case Pgpass:
+	case Env, File:
+		if instance.CredentialsRef == nil || *instance.CredentialsRef == "" {
+			return fmt.Errorf("%w: credentials_ref has to name the %s the password is read from", ErrInvalidInstance, instance.CredentialsSource)
+		}
+		if instance.CredentialsSource == File && !filepath.IsAbs(*instance.CredentialsRef) {
+			return fmt.Errorf("%w: credentials_ref has to be an absolute path", ErrInvalidInstance)
+		}
+	default:
+		return fmt.Errorf("%w: credentials_source has to be pgpass, env or file", ErrInvalidInstance)
+	}
+	if instance.DataDirectory != nil && *instance.DataDirectory != "" && !filepath.IsAbs(*instance.DataDirectory) {
+		return fmt.Errorf("%w: data_directory has to be an absolute path", ErrInvalidInstance)
+	}
+	if instance.ServiceController != nil {
+		switch instance.ServiceController.Controller {
+		case Systemd, PgCtlcluster, PgCtl, None:
+		default:
+			return fmt.Errorf("%w: controller has to be systemd, pg_ctlcluster, pg_ctl or none", ErrInvalidInstance)
+		}
+	}
+	return nil
+}
+
+// Instances on other hosts can't be restarted from here, so they only take the `none` controller.
+// Kept apart from `validate` since it looks the host up, which shouldn't drop stored instances.
+func (instance *Instance) validateRemote() error {
+	if instance.IsLocal() || instance.ServiceController == nil || instance.ServiceController.Controller == None {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is not this host, service_controller can only be none", ErrInvalidInstance, instance.Host)
+}
+
+// Whether the instance runs on this host: its host is a unix socket directory, localhost,
+// or resolves to a loopback address or an address of one of this host's interfaces
+func (instance *Instance) IsLocal() bool {
+	if strings.HasPrefix(instance.Host, "/") || instance.Host == "localhost" {
+		return true
+	}
+	addresses := []string{instance.Host}
+	if net.ParseIP(instance.Host) == nil {
+		resolved, err := net.LookupHost(instance.Host)
+		if err != nil {
+			return false
+		}
+		addresses = resolved
+	}
+	interfaceAddresses, _ := net.InterfaceAddrs()
+	for _, address := range addresses {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			continue
+		}
+		if ip.IsLoopback() {
+			return true
+		}
+		for _, interfaceAddress := range interfaceAddresses {
+			if network, ok := interfaceAddress.(*net.IPNet); ok && network.IP.Equal(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+////////////////////////
+// Connections
+
+// Returns connection to instance @id, opening it on first use
+func (registry *Registry) Connection(id string) (*Connection, error) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if connection, ok := registry.connections[id]; ok {
+		return connection, nil
+	}
+	index := registry.find(id)
+	if index < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInstanceNotFound, id)
+	}
+	connection, err := registry.connect(registry.instances[index])
+	if err != nil {
+		return nil, err
+	}
+	registry.connections[id] = connection
+	return connection, nil
+}
+
+// Closes connection to instance @id, if one is open. Caller has to hold the mutex.
+func (registry *Registry) disconnect(id string) {
+	if connection, ok := registry.connections[id]; ok {
+		utils.CloseDbConnection(connection.DbHandler, registry.logger)
+		delete(registry.connections, id)
+	}
+}
+
+// Opens a connection to @instance and sets up its service controller and backup directory
+func (registry *Registry) connect(instance Instance) (*Connection, error) {
+	// 1. Connect
+	password, err := registry.password(instance)
+	if err != nil {
+		return nil, err
+	}
+	database := instance.database()
+	port := strconv.Itoa(instance.Port)
+	dbInfo := &utils.DbConnectionInfo{Hostname: instance.Host, User: instance.Username, Password: password, Port: port}
+	dbHandler, err := utils.InitDbConnectionToDatabase(instance.Host, instance.Username, password, port, database, registry.logger)
+	if err != nil {
+		return nil, err
+	}
+	dbInfo.ServerVersion, _ = utils.GetServerVersion(dbHandler, registry.logger)
+
+	// 2. Operating system user, only needed for changing files of local instances
+	osUser := instance.Username
+	if instance.OsUser != nil && *instance.OsUser != "" {
+		osUser = *instance.OsUser
+	}
+	postgresUser := &utils.User{Username: osUser}
+	if postgresUser.Uid, postgresUser.Gid, err = utils.GetUserIds(osUser, registry.logger); err != nil {
+		registry.logger.LogWarning(fmt.Errorf("instance %s: no local user %s, changing its configuration files will fail", instance.Id, osUser))
+	}
+
+	// 3. Service controller. Controllers run commands on this host, so other hosts get none.
+	local := instance.IsLocal()
+	serviceConfig := utils.ServiceConfig{}
+	if instance.DataDirectory != nil {
+		serviceConfig.DataDirectory = *instance.DataDirectory
+	}
+	if controller := instance.ServiceController; controller != nil {
+		serviceConfig.Controller = string(controller.Controller)
+		serviceConfig.SystemdUnit = valueOf(controller.SystemdUnit)
+		serviceConfig.Cluster = valueOf(controller.Cluster)
+		serviceConfig.PgCtlPath = valueOf(controller.PgCtlPath)
+	}
+	if !local {
+		if err := instance.validateRemote(); err != nil {
+			utils.CloseDbConnection(dbHandler, registry.logger)
+			return nil, fmt.Errorf("instance %s: %v", instance.Id, err)
+		}
+		serviceConfig.Controller = utils.ControllerNone
+	}
+	service, err := utils.NewServiceController(serviceConfig, dbHandler, osUser, registry.logger)
+	if err != nil {
+		utils.CloseDbConnection(dbHandler, registry.logger)
+		return nil, fmt.Errorf("instance %s: %v", instance.Id, err)
+	}
+
+	// 4. Files
+	configFile, _ := utils.FindConfigFile(dbHandler, registry.logger)
+	backupDir := BackupDir(registry.backupDir, instance.Id)
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		registry.logger.LogError(fmt.Errorf("could not create backup directory %s: %v", backupDir, err))
+	}
+
+	return &Connection{
+		Instance:     instance,
+		DbHandler:    dbHandler,
+		DbInfo:       dbInfo,
+		PostgresUser: postgresUser,
+		Service:      service,
+		ConfigFile:   configFile,
+		BackupDir:    backupDir,
+		Local:        local,
+	}, nil
+}
+
+// Reads password of @instance from its credentials source
+func (registry *Registry) password(instance Instance) (string, error) {
+	switch instance.CredentialsSource {
+	case Env:
+		password, ok := os.LookupEnv(*instance.CredentialsRef)
+		if !ok {
+			return "", fmt.Errorf("instance %s: environment variable %s is not set", instance.Id, *instance.CredentialsRef)
+		}
+		return password, nil
+	case File:
+		content, err := os.ReadFile(*instance.CredentialsRef)
+		if err != nil {
+			return "", fmt.Errorf("instance %s: could not read password: %v", instance.Id, err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+	return utils.LookupPgpassPassword(registry.appUser, instance.Host, strconv.Itoa(instance.Port), instance.database(), instance.Username, registry.logger)
+}
+
+// Database connections are opened to
+func (instance *Instance) database() string {
+	if instance.Database == nil || *instance.Database == "" {
+		return "postgres"
+	}
+	return *instance.Database
+}
+
+func valueOf(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
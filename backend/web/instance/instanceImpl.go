@@ -0,0 +1,119 @@
+/*
+This is where the implementation of automatically
+generated instance route goes
+*/
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/schedule"
+	"github.com/gin-gonic/gin"
+)
+
+type InstanceImpl struct {
+	Registry  *Registry
+	Scheduler *schedule.Scheduler
+	Logger    *utils.Logger
+}
+
+// Lists registered instances
+func (impl *InstanceImpl) GetInstances(c *gin.Context) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+	c.JSON(http.StatusAccepted, impl.Registry.Instances())
+}
+
+// Returns a single instance
+func (impl *InstanceImpl) GetInstance(c *gin.Context, instanceId InstanceId) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	instance, err := impl.Registry.Instance(instanceId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, &ErrorMessage{ErrorMessage: err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, instance)
+}
+
+// Registers an instance
+func (impl *InstanceImpl) PostInstance(c *gin.Context) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	instance := Instance{}
+	if err := c.BindJSON(&instance); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, &ErrorMessage{ErrorMessage: "incorrect payload format"})
+		return
+	}
+
+	created, err := impl.Registry.Create(instance)
+	if err != nil {
+		impl.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// Replaces an instance
+func (impl *InstanceImpl) PutInstance(c *gin.Context, instanceId InstanceId) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	instance := Instance{}
+	if err := c.BindJSON(&instance); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, &ErrorMessage{ErrorMessage: "incorrect payload format"})
+		return
+	}
+
+	updated, err := impl.Registry.Update(instanceId, instance)
+	if err != nil {
+		impl.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// Removes an instance unless changes are still scheduled for it
+func (impl *InstanceImpl) DeleteInstance(c *gin.Context, instanceId InstanceId) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	pending := schedule.Pending
+	for _, change := range impl.Scheduler.Changes(&pending) {
+		if change.Instance != nil && *change.Instance == instanceId {
+			c.JSON(http.StatusConflict, &ErrorMessage{ErrorMessage: fmt.Sprintf("change %s is scheduled for instance %s, cancel it first", change.Id, instanceId)})
+			return
+		}
+	}
+
+	if err := impl.Registry.Delete(instanceId); err != nil {
+		impl.writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Responds with the status code matching a registry error
+func (impl *InstanceImpl) writeError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	message := "Could not save instances. See /var/log/postgrescrutiniser/error.log for more details"
+	switch {
+	case errors.Is(err, ErrInvalidInstance):
+		status, message = http.StatusBadRequest, err.Error()
+	case errors.Is(err, ErrInstanceNotFound):
+		status, message = http.StatusNotFound, err.Error()
+	case errors.Is(err, ErrInstanceExists), errors.Is(err, ErrReadOnlyInstance):
+		status, message = http.StatusConflict, err.Error()
+	}
+	c.JSON(status, &ErrorMessage{ErrorMessage: message})
+}
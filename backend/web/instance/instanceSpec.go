@@ -0,0 +1,124 @@
+// Package instance provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.12.4 DO NOT EDIT.
+package instance
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Base64 encoded, gzipped, json marshaled Swagger object
+var swaggerSpec = []string{
+
+	"H4sIAAAAAAAC/+xZ33PbuBH+V3bQm7kXRpQTp3Onp6a9tHXnenXjdO4hciWIXIk4kwBvsZStetS/vbPg",
+	"T5m0M51JXT/kKSOD2F3sfvvtB+ReJa4onUXLXi3uValJF8hI4ZexnrVN8CKVXyn6hEzJxlm1UBcpuC1w",
+	"htB+pSKFd7ooc1QLRVg6YmN3KlJGvi81ZypSVhey3O5ZmVRFivDXyhCmasFUYaR8kmGh63iYkWT/Pz/p",
+	"V/+av/p+9er6/iz67fnxGxUpPpRizTOJp+Px2O4N4b8ncvRX9F7vMBgjVyKxwbCKsroq+uWHxoZxfXrw",
+	"+XXn221+wYTVMerONHaVEKZo2ejcrwi342S+t3tDzhZoGfaajN7kCI5ga3IMOS6197eOUjAeCHUKW3LF",
+	"ScLjylOcu0Tncek870gcVGys8Uhx4uzWx11RZq29cQ6jk2i9qyjBccA/Z0hPRLYIS4XmJDN2B7mx2KJF",
+	"l2VuEi12oPJI3/qlXf87npU7sbSOwkf4SD5kLeREYJSCsbB+kNv1bGkvm5A8aEKwuEcCz47qHWKDcGc8",
+	"02FpJYe2KqTCdQThD3sVKfGjrifyk2rWq9QQJuzoMM7ND5o1dOsRpMiYMNaZCe49ksRktpDjlsFVfFrK",
+	"vaY4N5uukL/m8dnbeNhSk0FttMfpcGQF2EHirMWEgV0E69b6+kEgI9uZ8zy2+2fnOdQhAp2mhN5LhSpr",
+	"7sC75Aa5z8EM+pPMdMVuJniERFtwNj/ABiHJtN1hCtqmSztkFSD0rEnyd5uhlM94wZp8I4HBZW36qkc7",
+	"UGU9OLu0J2kNzRHOMnFGM0Fx//A1YshVjD6C3N0iJZLKHJmRfASp2Rn2EaxXawkd1q/Wj9LgyGfNhQ+9",
+	"/qQLBJ+5W9ui1VhG2uoHDPuhNQ1JXnlGmnLh/EqabOzlbyWSDrv9wTMWoRe7XP79xzqH2kfgbi2SdO9U",
+	"DWdLeyXxag9rsSBHOsHT0k6FJZFLTN1p3p6/edN9J+fdIanAwDpdCUbGJ7hChm3DCSludZVzB5oIbjOT",
+	"ZIKwb3kIL87IVbusRtG7y4s+uI1zOWorTqU9TYKrxFkml+d1/r4JzK1+E/cTM26GTdzs+EO/4RipNh8T",
+	"oVclUkj4oCe1Pylvm+2JKXc6mMIEbWAd8jrwPEnmU7NrfICJfr89Gfc14Te9GVhOm9x3hQ/c+xj1zeCi",
+	"sVL3qeMMKbSz72vWN37YHSaO9FigDNY3CGvrLK7bYhsPOvdO3BhqIRHw92AaN+0yOmG5WyWcN8uwR/Iy",
+	"pMRlk8y+Op+j41PotBOmbjWp14mr7reKlJxocurUX6yCjFrcjwb/aFpsjI07qyNrTSSryprTRlS9ld+d",
+	"vX3VK4YGIZ+F4+DkY6CJZ0wqMny4ktapC7JBTUjvKs76X390VGhWC/WXnz+qRtWFNg2rfRQZc1kLP2O3",
+	"bkKmdjh7d3kxg/d7pMOQ5jo0F9rqXY9U4yHFMneHoEGMbyQDiogQJEb1pCKsm0q6eGt2FWluMbPRyU1V",
+	"NsMjdIPea5MHKSO8hTrJGlFUQGVTpKVdx7o0cRuTj+8HOvm4nsGH2tit4Sy0mO3j1wmDq+fFusH+uluN",
+	"lrbrkU6kwUB2gWYI3VaVIfjPEmfN64YDZsYjWEWq6R9pltl8Ng/TqESrS6MW6k34UxRuBQED/aHl1w4n",
+	"BMePxvNJHbot0eQQgK2hwIqunnXOyj1G/Qm5A0W4e/jSWV/7fT1/Lf8IhtGGEAZyNf7FSxz3g+uJYSz8",
+	"54ZDG486dqjVRPpQw/b0jL5KEvQe2qhkz/n87L8K6qlYTq5EE/4/uhu0UBjvRRo4gkLnW0cFpkFk1hl+",
+	"tmgqi3dlPUDC9SsQCOudD3OvTet1EBRTEvVDgxU/7JQRIAS9F/2ikBl6/r1LD1/soD0EjseH193jCINn",
+	"/yO/08wIt3rYVTXi5s9W4wu717npm/llIf58/v2zRfJuwObC8M1VJwWdC2cfAO+EASWst89YoKv6supN",
+	"im0XvlwmOEbqsfFZ00OOjFNEUbg9ntDEDC7YN0O8Ht83WPJQuIYalWjTcItKMkyrHNNmYvqlHQhZsZ5C",
+	"ZdnkwJnzGAwmYifPMV3aESn9ECId0NLwZe7TdDL7T+LBy93xekQw54/rpIYNQsAvrRXPny2Sn9x0Kz43",
+	"JXyckjWOToTf0zD8yhZP6oZJofkBuSLrQU+Jzaf05Bdu09fPogNetuR8KV3/gqVvNQnhMtfJ1ERrnpvk",
+	"lhoug65Ei2l7dbR4x9Ao4AjWJl23j5Ablx6WVvKxs+1T+lbvXUXty77c5SYm2WX1Bfvj/y3N588vzasy",
+	"1fxVl38VA5Ni4OFDzdd5/9TtYPD+GOhn+PL46Voopn6frsmporx5YVzEcfe/R4vv5t/N5Z1OHa+P/xkA",
+	"U6xMZUAfAAA=",
+}
+
+// GetSwagger returns the content of the embedded swagger specification file
+// or error if failed to decode
+func decodeSpec() ([]byte, error) {
+	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error base64 decoding spec: %s", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var rawSpec = decodeSpecCached()
+
+// a naive cached of a decoded swagger spec
+func decodeSpecCached() func() ([]byte, error) {
+	data, err := decodeSpec()
+	return func() ([]byte, error) {
+		return data, err
+	}
+}
+
+// Constructs a synthetic filesystem for resolving external references when loading openapi specifications.
+func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
+	var res = make(map[string]func() ([]byte, error))
+	if len(pathToFile) > 0 {
+		res[pathToFile] = rawSpec
+	}
+
+	return res
+}
+
+// GetSwagger returns the Swagger specification corresponding to the generated code
+// in this file. The external references of Swagger specification are resolved.
+// The logic of resolving external references is tightly connected to "import-mapping" feature.
+// Externally referenced files must be embedded in the corresponding golang packages.
+// Urls can be supported but this task was out of the scope.
+func GetSwagger() (swagger *openapi3.T, err error) {
+	var resolvePath = PathToRawSpec("")
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, url *url.URL) ([]byte, error) {
+		var pathToFile = url.String()
+		pathToFile = path.Clean(pathToFile)
+		getSpec, ok := resolvePath[pathToFile]
+		if !ok {
+			err1 := fmt.Errorf("path not found: %s", pathToFile)
+			return nil, err1
+		}
+		return getSpec()
+	}
+	var specData []byte
+	specData, err = rawSpec()
+	if err != nil {
+		return
+	}
+	swagger, err = loader.LoadFromData(specData)
+	if err != nil {
+		return
+	}
+	return
+}
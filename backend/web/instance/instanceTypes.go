@@ -0,0 +1,97 @@
+// Package instance provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.12.4 DO NOT EDIT.
+package instance
+
+const (
+	BearerAuthScopes = "bearerAuth.Scopes"
+)
+
+// Defines values for InstanceCredentialsSource.
+const (
+	Env    InstanceCredentialsSource = "env"
+	File   InstanceCredentialsSource = "file"
+	Pgpass InstanceCredentialsSource = "pgpass"
+)
+
+// Defines values for ServiceControllerController.
+const (
+	None         ServiceControllerController = "none"
+	PgCtl        ServiceControllerController = "pg_ctl"
+	PgCtlcluster ServiceControllerController = "pg_ctlcluster"
+	Systemd      ServiceControllerController = "systemd"
+)
+
+// ErrorMessage defines model for ErrorMessage.
+type ErrorMessage struct {
+	ErrorMessage string `json:"error_message"`
+}
+
+// Instance defines model for instance.
+type Instance struct {
+	// CredentialsRef Environment variable or file the password is read from
+	CredentialsRef *string `json:"credentials_ref,omitempty"`
+
+	// CredentialsSource Where the password is read from: the matching line of the application user's
+	// `~/.pgpass`, the environment variable or the file named in `credentials_ref`.
+	// Passwords are never stored in the registry
+	CredentialsSource InstanceCredentialsSource `json:"credentials_source"`
+
+	// DataDirectory Data directory, detected from the server if left out
+	DataDirectory *string `json:"data_directory,omitempty"`
+
+	// Database Database to connect to, `postgres` if left out
+	Database *string `json:"database,omitempty"`
+
+	// Host Host name, address or unix socket directory. postgresql.auto.conf can only be changed and
+	// the instance restarted when this is the host PostgreScrutiniser runs on
+	Host string `json:"host"`
+
+	// Id Used in routes, lowercase letters, digits, `_` and `-`
+	Id string `json:"id"`
+
+	// Name Name shown in the interface
+	Name *string `json:"name,omitempty"`
+
+	// OsUser Operating system user PostgreSQL runs as, owner of postgresql.auto.conf.
+	// Same as `username` if left out
+	OsUser *string `json:"os_user,omitempty"`
+	Port   int     `json:"port"`
+
+	// ReadOnly Set for the default instance, which can't be changed through this API
+	ReadOnly *bool `json:"read_only,omitempty"`
+
+	// ServiceController How the instance is restarted, details left out are detected from the server. Instances on
+	// other hosts can't be restarted from here and only take `none`, which is also their default
+	ServiceController *ServiceController `json:"service_controller,omitempty"`
+
+	// Username Superuser to connect as
+	Username string `json:"username"`
+}
+
+// InstanceCredentialsSource Where the password is read from: the matching line of the application user's
+// `~/.pgpass`, the environment variable or the file named in `credentials_ref`.
+// Passwords are never stored in the registry
+type InstanceCredentialsSource string
+
+// ServiceController How the instance is restarted, details left out are detected from the server. Instances on
+// other hosts can't be restarted from here and only take `none`, which is also their default
+type ServiceController struct {
+	// Cluster pg_ctlcluster version and name
+	Cluster     *string                     `json:"cluster,omitempty"`
+	Controller  ServiceControllerController `json:"controller"`
+	PgCtlPath   *string                     `json:"pg_ctl_path,omitempty"`
+	SystemdUnit *string                     `json:"systemd_unit,omitempty"`
+}
+
+// ServiceControllerController defines model for ServiceController.Controller.
+type ServiceControllerController string
+
+// InstanceId defines model for instanceId.
+type InstanceId = string
+
+// PostInstanceJSONRequestBody defines body for PostInstance for application/json ContentType.
+type PostInstanceJSONRequestBody = Instance
+
+// PutInstanceJSONRequestBody defines body for PutInstance for application/json ContentType.
+type PutInstanceJSONRequestBody = Instance
@@ -0,0 +1,291 @@
+// Resource configuration and backup routes for every registered instance. The
+// same routes are registered under /api for the default instance and under
+// /api/instances/:instance_id, and each request is passed on to implementations
+// set up for the instance it is for.
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/file"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/instance"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/resourceConfig"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/schedule"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Implementations of one instance, kept as long as its connection is
+type instanceImpls struct {
+	connection     *instance.Connection
+	resourceConfig *resourceConfig.ResourceConfigImpl
+	file           *file.FileImpl
+
+	// Held by both implementations while they use the configuration or change postgresql.auto.conf,
+	// from handlers and scheduled changes alike, so that changes to one instance never overlap
+	mutex *sync.Mutex
+}
+
+type instanceRoutes struct {
+	registry  *instance.Registry
+	scheduler *schedule.Scheduler
+	audit     *utils.AuditLog
+	validate  *validator.Validate
+	appUser   *utils.User
+	logger    *utils.Logger
+
+	mutex sync.Mutex
+	impls map[string]*instanceImpls
+}
+
+func newInstanceRoutes(registry *instance.Registry, scheduler *schedule.Scheduler, audit *utils.AuditLog, validate *validator.Validate, appUser *utils.User, logger *utils.Logger) *instanceRoutes {
+	return &instanceRoutes{
+		registry:  registry,
+		scheduler: scheduler,
+		audit:     audit,
+		validate:  validate,
+		appUser:   appUser,
+		logger:    logger,
+		impls:     make(map[string]*instanceImpls),
+	}
+}
+
+// Returns implementations for instance @id, setting them up on first use
+func (routes *instanceRoutes) forInstance(id string) (*instanceImpls, error) {
+	connection, err := routes.registry.Connection(id)
+	if err != nil {
+		return nil, err
+	}
+
+	routes.mutex.Lock()
+	defer routes.mutex.Unlock()
+
+	// Updating an instance replaces its connection, implementations are replaced along with it
+	// The mutex outlives replaced implementations, which may still be running a change
+	mutex := &sync.Mutex{}
+	if impls, ok := routes.impls[id]; ok {
+		if impls.connection == connection {
+			return impls, nil
+		}
+		mutex = impls.mutex
+	}
+	impls := &instanceImpls{
+		connection: connection,
+		mutex:      mutex,
+		resourceConfig: &resourceConfig.ResourceConfigImpl{
+			Instance:     id,
+			ConfigFile:   connection.ConfigFile,
+			BackupDir:    connection.BackupDir,
+			Logger:       routes.logger,
+			AppUser:      routes.appUser,
+			PostgresUser: connection.PostgresUser,
+			DbHandler:    connection.DbHandler,
+			DbInfo:       connection.DbInfo,
+			Service:      connection.Service,
+			Scheduler:    routes.scheduler,
+			Audit:        routes.audit,
+			Mutex:        mutex,
+		},
+		file: &file.FileImpl{
+			Instance:         id,
+			BackupDir:        connection.BackupDir,
+			CurrentFile:      filepath.Dir(connection.ConfigFile) + "/postgresql.auto.conf",
+			PostgresUsername: connection.PostgresUser.Username,
+			AppUser:          routes.appUser,
+			Logger:           routes.logger,
+			DbHandler:        connection.DbHandler,
+			Service:          connection.Service,
+			Scheduler:        routes.scheduler,
+			Validate:         routes.validate,
+			Audit:            routes.audit,
+			Mutex:            mutex,
+		},
+	}
+	routes.impls[id] = impls
+	return impls, nil
+}
+
+// Returns implementations for the instance in the request path, the default instance if there's none.
+// Returns nil if middleware aborted the request or the instance can't be used, in which case a response was written.
+func (routes *instanceRoutes) forRequest(c *gin.Context) *instanceImpls {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return nil
+	}
+
+	id := c.Param("instance_id")
+	if id == "" {
+		id = instance.DefaultId
+	}
+	impls, err := routes.forInstance(id)
+	if errors.Is(err, instance.ErrInstanceNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error_message": err.Error()})
+		return nil
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error_message": fmt.Sprintf("Could not connect to instance %s: %v", id, err)})
+		return nil
+	}
+	return impls
+}
+
+// Refuses changes to instances on other hosts and returns true if it did. Their postgresql.auto.conf
+// and service would be looked for on this host, so changing them is refused rather than done halfway.
+func refuseRemote(c *gin.Context, impls *instanceImpls) bool {
+	if impls.connection.Local {
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{"error_message": fmt.Sprintf("Instance %s: %v", impls.connection.Instance.Id, instance.ErrRemoteInstance)})
+	return true
+}
+
+// Instance a scheduled change runs on. Changes scheduled before there were instances have none.
+func changeInstance(change *schedule.ScheduledChange) string {
+	if change.Instance == nil || *change.Instance == "" {
+		return instance.DefaultId
+	}
+	return *change.Instance
+}
+
+func (routes *instanceRoutes) runScheduledSuggestions(change *schedule.ScheduledChange) (*schedule.ChangeOutcome, error) {
+	impls, err := routes.forInstance(changeInstance(change))
+	if err != nil {
+		return nil, err
+	}
+	if !impls.connection.Local {
+		return nil, instance.ErrRemoteInstance
+	}
+	return impls.resourceConfig.RunScheduledChange(change)
+}
+
+func (routes *instanceRoutes) runScheduledRestore(change *schedule.ScheduledChange) (*schedule.ChangeOutcome, error) {
+	impls, err := routes.forInstance(changeInstance(change))
+	if err != nil {
+		return nil, err
+	}
+	if !impls.connection.Local {
+		return nil, instance.ErrRemoteInstance
+	}
+	return impls.file.RunScheduledChange(change)
+}
+
+////////////////////////
+// resourceConfig.ServerInterface
+
+type resourceConfigRoutes struct {
+	*instanceRoutes
+}
+
+func (routes resourceConfigRoutes) ExportResourceConfigs(c *gin.Context, params resourceConfig.ExportResourceConfigsParams) {
+	if impls := routes.forRequest(c); impls != nil {
+		impls.resourceConfig.ExportResourceConfigs(c, params)
+	}
+}
+
+func (routes resourceConfigRoutes) GetMemoryBudget(c *gin.Context) {
+	if impls := routes.forRequest(c); impls != nil {
+		impls.resourceConfig.GetMemoryBudget(c)
+	}
+}
+
+func (routes resourceConfigRoutes) GetPendingRestart(c *gin.Context) {
+	if impls := routes.forRequest(c); impls != nil {
+		impls.resourceConfig.GetPendingRestart(c)
+	}
+}
+
+func (routes resourceConfigRoutes) GetFileSettings(c *gin.Context, params resourceConfig.GetFileSettingsParams) {
+	if impls := routes.forRequest(c); impls != nil {
+		impls.resourceConfig.GetFileSettings(c, params)
+	}
+}
+
+func (routes resourceConfigRoutes) GetRoleSettings(c *gin.Context) {
+	if impls := routes.forRequest(c); impls != nil {
+		impls.resourceConfig.GetRoleSettings(c)
+	}
+}
+
+func (routes resourceConfigRoutes) GetWorkloadProfile(c *gin.Context) {
+	if impls := routes.forRequest(c); impls != nil {
+		impls.resourceConfig.GetWorkloadProfile(c)
+	}
+}
+
+func (routes resourceConfigRoutes) PutWorkloadProfile(c *gin.Context) {
+	if impls := routes.forRequest(c); impls != nil {
+		impls.resourceConfig.PutWorkloadProfile(c)
+	}
+}
+
+func (routes resourceConfigRoutes) GetReport(c *gin.Context, params resourceConfig.GetReportParams) {
+	if impls := routes.forRequest(c); impls != nil {
+		impls.resourceConfig.GetReport(c, params)
+	}
+}
+
+func (routes resourceConfigRoutes) DeleteResourceConfigs(c *gin.Context) {
+	if impls := routes.forRequest(c); impls != nil && !refuseRemote(c, impls) {
+		impls.resourceConfig.DeleteResourceConfigs(c)
+	}
+}
+
+func (routes resourceConfigRoutes) GetResourceConfigs(c *gin.Context, params resourceConfig.GetResourceConfigsParams) {
+	if impls := routes.forRequest(c); impls != nil {
+		impls.resourceConfig.GetResourceConfigs(c, params)
+	}
+}
+
+func (routes resourceConfigRoutes) PatchResourceConfigs(c *gin.Context, params resourceConfig.PatchResourceConfigsParams) {
+	impls := routes.forRequest(c)
+	if impls == nil {
+		return
+	}
+	// Dry runs only validate, which works over SQL
+	if (params.DryRun == nil || !*params.DryRun) && refuseRemote(c, impls) {
+		return
+	}
+	impls.resourceConfig.PatchResourceConfigs(c, params)
+}
+
+func (routes resourceConfigRoutes) GetResourceConfigById(c *gin.Context, config string, params resourceConfig.GetResourceConfigByIdParams) {
+	if impls := routes.forRequest(c); impls != nil {
+		impls.resourceConfig.GetResourceConfigById(c, config, params)
+	}
+}
+
+////////////////////////
+// file.ServerInterface
+
+type fileRoutes struct {
+	*instanceRoutes
+}
+
+func (routes fileRoutes) DeleteBackups(c *gin.Context) {
+	if impls := routes.forRequest(c); impls != nil {
+		impls.file.DeleteBackups(c)
+	}
+}
+
+func (routes fileRoutes) GetBackups(c *gin.Context) {
+	if impls := routes.forRequest(c); impls != nil {
+		impls.file.GetBackups(c)
+	}
+}
+
+func (routes fileRoutes) DeleteBackup(c *gin.Context, backupName string) {
+	if impls := routes.forRequest(c); impls != nil {
+		impls.file.DeleteBackup(c, backupName)
+	}
+}
+
+func (routes fileRoutes) PutBackup(c *gin.Context, backupName string, params file.PutBackupParams) {
+	if impls := routes.forRequest(c); impls != nil && !refuseRemote(c, impls) {
+		impls.file.PutBackup(c, backupName, params)
+	}
+}
@@ -0,0 +1,503 @@
+// Kernel parameter checks. These cover sysctl settings that affect how
+// PostgreSQL uses memory and writes to disk, and apply suggestions through
+// a drop-in file in /etc/sysctl.d/
+package kernelConfig
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	sysctl "github.com/lorenzosaino/go-sysctl"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+const (
+	dropInPath = "/etc/sysctl.d/90-postgrescrutiniser.conf"
+	thpName    = "transparent_hugepage" // not a sysctl, lives in /sys/kernel/mm/transparent_hugepage/enabled
+)
+
+// Returned when a suggestion cannot be applied, before anything has been changed
+var ErrInvalidSuggestion = errors.New("invalid suggestion")
+
+type kernelCheck struct {
+	name string
+	run  func(conf *Configuration, logger *utils.Logger) (*KernelConfig, error)
+}
+
+// Checks in the order they are run and returned. Later checks may rely on
+// suggestions made by earlier ones (overcommit_ratio on overcommit_memory)
+var kernelChecks = []kernelCheck{
+	{name: "vm.overcommit_memory", run: (*Configuration).CheckOvercommitMemory},
+	{name: "vm.overcommit_ratio", run: (*Configuration).CheckOvercommitRatio},
+	{name: "vm.swappiness", run: (*Configuration).CheckSwappiness},
+	{name: "vm.dirty_background_bytes", run: (*Configuration).CheckDirtyBackgroundBytes},
+	{name: "vm.dirty_bytes", run: (*Configuration).CheckDirtyBytes},
+	{name: "kernel.shmmax", run: (*Configuration).CheckShmmax},
+	{name: "kernel.shmall", run: (*Configuration).CheckShmall},
+	{name: thpName, run: (*Configuration).CheckTransparentHugePages},
+}
+
+type Configuration struct {
+	dbHandler  *sql.DB
+	dropInPath string // sysctl.d drop-in suggestions are written to
+	backupDir  string // directory to where the drop-in will be backed up
+	appUser    *utils.User
+	settings   map[string]KernelConfig // results of the last check run
+}
+
+func InitChecks(dbHandler *sql.DB, backupDir string, appUser *utils.User) *Configuration {
+	return &Configuration{
+		dbHandler:  dbHandler,
+		dropInPath: dropInPath,
+		backupDir:  backupDir,
+		appUser:    appUser,
+		settings:   make(map[string]KernelConfig),
+	}
+}
+
+// Runs every kernel check and returns their results in check order
+func RunChecks(conf *Configuration, logger *utils.Logger) []KernelConfig {
+	conf.settings = make(map[string]KernelConfig)
+	results := []KernelConfig{}
+	for _, check := range kernelChecks {
+		results = append(results, conf.runCheck(check, logger))
+	}
+	return results
+}
+
+// Runs a single check by name. Checks it relies on are run first.
+func RunCheck(conf *Configuration, name string, logger *utils.Logger) (*KernelConfig, error) {
+	for _, check := range kernelChecks {
+		if check.name != name {
+			continue
+		}
+		if name == "vm.overcommit_ratio" {
+			conf.runCheck(kernelChecks[0], logger)
+		}
+		result := conf.runCheck(check, logger)
+		if result.GotError {
+			return nil, fmt.Errorf("%s check failed", name)
+		}
+		return &result, nil
+	}
+	return nil, fmt.Errorf("no kernel parameter check with name: %s", name)
+}
+
+func (conf *Configuration) runCheck(check kernelCheck, logger *utils.Logger) KernelConfig {
+	result, err := check.run(conf, logger)
+	if err != nil {
+		result = &KernelConfig{Name: check.name, GotError: true, Applicable: check.name != thpName}
+	}
+	conf.settings[check.name] = *result
+	return *result
+}
+
+// Reads kernel parameter and prepares a result for it
+func newKernelConfig(name string) (*KernelConfig, error) {
+	value, err := sysctl.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return &KernelConfig{Name: name, Value: strings.TrimSpace(value), Applicable: true}, nil
+}
+
+// Clears suggestion if it's the same as the current value
+func resetSuggestionIfEqual(setting *KernelConfig) {
+	if setting.SuggestedValue == setting.Value {
+		setting.SuggestedValue = ""
+	}
+}
+
+// Returns what a parameter will be after its suggestion is applied
+func suggestedOrCurrent(setting KernelConfig) string {
+	if setting.SuggestedValue != "" {
+		return setting.SuggestedValue
+	}
+	return setting.Value
+}
+
+////////////////////////////////////////////////////////////////////
+// Below are the checks themselves
+////////////////////////////////////////////////////////////////////
+
+func (conf *Configuration) CheckOvercommitMemory(logger *utils.Logger) (*KernelConfig, error) {
+	overcommitMemory, err := newKernelConfig("vm.overcommit_memory")
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed vm.overcommit_memory check: %v", err))
+		return nil, err
+	}
+	overcommitMemory.Details = "This parameter controls whether the kernel allows allocating more memory than is actually available. "
+
+	if overcommitMemory.Value != "2" {
+		overcommitMemory.SuggestedValue = "2"
+		overcommitMemory.Details += "With overcommit allowed, running out of memory is handled by the OOM killer, which may kill the postmaster or a backend holding shared memory and force PostgreSQL to restart. With strict overcommit (2) allocations fail instead and only the query that made them is cancelled. Suggestion is to set this to 2 together with an appropriate vm.overcommit_ratio."
+	}
+
+	resetSuggestionIfEqual(overcommitMemory)
+	return overcommitMemory, nil
+}
+
+// Commit limit with strict overcommit is swap + (RAM - huge pages) * overcommit_ratio / 100.
+// The ratio is chosen so that the commit limit is close to the RAM outside of huge pages.
+func (conf *Configuration) CheckOvercommitRatio(logger *utils.Logger) (*KernelConfig, error) {
+	overcommitRatio, err := newKernelConfig("vm.overcommit_ratio")
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed vm.overcommit_ratio check: %v", err))
+		return nil, err
+	}
+	overcommitRatio.Details = "This parameter sets the percentage of RAM that can be allocated, on top of swap, when strict overcommit (vm.overcommit_memory = 2) is used. "
+
+	if suggestedOrCurrent(conf.settings["vm.overcommit_memory"]) != "2" {
+		overcommitRatio.Details += "It has no effect unless vm.overcommit_memory is set to 2."
+		return overcommitRatio, nil
+	}
+
+	totalMemory, err := utils.GetTotalMemory()
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed vm.overcommit_ratio check: %v", err))
+		return nil, err
+	}
+	totalSwap, err := utils.GetTotalSwap()
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed vm.overcommit_ratio check: %v", err))
+		return nil, err
+	}
+	// Memory reserved for huge pages can't be committed, the kernel leaves it out of the ratio
+	hugePages, err := utils.GetReservedHugePagesSize()
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed vm.overcommit_ratio check: %v", err))
+		return nil, err
+	}
+	var usableMemory uint64
+	if hugePages < totalMemory {
+		usableMemory = totalMemory - hugePages
+	}
+
+	// Never go below the default of 50, otherwise memory that exists could not be used
+	ratio := 50.0
+	if usableMemory > 0 && totalSwap < usableMemory {
+		ratio = math.Round(float64(usableMemory-totalSwap) / float64(usableMemory) * 100)
+		if ratio < 50 {
+			ratio = 50
+		}
+	}
+	overcommitRatio.SuggestedValue = fmt.Sprint(ratio)
+	overcommitRatio.Details += fmt.Sprintf("Server has %.0fMB of RAM, %.0fMB of which is reserved for huge pages, and %.0fMB of swap. Huge pages are excluded from the commit limit (swap + (RAM - huge pages) * ratio / 100). Suggestion is to pick a ratio that makes the commit limit about equal to RAM outside of huge pages, so that memory can be allocated without the server having to swap heavily. Memory used through huge pages, such as shared_buffers with huge_pages on, doesn't count against it. If vm.nr_hugepages is changed, run this check again.", float64(totalMemory)/1024/1024, float64(hugePages)/1024/1024, float64(totalSwap)/1024/1024)
+
+	resetSuggestionIfEqual(overcommitRatio)
+	return overcommitRatio, nil
+}
+
+func (conf *Configuration) CheckSwappiness(logger *utils.Logger) (*KernelConfig, error) {
+	swappiness, err := newKernelConfig("vm.swappiness")
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed vm.swappiness check: %v", err))
+		return nil, err
+	}
+	swappiness.Details = "This parameter controls how eagerly the kernel swaps out process memory in favour of keeping file pages cached. "
+
+	value, err := utils.StringToInt(swappiness.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed vm.swappiness check: %v", err))
+		return nil, err
+	}
+	if value > 10 {
+		swappiness.SuggestedValue = "1"
+		swappiness.Details += "Backends that get swapped out stall queries far more than a smaller page cache does. Suggestion is to set this to 1, which avoids swapping unless the server is about to run out of memory, while still keeping swap usable as a last resort."
+	}
+
+	resetSuggestionIfEqual(swappiness)
+	return swappiness, nil
+}
+
+// Returns how many bytes of dirty pages are worth keeping before writeback starts.
+// Storage that can absorb writes faster can have more outstanding.
+func (conf *Configuration) dirtyBackgroundTarget(logger *utils.Logger) (uint64, string) {
+	dataDirectory, err := utils.FindDataDirectory(conf.dbHandler, logger)
+	if err == nil {
+		if storage, err := utils.GetStorageInfo(dataDirectory); err == nil && storage.Type == utils.StorageSSD {
+			return 256 * 1024 * 1024, fmt.Sprintf("data directory is on SSD (%s)", storage.Device)
+		}
+	}
+	return 64 * 1024 * 1024, "data directory is on rotational or unknown storage"
+}
+
+/*
+Suggests a fixed number of bytes for the threshold that is used instead of a percentage of memory.
+@bytesName - name of the bytes parameter being checked
+@ratioName - name of the percentage parameter it replaces
+@target - suggested threshold in bytes
+*/
+func (conf *Configuration) checkDirtyThreshold(bytesName string, ratioName string, target uint64, reason string, logger *utils.Logger) (*KernelConfig, error) {
+	setting, err := newKernelConfig(bytesName)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed %s check: %v", bytesName, err))
+		return nil, err
+	}
+	current, err := utils.StringToUint64(setting.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed %s check: %v", bytesName, err))
+		return nil, err
+	}
+
+	// 0 means the ratio parameter is used instead, work out how much memory that is
+	if current == 0 {
+		ratioValue, err := sysctl.Get(ratioName)
+		if err != nil {
+			logger.LogError(fmt.Errorf("Failed %s check: %v", bytesName, err))
+			return nil, err
+		}
+		ratio, err := utils.StringToUint64(strings.TrimSpace(ratioValue))
+		if err != nil {
+			logger.LogError(fmt.Errorf("Failed %s check: %v", bytesName, err))
+			return nil, err
+		}
+		totalMemory, err := utils.GetTotalMemory()
+		if err != nil {
+			logger.LogError(fmt.Errorf("Failed %s check: %v", bytesName, err))
+			return nil, err
+		}
+		current = totalMemory * ratio / 100
+		setting.Details += fmt.Sprintf("It is currently 0, so %s (%d%%) is used instead, which is %.0fMB on this server. ", ratioName, ratio, float64(current)/1024/1024)
+	}
+
+	// Only suggest a change when the threshold is far off the target
+	if current > target*2 || current < target/2 {
+		setting.SuggestedValue = fmt.Sprint(target)
+		setting.Details += fmt.Sprintf("Large amounts of dirty pages get flushed in bursts that stall checkpoints and queries, while very small amounts cause needless writes. Since the %s, suggestion is to set this to %.0fMB. Setting it makes the kernel ignore %s.", reason, float64(target)/1024/1024, ratioName)
+	}
+
+	resetSuggestionIfEqual(setting)
+	return setting, nil
+}
+
+func (conf *Configuration) CheckDirtyBackgroundBytes(logger *utils.Logger) (*KernelConfig, error) {
+	target, reason := conf.dirtyBackgroundTarget(logger)
+	setting, err := conf.checkDirtyThreshold("vm.dirty_background_bytes", "vm.dirty_background_ratio", target, reason, logger)
+	if err != nil {
+		return nil, err
+	}
+	setting.Details = "This parameter sets the amount of dirty memory at which background writeback starts. " + setting.Details
+	return setting, nil
+}
+
+// Processes writing dirty pages get blocked at this threshold, so it's
+// kept at 4 times the background threshold to leave room for bursts
+func (conf *Configuration) CheckDirtyBytes(logger *utils.Logger) (*KernelConfig, error) {
+	target, reason := conf.dirtyBackgroundTarget(logger)
+	setting, err := conf.checkDirtyThreshold("vm.dirty_bytes", "vm.dirty_ratio", target*4, reason, logger)
+	if err != nil {
+		return nil, err
+	}
+	setting.Details = "This parameter sets the amount of dirty memory at which processes writing data are made to write it out themselves. " + setting.Details
+	return setting, nil
+}
+
+// Returns bytes of System V shared memory PostgreSQL needs. Since PostgreSQL 9.3
+// the main shared memory area is only allocated as System V shared memory
+// when shared_memory_type is sysv, otherwise only a small segment is used.
+func (conf *Configuration) sysvSharedMemory(logger *utils.Logger) (uint64, string, error) {
+	rows, err := conf.dbHandler.Query("SELECT name, setting, coalesce(unit, '') FROM pg_settings WHERE name IN ('shared_buffers', 'shared_memory_size', 'shared_memory_type')")
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed querying Postgres: %v", err))
+		return 0, "", err
+	}
+	defer rows.Close()
+
+	settings := make(map[string][2]string)
+	for rows.Next() {
+		var name, setting, unit string
+		if err := rows.Scan(&name, &setting, &unit); err != nil {
+			logger.LogError(fmt.Errorf("Failed scanning row: %v", err))
+			return 0, "", err
+		}
+		settings[name] = [2]string{setting, unit}
+	}
+
+	if settings["shared_memory_type"][0] != "sysv" {
+		return 0, "shared_memory_type is not sysv, so PostgreSQL only needs a small System V shared memory segment", nil
+	}
+
+	// shared_memory_size only exists on PostgreSQL 15 and later
+	if size, ok := settings["shared_memory_size"]; ok {
+		bytes, err := utils.ConvertBasedOnUnit(size[0], size[1], "B")
+		return uint64(bytes), "shared_memory_type is sysv and shared_memory_size is " + size[0] + size[1], err
+	}
+	sharedBuffers := settings["shared_buffers"]
+	bytes, err := utils.ConvertBasedOnUnit(sharedBuffers[0], sharedBuffers[1], "B")
+	return uint64(float64(bytes) * 1.05), "shared_memory_type is sysv, so the whole shared memory area (shared_buffers plus about 5%) is System V shared memory", err
+}
+
+func (conf *Configuration) CheckShmmax(logger *utils.Logger) (*KernelConfig, error) {
+	shmmax, err := newKernelConfig("kernel.shmmax")
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed kernel.shmmax check: %v", err))
+		return nil, err
+	}
+	shmmax.Details = "This parameter sets the largest System V shared memory segment that can be created. "
+
+	needed, reason, err := conf.sysvSharedMemory(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed kernel.shmmax check: %v", err))
+		return nil, err
+	}
+	current, err := utils.StringToUint64(shmmax.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed kernel.shmmax check: %v", err))
+		return nil, err
+	}
+
+	shmmax.Details += reason + ". "
+	if current < needed {
+		shmmax.SuggestedValue = fmt.Sprint(needed)
+		shmmax.Details += "Current limit is lower than that and PostgreSQL will fail to start. Suggestion is to raise it to the size of the shared memory area."
+	}
+
+	resetSuggestionIfEqual(shmmax)
+	return shmmax, nil
+}
+
+func (conf *Configuration) CheckShmall(logger *utils.Logger) (*KernelConfig, error) {
+	shmall, err := newKernelConfig("kernel.shmall")
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed kernel.shmall check: %v", err))
+		return nil, err
+	}
+	shmall.Details = "This parameter sets the total amount of System V shared memory, in pages, that can be used at once. "
+
+	needed, reason, err := conf.sysvSharedMemory(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed kernel.shmall check: %v", err))
+		return nil, err
+	}
+	current, err := utils.StringToUint64(shmall.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed kernel.shmall check: %v", err))
+		return nil, err
+	}
+
+	pageSize := uint64(os.Getpagesize())
+	neededPages := (needed + pageSize - 1) / pageSize
+	shmall.Details += reason + ". "
+	if current < neededPages {
+		shmall.SuggestedValue = fmt.Sprint(neededPages)
+		shmall.Details += fmt.Sprintf("Current limit is lower than that and PostgreSQL will fail to start. Suggestion is to raise it to the size of the shared memory area in %d byte pages.", pageSize)
+	}
+
+	resetSuggestionIfEqual(shmall)
+	return shmall, nil
+}
+
+func (conf *Configuration) CheckTransparentHugePages(logger *utils.Logger) (*KernelConfig, error) {
+	mode, err := utils.GetTransparentHugePages()
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed transparent_hugepage check: %v", err))
+		return nil, err
+	}
+	thp := &KernelConfig{Name: thpName, Value: mode, Applicable: false}
+	thp.Details = "This setting controls whether the kernel backs process memory with huge pages on its own. It is not a sysctl, so it cannot be applied through /etc/sysctl.d/. "
+
+	if mode == "always" {
+		thp.SuggestedValue = "never"
+		thp.Details += "Background compaction done to build transparent huge pages is known to cause latency spikes and memory bloat for PostgreSQL. Suggestion is to disable it by adding transparent_hugepage=never to the kernel command line, and to use huge pages reserved through vm.nr_hugepages instead."
+	}
+
+	resetSuggestionIfEqual(thp)
+	return thp, nil
+}
+
+////////////////////////////////////////////////////////////////////
+// Below are functions used to apply suggestions
+////////////////////////////////////////////////////////////////////
+
+// Reads `key = value` lines from the drop-in, if it exists
+func readDropIn(path string) (map[string]string, error) {
+	values := make(map[string]string)
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		fields := strings.SplitN(line, "=", 2)
+		if len(fields) == 2 {
+			values[strings.TrimSpace(fields[0])] = strings.TrimSpace(fields[1])
+		}
+	}
+	return values, nil
+}
+
+// Writes suggestions to the sysctl.d drop-in and loads it. Values already in the
+// drop-in are kept unless overwritten. Existing drop-in is backed up first.
+func (conf *Configuration) ApplySuggestions(suggestions *PatchKernelConfigsJSONBody, logger *utils.Logger) error {
+	// 1. Validate suggestions before touching anything
+	for _, suggestion := range *suggestions {
+		known := false
+		for _, check := range kernelChecks {
+			known = known || check.name == suggestion.Name
+		}
+		if !known {
+			return fmt.Errorf("%w: no kernel parameter check with name: %s", ErrInvalidSuggestion, suggestion.Name)
+		}
+		if suggestion.Name == thpName {
+			return fmt.Errorf("%w: %s cannot be applied through sysctl, set it on the kernel command line instead", ErrInvalidSuggestion, thpName)
+		}
+		if _, err := utils.StringToUint64(suggestion.SuggestedValue); err != nil {
+			return fmt.Errorf("%w: value for %s is not a number: %s", ErrInvalidSuggestion, suggestion.Name, suggestion.SuggestedValue)
+		}
+	}
+
+	// 2. Merge with the current drop-in
+	values, err := readDropIn(conf.dropInPath)
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed reading %s: %v", conf.dropInPath, err))
+		return err
+	}
+	if len(values) > 0 {
+		if _, err := utils.BackupFile(conf.dropInPath, conf.backupDir, conf.appUser, logger); err != nil {
+			return fmt.Errorf("failed to backup %s", conf.dropInPath)
+		}
+	}
+	for _, suggestion := range *suggestions {
+		values[suggestion.Name] = suggestion.SuggestedValue
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	content := "# Managed by postgrescrutiniser. Previous versions are kept in " + conf.backupDir + "\n"
+	for _, name := range names {
+		content += fmt.Sprintf("%s = %s\n", name, values[name])
+	}
+
+	// 3. Write the drop-in as root and load it
+	cmd := exec.Command("sudo", "tee", conf.dropInPath)
+	cmd.Stdin = strings.NewReader(content)
+	if err := cmd.Run(); err != nil {
+		logger.LogError(fmt.Errorf("failed writing %s: %v", conf.dropInPath, err))
+		return fmt.Errorf("failed writing %s", filepath.Base(conf.dropInPath))
+	}
+	cmd = exec.Command("sudo", "sysctl", "-p", conf.dropInPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		logger.LogError(fmt.Errorf("failed loading %s: %v: %s", conf.dropInPath, err, output))
+		return fmt.Errorf("failed loading %s", filepath.Base(conf.dropInPath))
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+// Package kernelConfig provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.12.4 DO NOT EDIT.
+package kernelConfig
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/deepmap/oapi-codegen/pkg/runtime"
+	"github.com/gin-gonic/gin"
+)
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+
+	// (GET /kernel)
+	GetKernelConfigs(c *gin.Context)
+
+	// (PATCH /kernel)
+	PatchKernelConfigs(c *gin.Context)
+
+	// (GET /kernel/{parameter})
+	GetKernelConfigById(c *gin.Context, parameter GetKernelConfigByIdParamsParameter)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandler       func(*gin.Context, error, int)
+}
+
+type MiddlewareFunc func(c *gin.Context)
+
+// GetKernelConfigs operation middleware
+func (siw *ServerInterfaceWrapper) GetKernelConfigs(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetKernelConfigs(c)
+}
+
+// PatchKernelConfigs operation middleware
+func (siw *ServerInterfaceWrapper) PatchKernelConfigs(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.PatchKernelConfigs(c)
+}
+
+// GetKernelConfigById operation middleware
+func (siw *ServerInterfaceWrapper) GetKernelConfigById(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "parameter" -------------
+	var parameter GetKernelConfigByIdParamsParameter
+
+	err = runtime.BindStyledParameter("simple", false, "parameter", c.Param("parameter"), &parameter)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter parameter: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetKernelConfigById(c, parameter)
+}
+
+// GinServerOptions provides options for the Gin server.
+type GinServerOptions struct {
+	BaseURL      string
+	Middlewares  []MiddlewareFunc
+	ErrorHandler func(*gin.Context, error, int)
+}
+
+// RegisterHandlers creates http.Handler with routing matching OpenAPI spec.
+func RegisterHandlers(router *gin.Engine, si ServerInterface) *gin.Engine {
+	return RegisterHandlersWithOptions(router, si, GinServerOptions{})
+}
+
+// RegisterHandlersWithOptions creates http.Handler with additional options
+func RegisterHandlersWithOptions(router *gin.Engine, si ServerInterface, options GinServerOptions) *gin.Engine {
+
+	errorHandler := options.ErrorHandler
+
+	if errorHandler == nil {
+		errorHandler = func(c *gin.Context, err error, statusCode int) {
+			c.JSON(statusCode, gin.H{"msg": err.Error()})
+		}
+	}
+
+	wrapper := ServerInterfaceWrapper{
+		Handler:            si,
+		HandlerMiddlewares: options.Middlewares,
+		ErrorHandler:       errorHandler,
+	}
+
+	router.GET(options.BaseURL+"/kernel", wrapper.GetKernelConfigs)
+
+	router.PATCH(options.BaseURL+"/kernel", wrapper.PatchKernelConfigs)
+
+	router.GET(options.BaseURL+"/kernel/:parameter", wrapper.GetKernelConfigById)
+
+	return router
+}
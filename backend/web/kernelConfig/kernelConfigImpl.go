@@ -0,0 +1,107 @@
+/*
+This is where the implementation of automatically
+generated kernel parameter route goes
+*/
+package kernelConfig
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+/*
+After `oapi-codegen` was used to generate API endpoints,
+`ServerInterface` can be used to add our actual implementation
+*/
+type KernelConfigImpl struct {
+	BackupDir     string
+	AppUser       *utils.User
+	Logger        *utils.Logger
+	DbHandler     *sql.DB
+	Configuration *Configuration
+}
+
+func (impl *KernelConfigImpl) GetKernelConfigs(c *gin.Context) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	// Reuse the same reference that contains kernel parameter details
+	if impl.Configuration == nil {
+		impl.Configuration = InitChecks(impl.DbHandler, impl.BackupDir, impl.AppUser)
+	}
+
+	c.JSON(http.StatusAccepted, RunChecks(impl.Configuration, impl.Logger))
+}
+
+func (impl *KernelConfigImpl) GetKernelConfigById(c *gin.Context, parameter GetKernelConfigByIdParamsParameter) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	// Reuse the same reference that contains kernel parameter details
+	if impl.Configuration == nil {
+		impl.Configuration = InitChecks(impl.DbHandler, impl.BackupDir, impl.AppUser)
+	}
+
+	configData, err := RunCheck(impl.Configuration, string(parameter), impl.Logger)
+	if err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: "Could not get suggestion. See /var/log/postgrescrutiniser/error.log for more details",
+		}
+		c.JSON(http.StatusInternalServerError, errorMsg)
+		return
+	}
+	c.JSON(http.StatusAccepted, configData)
+}
+
+// Accepts a request body containing an array of suggestions
+func (impl *KernelConfigImpl) PatchKernelConfigs(c *gin.Context) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	// Reuse the same reference that contains kernel parameter details
+	if impl.Configuration == nil {
+		impl.Configuration = InitChecks(impl.DbHandler, impl.BackupDir, impl.AppUser)
+	}
+
+	// Bind post body and validate
+	suggestions := PatchKernelConfigsJSONBody{}
+	if err := c.BindJSON(&suggestions); err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: "incorrect payload format",
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorMsg)
+		return
+	}
+	if len(suggestions) == 0 {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: "empty payload array",
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	err := impl.Configuration.ApplySuggestions(&suggestions, impl.Logger)
+	if errors.Is(err, ErrInvalidSuggestion) {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: err.Error(),
+		}
+		c.JSON(http.StatusBadRequest, errorMsg)
+		return
+	}
+	if err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: fmt.Sprintf("%s. See /var/log/postgrescrutiniser/error.log for more details", err.Error()),
+		}
+		c.JSON(http.StatusInternalServerError, errorMsg)
+		return
+	}
+	c.Status(http.StatusCreated)
+}
@@ -0,0 +1,113 @@
+// Package kernelConfig provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.12.4 DO NOT EDIT.
+package kernelConfig
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Base64 encoded, gzipped, json marshaled Swagger object
+var swaggerSpec = []string{
+
+	"H4sIAAAAAAAC/+xX3W7bRhN9lcF+3yUryekPAt45aVq4QQujCZoLRxBW5IjceLnLzAwlEwbfvdglJVE/",
+	"rh0UNVogVyK5O7Nnzs6cGd2rzFe1d+iEVXqvOCux0vHxDZGnX5FZFxjea/I1khiMqxhWF9V+WdoaVapY",
+	"yLhCdV2iCD83hjBX6c3R9nmy3e6XnzAT1SXqFsmhfe3dyhTxgDtd1Ta61nVtTaaX4U2owUTlKNpYVql6",
+	"XxqGWpOuUJAg807IW4bSbwB1gWRbkBKhdw+80TWDbwRq8hkyQ4WVp3YCKlGFl0UEqtKVtoyJcroKKNfV",
+	"JBjWxiGzShQ3RYEsmC/W2jZhx4VK1Pb5h1mI55CucQj3KkfOyNRivFOp+lCilEgR5uDZeAeZdrBEiJaY",
+	"g5Tkm6IEDdxyJnaSQ06+/sY4tWNz6b1F7VQ3Yuj4tB/7BTBu5akyroBN2YKGiB42mmEXnUqOb/WAo2PP",
+	"XGNmVgYZNkNEWYnZLRReQDvorc5h7Vk+dvebrhD8anx7u3s+h+zkUo4d/hEjlFILbIy1gdydDehVSB9q",
+	"nAuUROATeFPV0oJZgfOQldoVCOYRfh44+3VDhE4Glp8c1VEVRaK2Z5xGvL/1ZJxw40t7rPKutWTluygC",
+	"B0V4c/8ltdAl4+1+jZT5qjKy6IvtrNUL1c2Pq+Z5E8MDo5z1CuKfejfHh50SHhBh1pCRNhLdh7pETUiX",
+	"jZT7t588VVpUqn758D64jrtVOqzuEZUiteqC41DUp/G97cO53obDcHl9FcyNhLtV156lIHyXUSPGGY6+",
+	"10jcm19MZpMoab5Gp2ujUvVt/JSoWksZ4U97ysJjgXIK4XeUhhyDtvaU3V4mCLmxwh+diieRDqZXuUrV",
+	"zyhvRznKKjDPtXfcc/di9iL8BO1HJyO5DR6mn9i7fWsLT0awiob/J1ypVP1vum+C034bTw/6UbfjWhPp",
+	"tif7SP2aLPaTLbJg893s4ouA/RWeg3Z85vz3/hYdVIY5CJgnqLQNCo95QPL9bPZsSN4hrZGATY6D6Mct",
+	"K91YeTYQjcO7GrMg7QOGcIe64FCrQ7LOu5jBWXmar5cBGDJ4h5FLT+PezLBsYUNGAtVSYhXEQ2/7MayM",
+	"RTAOpijZdNutpx+ddjlYr/NgZWQClw7wznD0srU1DEud3WIOTQ0rQyxnCiKq9GlJfG6Q5ZXP23+2GsY9",
+	"4mxh7GVRqMHupFovHpSoei9R27lnqKtVY23b19TzZfKVW2trchiohaXPBwxf6/pfXdddsu1I0/tdTnWP",
+	"dycYZtjsgSb1WGt61V7lsS0OVhwnp8PD3DC+nBkyIKBL9lPXybBlgoPQdNXuv8l49DksvGREObqmCgw9",
+	"MI4dfo7xqeTk9HU1yQ1JuwgKVZBvXL5YtoKHa8OHPrwJl1Wl7w7etbUqUULaca0JnSzKpsD68I/hbsqa",
+	"/81m//Qe/9Se/vz6s7/jr+LzHxCf0YQf638829/MQ0ZzjKZXh4bsMMOn06n1mbalZ0lfzl7OpmHa7ubd",
+	"nwMAOIP3PKYRAAA=",
+}
+
+// GetSwagger returns the content of the embedded swagger specification file
+// or error if failed to decode
+func decodeSpec() ([]byte, error) {
+	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error base64 decoding spec: %s", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var rawSpec = decodeSpecCached()
+
+// a naive cached of a decoded swagger spec
+func decodeSpecCached() func() ([]byte, error) {
+	data, err := decodeSpec()
+	return func() ([]byte, error) {
+		return data, err
+	}
+}
+
+// Constructs a synthetic filesystem for resolving external references when loading openapi specifications.
+func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
+	var res = make(map[string]func() ([]byte, error))
+	if len(pathToFile) > 0 {
+		res[pathToFile] = rawSpec
+	}
+
+	return res
+}
+
+// GetSwagger returns the Swagger specification corresponding to the generated code
+// in this file. The external references of Swagger specification are resolved.
+// The logic of resolving external references is tightly connected to "import-mapping" feature.
+// Externally referenced files must be embedded in the corresponding golang packages.
+// Urls can be supported but this task was out of the scope.
+func GetSwagger() (swagger *openapi3.T, err error) {
+	var resolvePath = PathToRawSpec("")
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, url *url.URL) ([]byte, error) {
+		var pathToFile = url.String()
+		pathToFile = path.Clean(pathToFile)
+		getSpec, ok := resolvePath[pathToFile]
+		if !ok {
+			err1 := fmt.Errorf("path not found: %s", pathToFile)
+			return nil, err1
+		}
+		return getSpec()
+	}
+	var specData []byte
+	specData, err = rawSpec()
+	if err != nil {
+		return
+	}
+	swagger, err = loader.LoadFromData(specData)
+	if err != nil {
+		return
+	}
+	return
+}
@@ -0,0 +1,64 @@
+// Package kernelConfig provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.12.4 DO NOT EDIT.
+package kernelConfig
+
+const (
+	BearerAuthScopes = "bearerAuth.Scopes"
+)
+
+// Defines values for GetKernelConfigByIdParamsParameter.
+const (
+	KernelShmall           GetKernelConfigByIdParamsParameter = "kernel.shmall"
+	KernelShmmax           GetKernelConfigByIdParamsParameter = "kernel.shmmax"
+	TransparentHugepage    GetKernelConfigByIdParamsParameter = "transparent_hugepage"
+	VmDirtyBackgroundBytes GetKernelConfigByIdParamsParameter = "vm.dirty_background_bytes"
+	VmDirtyBytes           GetKernelConfigByIdParamsParameter = "vm.dirty_bytes"
+	VmOvercommitMemory     GetKernelConfigByIdParamsParameter = "vm.overcommit_memory"
+	VmOvercommitRatio      GetKernelConfigByIdParamsParameter = "vm.overcommit_ratio"
+	VmSwappiness           GetKernelConfigByIdParamsParameter = "vm.swappiness"
+)
+
+// ErrorMessage defines model for ErrorMessage.
+type ErrorMessage struct {
+	ErrorMessage string `json:"error_message"`
+}
+
+// KernelConfig defines model for kernelConfig.
+type KernelConfig struct {
+	// Applicable Whether the suggestion can be applied through a sysctl.d drop-in
+	Applicable bool `json:"applicable"`
+
+	// Details Details informing why a value was suggested
+	Details string `json:"details"`
+
+	// GotError specifies whether check got an error
+	GotError bool `json:"got_error"`
+
+	// Name Name of the kernel parameter
+	Name string `json:"name"`
+
+	// SuggestedValue Value that will be suggested after running check. Empty if no change is suggested
+	SuggestedValue string `json:"suggested_value"`
+
+	// Value Current value of the kernel parameter
+	Value string `json:"value"`
+}
+
+// KernelConfigPatchSchema defines model for kernelConfigPatchSchema.
+type KernelConfigPatchSchema struct {
+	// Name Name of the kernel parameter
+	Name string `json:"name"`
+
+	// SuggestedValue Value to set the kernel parameter to
+	SuggestedValue string `json:"suggested_value"`
+}
+
+// PatchKernelConfigsJSONBody defines parameters for PatchKernelConfigs.
+type PatchKernelConfigsJSONBody = []KernelConfigPatchSchema
+
+// GetKernelConfigByIdParamsParameter defines parameters for GetKernelConfigById.
+type GetKernelConfigByIdParamsParameter string
+
+// PatchKernelConfigsJSONRequestBody defines body for PatchKernelConfigs for application/json ContentType.
+type PatchKernelConfigsJSONRequestBody = PatchKernelConfigsJSONBody
@@ -6,12 +6,15 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
-	"path/filepath"
 
 	"github.com/Globys031/PostgreScrutiniser/backend/utils"
 	"github.com/Globys031/PostgreScrutiniser/backend/web/auth"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/compare"
 	"github.com/Globys031/PostgreScrutiniser/backend/web/file"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/instance"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/kernelConfig"
 	"github.com/Globys031/PostgreScrutiniser/backend/web/resourceConfig"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/schedule"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -20,7 +23,7 @@ import (
 )
 
 // func RegisterRoutes(svc *AuthService) *gin.Engine {
-func RegisterRoutes(jwt *auth.JwtWrapper, dbHandler *sql.DB, appUser *utils.User, postgresUser *utils.User, backupDir string, logger *utils.Logger) *gin.Engine {
+func RegisterRoutes(jwt *auth.JwtWrapper, registry *instance.Registry, appUser *utils.User, backupDir string, logger *utils.Logger) *gin.Engine {
 	////////////////////////
 	// Route configurations
 	router := gin.Default()
@@ -33,16 +36,30 @@ func RegisterRoutes(jwt *auth.JwtWrapper, dbHandler *sql.DB, appUser *utils.User
 	////////////////////////
 
 	validate := registerCustomValidators()
-	configFilePath, _ := utils.FindConfigFile(dbHandler, logger)
+	// Default instance is connected to at startup, so this can't fail
+	defaultConnection, _ := registry.Connection(instance.DefaultId)
+	// Changes that wait for a time or maintenance window, executors are registered along with routes
+	scheduler := schedule.NewScheduler(schedule.DefaultStoreFile, logger)
+	// Changes made through the API, read back by drift detection
+	audit := utils.NewAuditLog(utils.AuditLogFile)
+	instances := newInstanceRoutes(registry, scheduler, audit, validate, appUser, logger)
+	baselines := compare.NewBaselineStore(compare.DefaultBaselineDir, logger)
+	drift := compare.NewDriftDetector(compare.DefaultDriftStoreFile, registry, baselines, audit, logger)
 
 	////////////////////////
 	// Register routes
-	registerAuthRoute(router, validate, jwt, dbHandler, logger)
-	registerResourceConfigRoute(router, validate, jwt, dbHandler, backupDir, postgresUser, appUser, configFilePath, logger)
-	registerFileRoute(router, validate, jwt, dbHandler, backupDir, postgresUser, appUser, configFilePath, logger)
+	registerAuthRoute(router, validate, jwt, defaultConnection.DbHandler, logger)
+	registerResourceConfigRoute(router, jwt, instances, logger)
+	registerFileRoute(router, jwt, instances, logger)
+	registerKernelConfigRoute(router, jwt, defaultConnection.DbHandler, backupDir, appUser, logger)
+	registerScheduleRoute(router, jwt, scheduler, logger)
+	registerInstanceRoute(router, jwt, registry, scheduler, logger)
+	registerCompareRoute(router, jwt, registry, baselines, drift, logger)
 	// Registers routes for openapi specification
 	registerDocsRoutes(router, logger)
 
+	scheduler.Start()
+	drift.Start()
 	return router
 }
 
@@ -65,42 +82,96 @@ func registerAuthRoute(router *gin.Engine, validate *validator.Validate, jwt *au
 	auth.RegisterHandlersWithOptions(router, authConfigApi, *optionsAuthConfig)
 }
 
-func registerResourceConfigRoute(router *gin.Engine, validate *validator.Validate, jwt *auth.JwtWrapper, dbHandler *sql.DB, backupDir string, postgresUser *utils.User, appUser *utils.User, configFilePath string, logger *utils.Logger) {
-	optionsResourceConfig := &resourceConfig.GinServerOptions{
+// Registered once for the default instance and once for instances picked by id
+func registerResourceConfigRoute(router *gin.Engine, jwt *auth.JwtWrapper, instances *instanceRoutes, logger *utils.Logger) {
+	resourceConfigApi := resourceConfigRoutes{instances}
+	for _, baseURL := range []string{"/api", "/api/instances/:instance_id"} {
+		optionsResourceConfig := &resourceConfig.GinServerOptions{
+			BaseURL: baseURL,
+			Middlewares: []resourceConfig.MiddlewareFunc{
+				resourceConfig.MiddlewareFunc(jwt.ValidateTokenMiddleware(logger)),
+			},
+		}
+		resourceConfig.RegisterHandlersWithOptions(router, resourceConfigApi, *optionsResourceConfig)
+	}
+	instances.scheduler.RegisterExecutor(schedule.ApplySuggestions, instances.runScheduledSuggestions)
+}
+
+// Registered once for the default instance and once for instances picked by id
+func registerFileRoute(router *gin.Engine, jwt *auth.JwtWrapper, instances *instanceRoutes, logger *utils.Logger) {
+	fileApi := fileRoutes{instances}
+	for _, baseURL := range []string{"/api", "/api/instances/:instance_id"} {
+		optionsFile := &file.GinServerOptions{
+			BaseURL: baseURL,
+			Middlewares: []file.MiddlewareFunc{
+				file.MiddlewareFunc(jwt.ValidateTokenMiddleware(logger)),
+				// middleware.OapiRequestValidator(swaggerFile),
+			},
+		}
+		file.RegisterHandlersWithOptions(router, fileApi, *optionsFile)
+	}
+	instances.scheduler.RegisterExecutor(schedule.RestoreBackup, instances.runScheduledRestore)
+}
+
+func registerKernelConfigRoute(router *gin.Engine, jwt *auth.JwtWrapper, dbHandler *sql.DB, backupDir string, appUser *utils.User, logger *utils.Logger) {
+	optionsKernelConfig := &kernelConfig.GinServerOptions{
 		BaseURL: "/api",
-		Middlewares: []resourceConfig.MiddlewareFunc{
-			resourceConfig.MiddlewareFunc(jwt.ValidateTokenMiddleware(logger)),
+		Middlewares: []kernelConfig.MiddlewareFunc{
+			kernelConfig.MiddlewareFunc(jwt.ValidateTokenMiddleware(logger)),
 		},
 	}
-	resourceConfigApi := &resourceConfig.ResourceConfigImpl{
-		ConfigFile:   configFilePath,
-		Logger:       logger,
-		AppUser:      appUser,
-		PostgresUser: postgresUser,
-		DbHandler:    dbHandler,
+	kernelConfigApi := &kernelConfig.KernelConfigImpl{
+		BackupDir: backupDir,
+		AppUser:   appUser,
+		Logger:    logger,
+		DbHandler: dbHandler,
 	}
-	resourceConfig.RegisterHandlersWithOptions(router, resourceConfigApi, *optionsResourceConfig)
+	kernelConfig.RegisterHandlersWithOptions(router, kernelConfigApi, *optionsKernelConfig)
 }
 
-func registerFileRoute(router *gin.Engine, validate *validator.Validate, jwt *auth.JwtWrapper, dbHandler *sql.DB, backupDir string, postgresUser *utils.User, appUser *utils.User, configFilePath string, logger *utils.Logger) {
-	optionsFile := &file.GinServerOptions{
+func registerScheduleRoute(router *gin.Engine, jwt *auth.JwtWrapper, scheduler *schedule.Scheduler, logger *utils.Logger) {
+	optionsSchedule := &schedule.GinServerOptions{
 		BaseURL: "/api",
-		Middlewares: []file.MiddlewareFunc{
-			file.MiddlewareFunc(jwt.ValidateTokenMiddleware(logger)),
-			// middleware.OapiRequestValidator(swaggerFile),
+		Middlewares: []schedule.MiddlewareFunc{
+			schedule.MiddlewareFunc(jwt.ValidateTokenMiddleware(logger)),
 		},
 	}
+	scheduleApi := &schedule.ScheduleImpl{
+		Scheduler: scheduler,
+		Logger:    logger,
+	}
+	schedule.RegisterHandlersWithOptions(router, scheduleApi, *optionsSchedule)
+}
 
-	fileApi := &file.FileImpl{
-		BackupDir:        backupDir,
-		CurrentFile:      filepath.Dir(configFilePath) + "/postgresql.auto.conf",
-		PostgresUsername: postgresUser.Username,
-		AppUser: appUser,
-		Logger:           logger,
-		DbHandler:        dbHandler,
-		Validate:         validate,
+func registerInstanceRoute(router *gin.Engine, jwt *auth.JwtWrapper, registry *instance.Registry, scheduler *schedule.Scheduler, logger *utils.Logger) {
+	optionsInstance := &instance.GinServerOptions{
+		BaseURL: "/api",
+		Middlewares: []instance.MiddlewareFunc{
+			instance.MiddlewareFunc(jwt.ValidateTokenMiddleware(logger)),
+		},
 	}
-	file.RegisterHandlersWithOptions(router, fileApi, *optionsFile)
+	instanceApi := &instance.InstanceImpl{
+		Registry:  registry,
+		Scheduler: scheduler,
+		Logger:    logger,
+	}
+	instance.RegisterHandlersWithOptions(router, instanceApi, *optionsInstance)
+}
+
+func registerCompareRoute(router *gin.Engine, jwt *auth.JwtWrapper, registry *instance.Registry, baselines *compare.BaselineStore, drift *compare.DriftDetector, logger *utils.Logger) {
+	optionsCompare := &compare.GinServerOptions{
+		BaseURL: "/api",
+		Middlewares: []compare.MiddlewareFunc{
+			compare.MiddlewareFunc(jwt.ValidateTokenMiddleware(logger)),
+		},
+	}
+	compareApi := &compare.CompareImpl{
+		Registry:  registry,
+		Baselines: baselines,
+		Drift:     drift,
+		Logger:    logger,
+	}
+	compare.RegisterHandlersWithOptions(router, compareApi, *optionsCompare)
 }
 
 func registerDocsRoutes(router *gin.Engine, logger *utils.Logger) {
@@ -113,6 +184,18 @@ func registerDocsRoutes(router *gin.Engine, logger *utils.Logger) {
 	router.GET("/api/docs/resource-config", func(c *gin.Context) {
 		openAPISpecHandler("resourceConfig", logger).ServeHTTP(c.Writer, c.Request)
 	})
+	router.GET("/api/docs/kernel-config", func(c *gin.Context) {
+		openAPISpecHandler("kernelConfig", logger).ServeHTTP(c.Writer, c.Request)
+	})
+	router.GET("/api/docs/schedule", func(c *gin.Context) {
+		openAPISpecHandler("schedule", logger).ServeHTTP(c.Writer, c.Request)
+	})
+	router.GET("/api/docs/instance", func(c *gin.Context) {
+		openAPISpecHandler("instance", logger).ServeHTTP(c.Writer, c.Request)
+	})
+	router.GET("/api/docs/compare", func(c *gin.Context) {
+		openAPISpecHandler("compare", logger).ServeHTTP(c.Writer, c.Request)
+	})
 }
 
 // Returns a handler function for displaying openapi documentation
@@ -128,7 +211,15 @@ func openAPISpecHandler(docsType string, logger *utils.Logger) http.Handler {
 		case "file":
 			swagger, err = file.GetSwagger()
 		case "resourceConfig":
-			swagger, err = resourceConfig.GetSwagger()
+			swagger, err = resourceConfig.GetSwaggerWithChecks()
+		case "kernelConfig":
+			swagger, err = kernelConfig.GetSwagger()
+		case "schedule":
+			swagger, err = schedule.GetSwagger()
+		case "instance":
+			swagger, err = instance.GetSwagger()
+		case "compare":
+			swagger, err = compare.GetSwagger()
 		default:
 			logger.LogError(fmt.Errorf("Something went wrong loading swagger spec"))
 		}
@@ -0,0 +1,439 @@
+// Autovacuum related checks. Suggestions are based on `pg_stat_user_tables`
+// collected from every database on the server, since autovacuum settings are
+// server wide but the tables that suffer from them can live in any database.
+package resourceConfig
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+func init() {
+	RegisterCheck(&checkDefinition{name: "autovacuum_max_workers", category: CategoryAutovacuum, needsServer: true, requiredSettings: autovacuumSettings, run: (*Configuration).CheckAutovacuumMaxWorkers})
+	RegisterCheck(&checkDefinition{name: "autovacuum_naptime", category: CategoryAutovacuum, needsServer: true, requiredSettings: autovacuumSettings, run: (*Configuration).CheckAutovacuumNaptime})
+	RegisterCheck(&checkDefinition{name: "autovacuum_vacuum_scale_factor", category: CategoryAutovacuum, needsServer: true, requiredSettings: autovacuumSettings, run: (*Configuration).CheckAutovacuumVacuumScaleFactor})
+	RegisterCheck(&checkDefinition{name: "autovacuum_vacuum_cost_limit", category: CategoryAutovacuum, needsServer: true, requiredSettings: append([]string{"vacuum_cost_limit"}, autovacuumSettings...), run: (*Configuration).CheckAutovacuumVacuumCostLimit})
+	RegisterCheck(&checkDefinition{name: "autovacuum_vacuum_cost_delay", category: CategoryAutovacuum, defaults: []versionDefault{{0, "20"}, {120000, "2"}}, run: (*Configuration).CheckAutovacuumVacuumCostDelay})
+}
+
+// Settings needed to work out which tables autovacuum should already have processed
+var autovacuumSettings = []string{"autovacuum_vacuum_threshold", "autovacuum_vacuum_scale_factor", "autovacuum_max_workers"}
+
+const (
+	staleVacuumAge        = 24 * time.Hour // tables not vacuumed for longer than this while overdue are lagging behind
+	largeTableTuples      = 1000000        // tables with more live tuples than this are considered large
+	hotTableMinTuples     = 100000         // smaller tables are not worth a per table override
+	maxTableSuggestions   = 5              // how many of the hottest tables get `ALTER TABLE` suggestions
+	tableStatsCacheExpiry = time.Minute    // autovacuum checks share table statistics for this long
+)
+
+type tableVacuumStats struct {
+	database   string
+	schema     string
+	table      string
+	liveTuples float64
+	deadTuples float64
+	vacuumAge  time.Duration // time since last (auto)vacuum, -1 if never vacuumed
+}
+
+// Tables statistics collected from every database. Cached since all autovacuum checks need them.
+type vacuumStatsResult struct {
+	tables          []tableVacuumStats
+	databases       int      // number of databases statistics were collected from
+	failedDatabases []string // databases that could not be connected to
+	collectedAt     time.Time
+}
+
+// Whether autovacuum should already have vacuumed the table with current settings.
+// Per table storage parameters are not taken into account.
+func (stats *tableVacuumStats) isOverdue(threshold float64, scaleFactor float64) bool {
+	return stats.deadTuples > threshold+scaleFactor*stats.liveTuples
+}
+
+// Overdue and not vacuumed for a long time (or never)
+func (stats *tableVacuumStats) isLagging(threshold float64, scaleFactor float64) bool {
+	return stats.isOverdue(threshold, scaleFactor) && (stats.vacuumAge < 0 || stats.vacuumAge > staleVacuumAge)
+}
+
+func (stats *tableVacuumStats) deadRatio() float64 {
+	if stats.liveTuples+stats.deadTuples == 0 {
+		return 0
+	}
+	return stats.deadTuples / (stats.liveTuples + stats.deadTuples)
+}
+
+// Collects `pg_stat_user_tables` from every database that accepts connections.
+// Falls back to the database `dbHandler` is connected to when no connection details are known.
+func (conf *Configuration) getVacuumStats(logger *utils.Logger) (*vacuumStatsResult, error) {
+	if conf.vacuumStats != nil && time.Since(conf.vacuumStats.collectedAt) < tableStatsCacheExpiry {
+		return conf.vacuumStats, nil
+	}
+
+	result := &vacuumStatsResult{collectedAt: time.Now()}
+	if conf.dbInfo == nil {
+		tables, err := queryTableVacuumStats(conf.dbHandler, "", logger)
+		if err != nil {
+			return nil, err
+		}
+		result.tables = tables
+		result.databases = 1
+		conf.vacuumStats = result
+		return result, nil
+	}
+
+	// 1. List databases
+	rows, err := conf.dbHandler.Query("SELECT datname FROM pg_database WHERE datallowconn AND NOT datistemplate")
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed listing databases: %v", err))
+		return nil, err
+	}
+	var databases []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			logger.LogError(fmt.Errorf("Failed scanning row: %v", err))
+			return nil, err
+		}
+		databases = append(databases, name)
+	}
+	rows.Close()
+
+	// 2. Collect table statistics from each of them
+	for _, database := range databases {
+		dbHandler, err := conf.dbInfo.Connect(database, logger)
+		if err != nil {
+			result.failedDatabases = append(result.failedDatabases, database)
+			continue
+		}
+		tables, err := queryTableVacuumStats(dbHandler, database, logger)
+		utils.CloseDbConnection(dbHandler, logger)
+		if err != nil {
+			result.failedDatabases = append(result.failedDatabases, database)
+			continue
+		}
+		result.tables = append(result.tables, tables...)
+		result.databases++
+	}
+
+	if result.databases == 0 {
+		err := fmt.Errorf("could not collect table statistics from any database")
+		logger.LogError(err)
+		return nil, err
+	}
+
+	conf.vacuumStats = result
+	return result, nil
+}
+
+func queryTableVacuumStats(dbHandler *sql.DB, database string, logger *utils.Logger) ([]tableVacuumStats, error) {
+	query := `SELECT current_database(), schemaname, relname, n_live_tup, n_dead_tup,
+		EXTRACT(EPOCH FROM now() - GREATEST(last_autovacuum, last_vacuum))
+		FROM pg_stat_user_tables`
+	rows, err := dbHandler.Query(query)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed querying pg_stat_user_tables in database %s: %v", database, err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []tableVacuumStats
+	for rows.Next() {
+		var stats tableVacuumStats
+		var vacuumAge sql.NullFloat64
+		if err := rows.Scan(&stats.database, &stats.schema, &stats.table, &stats.liveTuples, &stats.deadTuples, &vacuumAge); err != nil {
+			logger.LogError(fmt.Errorf("Failed scanning row: %v", err))
+			return nil, err
+		}
+		stats.vacuumAge = -1
+		if vacuumAge.Valid {
+			stats.vacuumAge = time.Duration(vacuumAge.Float64 * float64(time.Second))
+		}
+		tables = append(tables, stats)
+	}
+	return tables, nil
+}
+
+// Returns autovacuum_vacuum_threshold and autovacuum_vacuum_scale_factor as numbers
+func (conf *Configuration) getVacuumThresholds(logger *utils.Logger) (float64, float64, error) {
+	threshold, err := conf.getSpecificPGSetting("autovacuum_vacuum_threshold", logger)
+	if err != nil {
+		return 0, 0, err
+	}
+	scaleFactor, err := conf.getSpecificPGSetting("autovacuum_vacuum_scale_factor", logger)
+	if err != nil {
+		return 0, 0, err
+	}
+	thresholdValue, err := utils.StringToFloat64(threshold.Value)
+	if err != nil {
+		return 0, 0, err
+	}
+	scaleFactorValue, err := utils.StringToFloat64(scaleFactor.Value)
+	if err != nil {
+		return 0, 0, err
+	}
+	return thresholdValue, scaleFactorValue, nil
+}
+
+// Counts overdue and lagging tables with current thresholds
+func (conf *Configuration) countOverdueTables(stats *vacuumStatsResult, logger *utils.Logger) (int, int, error) {
+	threshold, scaleFactor, err := conf.getVacuumThresholds(logger)
+	if err != nil {
+		return 0, 0, err
+	}
+	overdue, lagging := 0, 0
+	for _, table := range stats.tables {
+		if table.isOverdue(threshold, scaleFactor) {
+			overdue++
+		}
+		if table.isLagging(threshold, scaleFactor) {
+			lagging++
+		}
+	}
+	return overdue, lagging, nil
+}
+
+// Describes where the statistics came from so that Details can be interpreted correctly
+func (stats *vacuumStatsResult) describe() string {
+	details := fmt.Sprintf("Statistics were collected from %d tables across %d database(s). ", len(stats.tables), stats.databases)
+	if len(stats.failedDatabases) > 0 {
+		details += fmt.Sprintf("Could not connect to %d database(s) (%v), their tables were not taken into account. ", len(stats.failedDatabases), stats.failedDatabases)
+	}
+	return details
+}
+
+func (conf *Configuration) CheckAutovacuumMaxWorkers(logger *utils.Logger) (*ResourceSetting, error) {
+	maxWorkers := conf.settings["autovacuum_max_workers"]
+	maxWorkers.Details = "This setting specifies the maximum number of autovacuum processes that may be running at any one time. "
+
+	stats, err := conf.getVacuumStats(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed autovacuum_max_workers check: %v", err))
+		maxWorkers.GotError = true
+		return nil, err
+	}
+	overdue, _, err := conf.countOverdueTables(stats, logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed autovacuum_max_workers check: %v", err))
+		maxWorkers.GotError = true
+		return nil, err
+	}
+	currentValue, err := utils.StringToInt(maxWorkers.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed autovacuum_max_workers check: %v", err))
+		maxWorkers.GotError = true
+		return nil, err
+	}
+
+	maxWorkers.Details += stats.describe()
+	if overdue > currentValue {
+		// Double the workers, but there's no point having more than there are tables to vacuum
+		suggestion := currentValue * 2
+		if suggestion > overdue {
+			suggestion = overdue
+		}
+		maxWorkers.SuggestedValue = fmt.Sprint(suggestion)
+		maxWorkers.Details += fmt.Sprintf("%d tables currently have more dead tuples than autovacuum's threshold, which is more than the %d workers that can process them at once. Suggestion is to increase the number of workers. Keep in mind that all workers share autovacuum_vacuum_cost_limit, so it may need to be increased as well for the extra workers to be useful.", overdue, currentValue)
+	} else {
+		maxWorkers.Details += fmt.Sprintf("%d tables currently have more dead tuples than autovacuum's threshold, which the current %d workers should be able to keep up with.", overdue, currentValue)
+	}
+
+	resetSuggestionIfEqual(&maxWorkers)
+	conf.settings["autovacuum_max_workers"] = maxWorkers
+	return &maxWorkers, nil
+}
+
+func (conf *Configuration) CheckAutovacuumNaptime(logger *utils.Logger) (*ResourceSetting, error) {
+	naptime := conf.settings["autovacuum_naptime"]
+	naptime.Details = "This setting specifies the minimum delay between autovacuum runs on any given database. "
+
+	stats, err := conf.getVacuumStats(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed autovacuum_naptime check: %v", err))
+		naptime.GotError = true
+		return nil, err
+	}
+	_, lagging, err := conf.countOverdueTables(stats, logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed autovacuum_naptime check: %v", err))
+		naptime.GotError = true
+		return nil, err
+	}
+	// autovacuum_naptime is always reported in seconds
+	currentValue, err := utils.StringToInt(naptime.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed autovacuum_naptime check: %v", err))
+		naptime.GotError = true
+		return nil, err
+	}
+
+	naptime.Details += stats.describe()
+	if lagging > 0 && currentValue > 30 {
+		naptime.SuggestedValue = "30"
+		naptime.Details += fmt.Sprintf("%d tables are past autovacuum's threshold and have not been vacuumed in over %.0f hours. Waking autovacuum up more often lets it notice these tables sooner. Suggestion is to set this to 30 seconds.", lagging, staleVacuumAge.Hours())
+	} else if lagging == 0 && currentValue < 15 {
+		naptime.SuggestedValue = "60"
+		naptime.Details += "No tables are lagging behind on vacuuming, so autovacuum is waking up more often than needed. Suggestion is to set this to the default of 1 minute."
+	}
+
+	resetSuggestionIfEqual(&naptime)
+	conf.settings["autovacuum_naptime"] = naptime
+	return &naptime, nil
+}
+
+// Besides the server wide value, suggests per table overrides for the hottest large tables
+func (conf *Configuration) CheckAutovacuumVacuumScaleFactor(logger *utils.Logger) (*ResourceSetting, error) {
+	scaleFactor := conf.settings["autovacuum_vacuum_scale_factor"]
+	scaleFactor.Details = "This setting specifies a fraction of the table size to add to autovacuum_vacuum_threshold when deciding whether to trigger a VACUUM. "
+
+	stats, err := conf.getVacuumStats(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed autovacuum_vacuum_scale_factor check: %v", err))
+		scaleFactor.GotError = true
+		return nil, err
+	}
+	currentValue, err := utils.StringToFloat64(scaleFactor.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed autovacuum_vacuum_scale_factor check: %v", err))
+		scaleFactor.GotError = true
+		return nil, err
+	}
+
+	// 1. Server wide suggestion based on whether there are large tables
+	largeTables := 0
+	for _, table := range stats.tables {
+		if table.liveTuples >= largeTableTuples {
+			largeTables++
+		}
+	}
+	scaleFactor.Details += stats.describe()
+	if largeTables > 0 && currentValue > 0.1 {
+		scaleFactor.SuggestedValue = "0.1"
+		scaleFactor.Details += fmt.Sprintf("%d tables have more than %d live tuples. With the current value such tables accumulate %.0f%% of their size in dead tuples before being vacuumed. Suggestion is to lower this to 0.1. ", largeTables, largeTableTuples, currentValue*100)
+	}
+
+	// 2. Per table suggestions for tables with the most dead tuples
+	scaleFactor.TableSuggestions = hotTableSuggestions(stats.tables)
+	if len(scaleFactor.TableSuggestions) > 0 {
+		scaleFactor.Details += fmt.Sprintf("The %d tables with the most dead tuples should be vacuumed more aggressively than the rest, see table suggestions for ALTER TABLE statements that override autovacuum settings for them.", len(scaleFactor.TableSuggestions))
+	}
+
+	resetSuggestionIfEqual(&scaleFactor)
+	conf.settings["autovacuum_vacuum_scale_factor"] = scaleFactor
+	return &scaleFactor, nil
+}
+
+// Picks the tables with most dead tuples out of the ones large enough to
+// warrant an override and makes `ALTER TABLE` suggestions for them
+func hotTableSuggestions(tables []tableVacuumStats) []TableSuggestion {
+	var candidates []tableVacuumStats
+	for _, table := range tables {
+		if table.liveTuples >= hotTableMinTuples && table.deadRatio() >= 0.05 {
+			candidates = append(candidates, table)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].deadTuples > candidates[j].deadTuples
+	})
+	if len(candidates) > maxTableSuggestions {
+		candidates = candidates[:maxTableSuggestions]
+	}
+
+	var suggestions []TableSuggestion
+	for _, table := range candidates {
+		// The bigger the table, the smaller the fraction of it we let turn into dead tuples
+		tableScaleFactor := "0.05"
+		if table.liveTuples >= 10*largeTableTuples {
+			tableScaleFactor = "0.01"
+		} else if table.liveTuples >= largeTableTuples {
+			tableScaleFactor = "0.02"
+		}
+
+		name := pq.QuoteIdentifier(table.schema) + "." + pq.QuoteIdentifier(table.table)
+		suggestions = append(suggestions, TableSuggestion{
+			Database:  table.database,
+			Table:     name,
+			Statement: fmt.Sprintf("ALTER TABLE %s SET (autovacuum_vacuum_scale_factor = %s, autovacuum_vacuum_threshold = 1000)", name, tableScaleFactor),
+			Details:   fmt.Sprintf("Table has %.0f live and %.0f dead tuples (%.0f%% dead).", table.liveTuples, table.deadTuples, table.deadRatio()*100),
+		})
+	}
+	return suggestions
+}
+
+func (conf *Configuration) CheckAutovacuumVacuumCostLimit(logger *utils.Logger) (*ResourceSetting, error) {
+	costLimit := conf.settings["autovacuum_vacuum_cost_limit"]
+	costLimit.Details = "This setting specifies the cost limit value that will be used in automatic VACUUM operations. The limit is shared between all running autovacuum workers. "
+
+	stats, err := conf.getVacuumStats(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed autovacuum_vacuum_cost_limit check: %v", err))
+		costLimit.GotError = true
+		return nil, err
+	}
+	_, lagging, err := conf.countOverdueTables(stats, logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed autovacuum_vacuum_cost_limit check: %v", err))
+		costLimit.GotError = true
+		return nil, err
+	}
+
+	// -1 means vacuum_cost_limit is used instead
+	effectiveValue := costLimit.Value
+	if costLimit.Value == "-1" {
+		vacuumCostLimit, err := conf.getSpecificPGSetting("vacuum_cost_limit", logger)
+		if err != nil {
+			logger.LogError(fmt.Errorf("Failed autovacuum_vacuum_cost_limit check: %v", err))
+			costLimit.GotError = true
+			return nil, err
+		}
+		effectiveValue = vacuumCostLimit.Value
+	}
+	effectiveLimit, err := utils.StringToInt(effectiveValue)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed autovacuum_vacuum_cost_limit check: %v", err))
+		costLimit.GotError = true
+		return nil, err
+	}
+
+	costLimit.Details += stats.describe()
+	if lagging > 0 && effectiveLimit < 2000 {
+		costLimit.SuggestedValue = "2000"
+		costLimit.Details += fmt.Sprintf("%d tables are past autovacuum's threshold and have not been vacuumed in over %.0f hours, meaning autovacuum is being throttled too much to keep up. Current effective limit is %d. Suggestion is to raise the limit to 2000.", lagging, staleVacuumAge.Hours(), effectiveLimit)
+	}
+
+	resetSuggestionIfEqual(&costLimit)
+	conf.settings["autovacuum_vacuum_cost_limit"] = costLimit
+	return &costLimit, nil
+}
+
+// GENERALREC
+func (conf *Configuration) CheckAutovacuumVacuumCostDelay(logger *utils.Logger) (*ResourceSetting, error) {
+	costDelay := conf.settings["autovacuum_vacuum_cost_delay"]
+	costDelay.Details = "This setting specifies the cost delay value that will be used in automatic VACUUM operations. "
+
+	// autovacuum_vacuum_cost_delay is always reported in milliseconds
+	delayAsMs, err := utils.StringToFloat32(costDelay.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed autovacuum_vacuum_cost_delay check: %v", err))
+		costDelay.GotError = true
+		return nil, err
+	}
+
+	if delayAsMs > 2 {
+		costDelay.SuggestedValue = "2"
+		costDelay.Details += "Current value is higher than 2ms. The old default of 20ms was chosen for much slower disks and makes autovacuum sleep most of the time on modern hardware. Suggestion is to set this to 2ms (the default since PostgreSQL 12)."
+		if defaultValue := conf.defaultValue("autovacuum_vacuum_cost_delay"); defaultValue != "2" {
+			costDelay.Details += fmt.Sprintf(" On this PostgreSQL version the default is still %sms, so it has to be set explicitly.", defaultValue)
+		}
+	}
+
+	resetSuggestionIfEqual(&costDelay)
+	conf.settings["autovacuum_vacuum_cost_delay"] = costDelay
+	return &costDelay, nil
+}
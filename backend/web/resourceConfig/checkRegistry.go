@@ -0,0 +1,218 @@
+// Registry of configuration checks. Every check registers itself from an
+// `init()` function in the file it's defined in, so adding a new check only
+// requires adding a new file. The settings query, the `/resource/{config}`
+// endpoint and its openapi enum are all driven from this registry.
+package resourceConfig
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+// Categories checks can be grouped by
+const (
+	CategoryMemory      = "memory"
+	CategoryWal         = "wal"
+	CategoryAutovacuum  = "autovacuum"
+	CategoryPlanner     = "planner"
+	CategoryParallel    = "parallel"
+	CategoryConnections = "connections"
+)
+
+var ErrUnknownCheck = errors.New("no resource configuration with name")
+
+// A single configuration check
+type Check interface {
+	Name() string               // name of the setting the check makes a suggestion for
+	Category() string           // memory, wal, autovacuum, etc...
+	RequiredSettings() []string // other `pg_settings` rows the check reads
+	DependsOn() []string        // checks that need to run before this one
+	// server_version_num range the setting exists in. 0 means no lower/upper bound.
+	// The upper bound is the first version the setting was removed in.
+	SupportedVersions() (minVersion int, removedIn int)
+	DefaultValue(version int) string // boot value on given server version, empty if not declared
+	NeedsServer() bool               // check reads statistics of a running server and can't run offline
+	Run(conf *Configuration, logger *utils.Logger) (*ResourceSetting, error)
+}
+
+// Default value of a setting starting from server_version_num `since`
+type versionDefault struct {
+	since int
+	value string
+}
+
+// Default `Check` implementation wrapping one of the `Configuration.Check*` methods
+type checkDefinition struct {
+	name             string
+	category         string
+	requiredSettings []string
+	dependsOn        []string
+	minVersion       int              // first server_version_num the setting exists in
+	removedIn        int              // server_version_num the setting was removed in
+	defaults         []versionDefault // ordered by `since`
+	needsServer      bool             // based on statistics, skipped when analysing configuration files
+	run              func(conf *Configuration, logger *utils.Logger) (*ResourceSetting, error)
+}
+
+func (check *checkDefinition) Name() string               { return check.name }
+func (check *checkDefinition) Category() string           { return check.category }
+func (check *checkDefinition) RequiredSettings() []string { return check.requiredSettings }
+func (check *checkDefinition) DependsOn() []string        { return check.dependsOn }
+func (check *checkDefinition) SupportedVersions() (int, int) {
+	return check.minVersion, check.removedIn
+}
+func (check *checkDefinition) DefaultValue(version int) string {
+	value := ""
+	for _, def := range check.defaults {
+		// Unknown version (0) gets the newest default
+		if version >= def.since || version == 0 {
+			value = def.value
+		}
+	}
+	return value
+}
+func (check *checkDefinition) NeedsServer() bool { return check.needsServer }
+func (check *checkDefinition) Run(conf *Configuration, logger *utils.Logger) (*ResourceSetting, error) {
+	return check.run(conf, logger)
+}
+
+// Returns an empty string if check applies to server @version, otherwise the reason it doesn't.
+// An unknown version (0) is assumed to support every check.
+func unsupportedReason(check Check, version int) string {
+	minVersion, removedIn := check.SupportedVersions()
+	if version == 0 {
+		return ""
+	}
+	if minVersion > 0 && version < minVersion {
+		return fmt.Sprintf("Check skipped: %s was introduced in PostgreSQL %s, but the server runs PostgreSQL %s.", check.Name(), utils.FormatMajorVersion(minVersion), utils.FormatServerVersion(version))
+	}
+	if removedIn > 0 && version >= removedIn {
+		return fmt.Sprintf("Check skipped: %s was removed in PostgreSQL %s, but the server runs PostgreSQL %s.", check.Name(), utils.FormatMajorVersion(removedIn), utils.FormatServerVersion(version))
+	}
+	return ""
+}
+
+var (
+	checks     = make(map[string]Check)
+	checkOrder []string // names in registration order
+)
+
+// Adds check to the registry. Meant to be called from `init()`,
+// registering the same name twice is a programming error.
+func RegisterCheck(check Check) {
+	if _, exists := checks[check.Name()]; exists {
+		panic(fmt.Sprintf("resourceConfig: check %s registered twice", check.Name()))
+	}
+	checks[check.Name()] = check
+	checkOrder = append(checkOrder, check.Name())
+}
+
+// Returns registered check by name
+func GetCheck(name string) (Check, bool) {
+	check, ok := checks[name]
+	return check, ok
+}
+
+// Returns all registered checks ordered so that every check comes after
+// the checks it depends on. Otherwise registration order is kept.
+func RegisteredChecks() []Check {
+	ordered := make([]Check, 0, len(checkOrder))
+	visited := make(map[string]bool)
+
+	var visit func(name string)
+	visit = func(name string) {
+		check, ok := checks[name]
+		if !ok || visited[name] {
+			return
+		}
+		// Mark before visiting dependencies so that cycles can't recurse forever
+		visited[name] = true
+		for _, dependency := range check.DependsOn() {
+			visit(dependency)
+		}
+		ordered = append(ordered, check)
+	}
+
+	for _, name := range checkOrder {
+		visit(name)
+	}
+	return ordered
+}
+
+// Returns sorted names of all registered checks
+func CheckNames() []string {
+	names := make([]string, 0, len(checkOrder))
+	names = append(names, checkOrder...)
+	sort.Strings(names)
+	return names
+}
+
+// Returns every setting that has to be read from `pg_settings`
+// for the registered checks to run
+func RequiredSettings() []string {
+	seen := make(map[string]bool)
+	var settings []string
+	for _, check := range RegisteredChecks() {
+		for _, name := range append([]string{check.Name()}, check.RequiredSettings()...) {
+			if !seen[name] {
+				seen[name] = true
+				settings = append(settings, name)
+			}
+		}
+	}
+	return settings
+}
+
+// Returns all (transitive) dependencies of check in the order they should run
+func dependenciesOf(check Check) []Check {
+	var dependencies []Check
+	visited := map[string]bool{check.Name(): true}
+
+	var visit func(name string)
+	visit = func(name string) {
+		dependency, ok := checks[name]
+		if !ok || visited[name] {
+			return
+		}
+		visited[name] = true
+		for _, next := range dependency.DependsOn() {
+			visit(next)
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	for _, name := range check.DependsOn() {
+		visit(name)
+	}
+	return dependencies
+}
+
+// Same as `GetSwagger`, but fills in the `config` path parameter's enum
+// with names of the currently registered checks
+func GetSwaggerWithChecks() (*openapi3.T, error) {
+	swagger, err := GetSwagger()
+	if err != nil {
+		return nil, err
+	}
+
+	pathItem := swagger.Paths.Find("/resource/{config}")
+	if pathItem == nil || pathItem.Get == nil {
+		return swagger, nil
+	}
+	for _, parameter := range pathItem.Get.Parameters {
+		if parameter.Value == nil || parameter.Value.Name != "config" || parameter.Value.Schema == nil {
+			continue
+		}
+		var enum []interface{}
+		for _, name := range CheckNames() {
+			enum = append(enum, name)
+		}
+		parameter.Value.Schema.Value.Enum = enum
+	}
+	return swagger, nil
+}
@@ -0,0 +1,85 @@
+// Connection related checks
+package resourceConfig
+
+import (
+	"fmt"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+func init() {
+	RegisterCheck(&checkDefinition{name: "max_connections", category: CategoryConnections, needsServer: true, requiredSettings: memoryBudgetSettings, run: (*Configuration).CheckMaxConnections})
+}
+
+// Returns the number of client connections currently open. `backend_type` was added in
+// PostgreSQL 10, before that only backends connected to a database are counted, except ours.
+func (conf *Configuration) getClientConnections(logger *utils.Logger) (int, error) {
+	query := "SELECT count(*) FROM pg_stat_activity WHERE backend_type = 'client backend'"
+	if conf.serverVersion != 0 && conf.serverVersion < 100000 {
+		query = "SELECT count(*) FROM pg_stat_activity WHERE pid <> pg_backend_pid() AND datname IS NOT NULL"
+	}
+
+	var connections int
+	row := conf.dbHandler.QueryRow(query)
+	if err := row.Scan(&connections); err != nil {
+		logger.LogError(fmt.Errorf("Failed counting client connections: %v", err))
+		return 0, err
+	}
+	return connections, nil
+}
+
+// Rounds @n up to the nearest multiple of 10
+func roundUpToTen(n int) int {
+	return (n + 9) / 10 * 10
+}
+
+func (conf *Configuration) CheckMaxConnections(logger *utils.Logger) (*ResourceSetting, error) {
+	maxConnections := conf.settings["max_connections"]
+	maxConnections.Details = "This setting determines the maximum number of concurrent connections to the database server. "
+
+	// 1. Get current value, connection usage, core count and worst case memory usage
+	currentValue, err := utils.StringToInt(maxConnections.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed max_connections check: %v", err))
+		maxConnections.GotError = true
+		return nil, err
+	}
+	usedConnections, err := conf.getClientConnections(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed max_connections check: %v", err))
+		maxConnections.GotError = true
+		return nil, err
+	}
+	cpuInfo, err := conf.getCpuInfo(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed max_connections check: %v", err))
+		maxConnections.GotError = true
+		return nil, err
+	}
+	budget, err := conf.CalculateMemoryBudget(false, logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed max_connections check: %v", err))
+		maxConnections.GotError = true
+		return nil, err
+	}
+
+	maxConnections.Details += fmt.Sprintf("%d of %d connections are currently in use. ", usedConnections, currentValue)
+
+	// 2. Suggest more connections when close to running out. Lowering it is only described: a single
+	// sample may have been taken off-peak, and clients refused at peak are worse than unused headroom.
+	cores := cpuInfo.UsableCores()
+	if float64(usedConnections) >= float64(currentValue)*0.8 {
+		maxConnections.SuggestedValue = fmt.Sprint(roundUpToTen(currentValue * 5 / 4))
+		maxConnections.Details += "More than 80% of allowed connections are in use, so new clients may soon be refused. Suggestion is to increase this by 25%. If the application opens a connection per request, a connection pooler such as PgBouncer is a better solution than raising this further."
+	} else if currentValue > 100 && currentValue > 4*cores && usedConnections < currentValue/2 {
+		maxConnections.Details += fmt.Sprintf("Fewer than half of allowed connections are in use right now while the server only has %d usable cores to run them on. Every allowed connection adds to the worst case memory usage through work_mem. This is a single sample, so no lower value is suggested; if connections stay this low at peak times as well, this can be lowered to about twice the peak (no lower than the default of 100). Lowering it takes a restart. ", cores)
+	}
+
+	if budget.ExceedsTotalMemory {
+		maxConnections.Details += budget.Details
+	}
+
+	resetSuggestionIfEqual(&maxConnections)
+	conf.settings["max_connections"] = maxConnections
+	return &maxConnections, nil
+}
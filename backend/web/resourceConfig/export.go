@@ -0,0 +1,142 @@
+// Suggestions exported as configuration for tools that manage PostgreSQL
+// settings, for setups where `ALTER SYSTEM` would fight with config management.
+// Every format is generated from the same body `PATCH /api/resource` accepts.
+package resourceConfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+var ErrInvalidExport = errors.New("invalid suggestion")
+
+// Content type and file extension of each export format
+var exportFormats = map[ExportResourceConfigsParamsFormat]struct {
+	contentType string
+	extension   string
+}{
+	Sql:           {"application/sql", "sql"},
+	Conf:          {"text/plain; charset=utf-8", "conf"},
+	Ansible:       {"application/yaml", "yml"},
+	Patroni:       {"application/yaml", "yml"},
+	Cloudnativepg: {"application/yaml", "yaml"},
+}
+
+// Returns an error if @format is not an export format
+func ValidateExportFormat(format string) error {
+	if _, ok := exportFormats[ExportResourceConfigsParamsFormat(format)]; !ok {
+		return fmt.Errorf("unknown export format: %s, expected sql, conf, ansible, patroni or cloudnativepg", format)
+	}
+	return nil
+}
+
+// Name an export in @format is downloaded as
+func ExportFileName(format ExportResourceConfigsParamsFormat) string {
+	return fmt.Sprintf("postgrescrutiniser-%s.%s", format, exportFormats[format].extension)
+}
+
+/*
+Renders suggestions in @format. Returns the rendered file and its content type.
+@suggestions - settings and values as sent to `PATCH /api/resource`
+*/
+func (conf *Configuration) ExportSuggestions(suggestions PatchResourceConfigsJSONBody, format ExportResourceConfigsParamsFormat) ([]byte, string, error) {
+	if err := ValidateExportFormat(string(format)); err != nil {
+		return nil, "", err
+	}
+
+	// 1. Only settings we have checks for can be exported. Names end up unquoted in every format.
+	// Per-database and per-role suggestions only have an equivalent in SQL.
+	values := make([][2]string, 0, len(suggestions))
+	scoped := []string{}
+	for _, suggestion := range suggestions {
+		if _, ok := GetCheck(suggestion.Name); !ok {
+			return nil, "", fmt.Errorf("%w: %s", ErrUnknownCheck, suggestion.Name)
+		}
+		if strings.ContainsAny(suggestion.SuggestedValue, "\r\n\x00") {
+			return nil, "", fmt.Errorf("%w: value of %s has to be on a single line", ErrInvalidExport, suggestion.Name)
+		}
+		value := conf.exportValue(suggestion.Name, suggestion.SuggestedValue)
+		if database, role := suggestion.scope(); database != "" || role != "" {
+			if format != Sql {
+				return nil, "", fmt.Errorf("%w: %s is set for a database or role, which can only be exported as sql", ErrInvalidExport, suggestion.Name)
+			}
+			statement, err := utils.RoleSettingStatement(database, role, suggestion.Name, &value)
+			if err != nil {
+				return nil, "", fmt.Errorf("%w: %v", ErrInvalidExport, err)
+			}
+			scoped = append(scoped, statement)
+			continue
+		}
+		values = append(values, [2]string{suggestion.Name, value})
+	}
+
+	// 2. Render
+	var builder strings.Builder
+	generated := fmt.Sprintf("Generated by PostgreScrutiniser on %s", time.Now().UTC().Format(time.RFC3339))
+	switch format {
+	case Sql:
+		fmt.Fprintf(&builder, "-- %s\n-- Run as a superuser. Settings with postmaster context only take effect after a restart.\n", generated)
+		for _, value := range values {
+			literal, err := utils.SettingValueSql(value[0], value[1])
+			if err != nil {
+				return nil, "", fmt.Errorf("%w: %v", ErrInvalidExport, err)
+			}
+			fmt.Fprintf(&builder, "ALTER SYSTEM SET %s = %s;\n", value[0], literal)
+		}
+		if len(values) > 0 {
+			builder.WriteString("SELECT pg_reload_conf();\n")
+		}
+		// Take effect in new sessions, no reload needed
+		for _, statement := range scoped {
+			builder.WriteString(statement + ";\n")
+		}
+	case Conf:
+		fmt.Fprintf(&builder, "# %s\n# Include at the end of postgresql.conf, e.g. include_if_exists = 'postgrescrutiniser.conf'\n", generated)
+		for _, value := range values {
+			fmt.Fprintf(&builder, "%s = %s\n", value[0], quoteConf(value[1]))
+		}
+	case Ansible:
+		fmt.Fprintf(&builder, "---\n# %s\npostgresql_parameters:\n", generated)
+		writeYamlParameters(&builder, values, "  ")
+	case Patroni:
+		fmt.Fprintf(&builder, "# %s\n# Merge into the dynamic configuration with `patronictl edit-config`\npostgresql:\n  parameters:\n", generated)
+		writeYamlParameters(&builder, values, "    ")
+	case Cloudnativepg:
+		fmt.Fprintf(&builder, "# %s\n# Merge into the spec of a postgresql.cnpg.io/v1 Cluster\nspec:\n  postgresql:\n    parameters:\n", generated)
+		writeYamlParameters(&builder, values, "      ")
+	}
+	return []byte(builder.String()), exportFormats[format].contentType, nil
+}
+
+// Value as it would be written by hand. Integers get the unit of the setting appended
+// so that exported files can be read without knowing each setting's base unit.
+func (conf *Configuration) exportValue(name string, value string) string {
+	return utils.FormatValueWithUnit(value, conf.settings[name].Unit)
+}
+
+// Parameters as a YAML mapping. Values are always quoted since tools like
+// CloudNativePG expect strings and YAML would otherwise turn `on` into a boolean.
+func writeYamlParameters(builder *strings.Builder, values [][2]string, indent string) {
+	if len(values) == 0 {
+		builder.WriteString(indent + "{}\n")
+		return
+	}
+	for _, value := range values {
+		fmt.Fprintf(builder, "%s%s: %s\n", indent, value[0], quoteSql(value[1]))
+	}
+}
+
+// Single quoted SQL literal. YAML single quoted scalars escape quotes the same way.
+func quoteSql(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// Single quoted postgresql.conf value, where backslashes start escape sequences
+func quoteConf(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
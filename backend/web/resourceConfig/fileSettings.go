@@ -0,0 +1,164 @@
+// Source of truth analysis. Entries of the configuration files are read from
+// `pg_file_settings` and each one is told apart as in effect, shadowed by a later
+// entry, overridden by postgresql.auto.conf or a higher priority source, or broken.
+// Meant to explain why editing postgresql.conf "did nothing".
+package resourceConfig
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+// `pg_settings.source` values used by this application
+const (
+	sourceDefault    = "default"
+	sourceConfigFile = "configuration file"
+)
+
+// Sources that take priority over configuration files for every session. Sources like
+// database or user only describe the connection of this application and are left out,
+// overrides for a database or role are listed from `pg_db_role_setting` instead.
+var overridingSources = map[string]bool{
+	"command line":         true,
+	"environment variable": true,
+	"global":               true,
+}
+
+// Returns entries of the configuration files in the order they apply. Unless @all is
+// set, only entries that are not in effect are returned.
+func (conf *Configuration) GetFileSettings(all bool, logger *utils.Logger) ([]FileSetting, error) {
+	var entries []FileSetting
+	var sources map[string]string
+	var overrides []utils.RoleSetting
+	var err error
+	if conf.offline != nil {
+		entries = conf.offline.fileSettings()
+	} else if conf.dbHandler == nil {
+		return nil, fmt.Errorf("listing configuration file entries needs a database connection")
+	} else if entries, sources, err = queryFileSettings(conf.dbHandler, logger); err != nil {
+		return nil, err
+	} else if overrides, err = utils.GetRoleSettings(conf.dbHandler, logger); err != nil {
+		return nil, err
+	}
+
+	classifyFileSettings(entries, sources)
+	addRoleOverrides(entries, overrides)
+	if all {
+		return entries, nil
+	}
+	problems := []FileSetting{}
+	for _, entry := range entries {
+		if entry.Status != Applied {
+			problems = append(problems, entry)
+		}
+	}
+	return problems, nil
+}
+
+// Reads `pg_file_settings` along with the value each setting is running with.
+// Also returns the `pg_settings.source` of each setting. Status is left to `classifyFileSettings`.
+func queryFileSettings(dbHandler *sql.DB, logger *utils.Logger) ([]FileSetting, map[string]string, error) {
+	rows, err := dbHandler.Query(`SELECT coalesce(f.name, ''), coalesce(f.setting, ''), coalesce(f.sourcefile, ''), coalesce(f.sourceline, 0),
+			f.applied, f.error, s.setting, s.source
+		FROM pg_file_settings f
+		LEFT JOIN pg_settings s ON s.name = f.name
+		ORDER BY f.seqno`)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed querying configuration file entries: %v", err))
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	entries := []FileSetting{}
+	sources := make(map[string]string)
+	for rows.Next() {
+		var entry FileSetting
+		var entryError, value, source sql.NullString
+		if err := rows.Scan(&entry.Name, &entry.Setting, &entry.Sourcefile, &entry.Sourceline, &entry.Applied, &entryError, &value, &source); err != nil {
+			logger.LogError(fmt.Errorf("Failed scanning row: %v", err))
+			return nil, nil, err
+		}
+		if entryError.Valid {
+			entry.Error = &entryError.String
+		}
+		if value.Valid {
+			entry.Value = &value.String
+		}
+		if source.Valid {
+			sources[entry.Name] = source.String
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		logger.LogError(fmt.Errorf("Failed querying configuration file entries: %v", err))
+		return nil, nil, err
+	}
+	return entries, sources, nil
+}
+
+// Configuration file entries read for offline analysis. Files that were read
+// successfully have no errors and nothing overrides them.
+func (env *offlineEnvironment) fileSettings() []FileSetting {
+	entries := make([]FileSetting, 0, len(env.entries))
+	for _, entry := range env.entries {
+		entries = append(entries, FileSetting{Name: entry.Name, Setting: entry.Value, Sourcefile: entry.File, Sourceline: entry.Line})
+	}
+	return entries
+}
+
+/*
+Sets status of @entries, which have to be in the order they apply. The last entry
+without an error wins, like it does in PostgreSQL.
+@sources - `pg_settings.source` of each setting, nil when analysing files offline
+*/
+func classifyFileSettings(entries []FileSetting, sources map[string]string) {
+	// 1. Find the entry in effect for each setting
+	effective := make(map[string]int)
+	for i, entry := range entries {
+		if entry.Error == nil && entry.Name != "" {
+			effective[entry.Name] = i
+		}
+	}
+
+	// 2. Tell everything else apart by why it isn't in effect
+	for i := range entries {
+		entry := &entries[i]
+		runningSource := sources[entry.Name]
+		winner, ok := effective[entry.Name]
+		switch {
+		case entry.Error != nil || !ok:
+			entry.Status = Error
+			entry.Applied = false
+		case winner != i:
+			location := fmt.Sprintf("%s:%d", entries[winner].Sourcefile, entries[winner].Sourceline)
+			entry.OverriddenBy = &location
+			entry.Status = Shadowed
+			if filepath.Base(entries[winner].Sourcefile) == "postgresql.auto.conf" {
+				entry.Status = OverriddenByAutoConf
+			}
+		case overridingSources[runningSource]:
+			entry.OverriddenBy = &runningSource
+			entry.Status = Overridden
+		default:
+			entry.Status = Applied
+			entry.Applied = true
+		}
+	}
+}
+
+// Lists @overrides from `pg_db_role_setting` with the entries of the setting they override.
+// They only apply to sessions of their database or role, so status is left as it is.
+func addRoleOverrides(entries []FileSetting, overrides []utils.RoleSetting) {
+	byName := make(map[string][]utils.RoleSetting)
+	for _, override := range overrides {
+		byName[override.Name] = append(byName[override.Name], override)
+	}
+	for i := range entries {
+		if settingOverrides, ok := byName[entries[i].Name]; ok {
+			entries[i].RoleOverrides = &settingOverrides
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Huge pages sizing calculator. Works out how many huge pages the main shared
+// memory area needs, so that a kernel value for vm.nr_hugepages can be recommended.
+package resourceConfig
+
+import (
+	"fmt"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+// Settings huge page estimates are calculated from. `shared_memory_size` and
+// `shared_memory_size_in_huge_pages` only exist on PostgreSQL 15 and later.
+var hugePageSettings = []string{
+	"shared_buffers",
+	"wal_buffers",
+	"huge_page_size",
+	"shared_memory_size",
+	"shared_memory_size_in_huge_pages",
+}
+
+type hugePageEstimate struct {
+	PageSize       uint64 // bytes
+	Pages          uint64 // pages needed with current shared_buffers
+	SuggestedPages uint64 // pages needed once shared_buffers suggestion is applied
+	Source         string // how the estimate was made
+}
+
+// Returns bytes of shared memory used by wal_buffers for given shared_buffers size
+func walBuffersBytes(walBuffers ResourceSetting, sharedBuffers float64) (float64, error) {
+	// -1 means 1/32 of shared_buffers, no less than 64kB and no more than 16MB
+	if walBuffers.Value == "-1" {
+		bytes := sharedBuffers / 32
+		if bytes < 64*1024 {
+			bytes = 64 * 1024
+		}
+		if bytes > 16*1024*1024 {
+			bytes = 16 * 1024 * 1024
+		}
+		return bytes, nil
+	}
+	bytes, err := utils.ConvertBasedOnUnit(walBuffers.Value, walBuffers.Unit, "B")
+	return float64(bytes), err
+}
+
+func pagesFor(bytes float64, pageSize uint64) uint64 {
+	if bytes <= 0 {
+		return 0
+	}
+	return (uint64(bytes) + pageSize - 1) / pageSize
+}
+
+// Estimates how many huge pages shared memory needs with current and suggested shared_buffers.
+// PostgreSQL 15+ reports the exact figure for current settings itself. On older versions
+// shared memory is estimated as shared_buffers + wal_buffers + 5% for lock tables and
+// other shared structures.
+func (conf *Configuration) estimateHugePages(logger *utils.Logger) (*hugePageEstimate, error) {
+	estimate := &hugePageEstimate{}
+
+	// 1. Huge page size. PostgreSQL uses the kernel default unless huge_page_size is set
+	hugePageSize := conf.settings["huge_page_size"]
+	if hugePageSize.Value != "" && hugePageSize.Value != "0" {
+		size, err := utils.ConvertBasedOnUnit(hugePageSize.Value, hugePageSize.Unit, "B")
+		if err != nil {
+			return nil, err
+		}
+		estimate.PageSize = uint64(size)
+	} else {
+		size, err := conf.getHugePageSize()
+		if err != nil {
+			return nil, err
+		}
+		estimate.PageSize = size
+	}
+	if estimate.PageSize == 0 {
+		return nil, fmt.Errorf("huge page size is 0")
+	}
+
+	// 2. Current and suggested shared_buffers in bytes
+	sharedBuffers := conf.settings["shared_buffers"]
+	current, err := utils.ConvertBasedOnUnit(sharedBuffers.Value, sharedBuffers.Unit, "B")
+	if err != nil {
+		return nil, err
+	}
+	suggested, err := utils.ConvertBasedOnUnit(suggestedOrCurrent(sharedBuffers), sharedBuffers.Unit, "B")
+	if err != nil {
+		return nil, err
+	}
+	currentWal, err := walBuffersBytes(conf.settings["wal_buffers"], float64(current))
+	if err != nil {
+		return nil, err
+	}
+	suggestedWal, err := walBuffersBytes(conf.settings["wal_buffers"], float64(suggested))
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Use figures reported by PostgreSQL where available. -1 means huge pages are not supported
+	if pages, ok := conf.settings["shared_memory_size_in_huge_pages"]; ok && pages.Value != "-1" {
+		currentPages, err := utils.StringToUint64(pages.Value)
+		if err != nil {
+			return nil, err
+		}
+		estimate.Pages = currentPages
+		estimate.Source = "shared_memory_size_in_huge_pages reported by PostgreSQL"
+
+		if size, ok := conf.settings["shared_memory_size"]; ok {
+			sizeBytes, err := utils.ConvertBasedOnUnit(size.Value, size.Unit, "B")
+			if err != nil {
+				return nil, err
+			}
+			suggestedBytes := float64(sizeBytes) - float64(current) - currentWal + float64(suggested) + suggestedWal
+			estimate.SuggestedPages = pagesFor(suggestedBytes, estimate.PageSize)
+		} else {
+			estimate.SuggestedPages = currentPages
+		}
+		return estimate, nil
+	}
+
+	estimate.Pages = pagesFor((float64(current)+currentWal)*1.05, estimate.PageSize)
+	estimate.SuggestedPages = pagesFor((float64(suggested)+suggestedWal)*1.05, estimate.PageSize)
+	estimate.Source = "estimated from shared_buffers and wal_buffers plus 5% for other shared structures"
+	return estimate, nil
+}
+
+// Sentence with huge pages needed and the vm.nr_hugepages recommendation
+func (estimate *hugePageEstimate) describe() string {
+	return fmt.Sprintf("Shared memory needs %d huge pages of %.0fkB with the current shared_buffers and %d once the shared_buffers suggestion is applied (%s). Kernel recommendation is vm.nr_hugepages = %d. ", estimate.Pages, float64(estimate.PageSize)/1024, estimate.SuggestedPages, estimate.Source, estimate.SuggestedPages)
+}
+
+// Returns a warning if transparent huge pages are set to `always`, empty string otherwise
+func (conf *Configuration) transparentHugePagesWarning(logger *utils.Logger) string {
+	mode, err := conf.getTransparentHugePages()
+	if err != nil {
+		logger.LogWarning(fmt.Errorf("could not check transparent huge pages: %v", err))
+		return ""
+	}
+	if mode != "always" {
+		return ""
+	}
+	return "Warning: transparent huge pages are set to \"always\". Background compaction done to build them is known to cause latency spikes and memory bloat for PostgreSQL. Recommendation is to set /sys/kernel/mm/transparent_hugepage/enabled to \"madvise\" or \"never\" and rely on huge pages reserved through vm.nr_hugepages instead. "
+}
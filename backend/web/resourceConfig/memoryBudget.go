@@ -0,0 +1,186 @@
+// Worst case memory budget calculator. Adds up the memory every configured
+// consumer is allowed to use at once, to see whether the configuration could
+// run the server out of memory if all connections and workers were busy.
+package resourceConfig
+
+import (
+	"fmt"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+// Settings the worst case memory budget is calculated from
+var memoryBudgetSettings = []string{
+	"shared_buffers",
+	"wal_buffers",
+	"max_connections",
+	"work_mem",
+	"hash_mem_multiplier",
+	"autovacuum_max_workers",
+	"autovacuum_work_mem",
+	"maintenance_work_mem",
+}
+
+// Returns setting value as it's used in the budget. With @useSuggestions, the
+// suggested value is used if the check for that setting made a suggestion.
+func (conf *Configuration) budgetSetting(name string, useSuggestions bool, logger *utils.Logger) (*ResourceSetting, error) {
+	setting, err := conf.getSpecificPGSetting(name, logger)
+	if err != nil {
+		return nil, err
+	}
+	if useSuggestions && setting.SuggestedValue != "" {
+		setting.Value = setting.SuggestedValue
+	}
+	return setting, nil
+}
+
+// Returns setting value converted to bytes
+func (conf *Configuration) budgetBytes(name string, useSuggestions bool, logger *utils.Logger) (float64, *ResourceSetting, error) {
+	setting, err := conf.budgetSetting(name, useSuggestions, logger)
+	if err != nil {
+		return 0, nil, err
+	}
+	bytes, err := utils.ConvertBasedOnUnit(setting.Value, setting.Unit, "B")
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not convert %s to bytes: %v", name, err)
+	}
+	return float64(bytes), setting, nil
+}
+
+// Returns setting value as a plain number
+func (conf *Configuration) budgetNumber(name string, useSuggestions bool, logger *utils.Logger) (float64, error) {
+	setting, err := conf.budgetSetting(name, useSuggestions, logger)
+	if err != nil {
+		return 0, err
+	}
+	return utils.StringToFloat64(setting.Value)
+}
+
+/*
+Calculates the worst case memory usage of the configuration:
+shared_buffers + wal_buffers + max_connections * work_mem * hash_mem_multiplier
++ autovacuum_max_workers * autovacuum_work_mem + maintenance_work_mem
+@useSuggestions - use suggested values instead of current ones where checks made suggestions
+*/
+func (conf *Configuration) CalculateMemoryBudget(useSuggestions bool, logger *utils.Logger) (*MemoryBudgetEstimate, error) {
+	memoryInfo, err := conf.getMemoryInfo(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed calculating memory budget: %v", err))
+		return nil, err
+	}
+	totalMemory := memoryInfo.Total
+
+	// 1. Shared memory
+	sharedBuffers, sharedBuffersSetting, err := conf.budgetBytes("shared_buffers", useSuggestions, logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed calculating memory budget: %v", err))
+		return nil, err
+	}
+	walBuffers, walBuffersSetting, err := conf.budgetBytes("wal_buffers", useSuggestions, logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed calculating memory budget: %v", err))
+		return nil, err
+	}
+	// -1 means 1/32 of shared_buffers, capped at 16MB
+	if walBuffers < 0 {
+		walBuffers = sharedBuffers / 32
+		if walBuffers > 16*1024*1024 {
+			walBuffers = 16 * 1024 * 1024
+		}
+	}
+
+	// 2. Memory used by connections, each of which may run a hash operation
+	maxConnections, err := conf.budgetNumber("max_connections", useSuggestions, logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed calculating memory budget: %v", err))
+		return nil, err
+	}
+	workMem, workMemSetting, err := conf.budgetBytes("work_mem", useSuggestions, logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed calculating memory budget: %v", err))
+		return nil, err
+	}
+	// Before PostgreSQL 13 hash operations were limited to work_mem as well
+	hashMemMultiplier := 1.0
+	if conf.serverVersion == 0 || conf.serverVersion >= 130000 {
+		hashMemMultiplier, err = conf.budgetNumber("hash_mem_multiplier", useSuggestions, logger)
+		if err != nil {
+			logger.LogError(fmt.Errorf("Failed calculating memory budget: %v", err))
+			return nil, err
+		}
+	}
+
+	// 3. Maintenance memory, autovacuum workers fall back to maintenance_work_mem if autovacuum_work_mem is -1
+	maintenanceWorkMem, maintenanceWorkMemSetting, err := conf.budgetBytes("maintenance_work_mem", useSuggestions, logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed calculating memory budget: %v", err))
+		return nil, err
+	}
+	autovacuumMaxWorkers, err := conf.budgetNumber("autovacuum_max_workers", useSuggestions, logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed calculating memory budget: %v", err))
+		return nil, err
+	}
+	autovacuumSetting, err := conf.budgetSetting("autovacuum_work_mem", useSuggestions, logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed calculating memory budget: %v", err))
+		return nil, err
+	}
+	autovacuumWorkMem := maintenanceWorkMem
+	autovacuumWorkMemLabel := fmt.Sprintf("maintenance_work_mem(%s%s)", maintenanceWorkMemSetting.Value, maintenanceWorkMemSetting.Unit)
+	if autovacuumSetting.Value != "-1" {
+		converted, err := utils.ConvertBasedOnUnit(autovacuumSetting.Value, autovacuumSetting.Unit, "B")
+		if err != nil {
+			logger.LogError(fmt.Errorf("Failed calculating memory budget: %v", err))
+			return nil, err
+		}
+		autovacuumWorkMem = float64(converted)
+		autovacuumWorkMemLabel = fmt.Sprintf("autovacuum_work_mem(%s%s)", autovacuumSetting.Value, autovacuumSetting.Unit)
+	}
+
+	// 4. Add everything up
+	items := []MemoryBudgetItem{
+		{
+			Name:    "shared_buffers",
+			Bytes:   int64(sharedBuffers),
+			Formula: fmt.Sprintf("shared_buffers(%s%s)", sharedBuffersSetting.Value, sharedBuffersSetting.Unit),
+		},
+		{
+			Name:    "wal_buffers",
+			Bytes:   int64(walBuffers),
+			Formula: fmt.Sprintf("wal_buffers(%s%s)", walBuffersSetting.Value, walBuffersSetting.Unit),
+		},
+		{
+			Name:    "connections",
+			Bytes:   int64(maxConnections * workMem * hashMemMultiplier),
+			Formula: fmt.Sprintf("max_connections(%.0f) * work_mem(%s%s) * hash_mem_multiplier(%g)", maxConnections, workMemSetting.Value, workMemSetting.Unit, hashMemMultiplier),
+		},
+		{
+			Name:    "autovacuum",
+			Bytes:   int64(autovacuumMaxWorkers * autovacuumWorkMem),
+			Formula: fmt.Sprintf("autovacuum_max_workers(%.0f) * %s", autovacuumMaxWorkers, autovacuumWorkMemLabel),
+		},
+		{
+			Name:    "maintenance",
+			Bytes:   int64(maintenanceWorkMem),
+			Formula: fmt.Sprintf("maintenance_work_mem(%s%s)", maintenanceWorkMemSetting.Value, maintenanceWorkMemSetting.Unit),
+		},
+	}
+	var worstCase int64
+	for _, item := range items {
+		worstCase += item.Bytes
+	}
+
+	budget := &MemoryBudgetEstimate{
+		TotalMemory:        int64(totalMemory),
+		WorstCase:          worstCase,
+		ExceedsTotalMemory: uint64(worstCase) > totalMemory,
+		Items:              items,
+	}
+	if budget.ExceedsTotalMemory {
+		budget.Details = fmt.Sprintf("If every connection ran a hash operation using its full work_mem while all autovacuum workers and a maintenance operation were running, PostgreSQL could use %.0fMB, which is more than the memory it is allowed to use (%.0fMB, from %s). Consider lowering max_connections (a connection pooler helps here) or work_mem.", float64(worstCase)/1024/1024, float64(totalMemory)/1024/1024, memoryInfo.Source)
+	} else {
+		budget.Details = fmt.Sprintf("Worst case memory usage is %.0fMB out of %.0fMB total memory (from %s).", float64(worstCase)/1024/1024, float64(totalMemory)/1024/1024, memoryInfo.Source)
+	}
+	return budget, nil
+}
@@ -0,0 +1,586 @@
+// Memory related checks. These were the original set of checks and cover
+// shared memory, huge pages and per-operation working memory settings.
+package resourceConfig
+
+import (
+	"fmt"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+func init() {
+	RegisterCheck(&checkDefinition{name: "shared_buffers", category: CategoryMemory, run: (*Configuration).CheckSharedBuffers})
+	RegisterCheck(&checkDefinition{name: "huge_pages", category: CategoryMemory, minVersion: 90400, requiredSettings: hugePageSettings, dependsOn: []string{"shared_buffers"}, run: (*Configuration).CheckHugePages})
+	RegisterCheck(&checkDefinition{name: "huge_page_size", category: CategoryMemory, minVersion: 140000, run: (*Configuration).CheckHugePageSize})
+	RegisterCheck(&checkDefinition{name: "temp_buffers", category: CategoryMemory, run: (*Configuration).CheckTempBuffers})
+	RegisterCheck(&checkDefinition{name: "max_prepared_transactions", category: CategoryMemory, requiredSettings: []string{"max_connections"}, run: (*Configuration).CheckMaxPreparedTransactions})
+	RegisterCheck(&checkDefinition{name: "work_mem", category: CategoryMemory, requiredSettings: []string{"max_connections"}, run: (*Configuration).CheckWorkMem})
+	RegisterCheck(&checkDefinition{name: "hash_mem_multiplier", category: CategoryMemory, minVersion: 130000, defaults: []versionDefault{{0, "1"}, {150000, "2"}}, requiredSettings: []string{"work_mem"}, dependsOn: []string{"work_mem"}, run: (*Configuration).CheckHashMemMultiplier})
+	RegisterCheck(&checkDefinition{name: "maintenance_work_mem", category: CategoryMemory, requiredSettings: []string{"autovacuum_max_workers"}, run: (*Configuration).CheckMaintenanceWorkMem})
+	RegisterCheck(&checkDefinition{name: "autovacuum_work_mem", category: CategoryMemory, minVersion: 90400, requiredSettings: []string{"autovacuum_max_workers"}, run: (*Configuration).CheckAutovacuumWorkMem})
+	RegisterCheck(&checkDefinition{name: "logical_decoding_work_mem", category: CategoryMemory, minVersion: 130000, run: (*Configuration).ChecklogicalDecodingWorkMem})
+	RegisterCheck(&checkDefinition{name: "max_stack_depth", category: CategoryMemory, run: (*Configuration).CheckMaxStackDepth})
+	RegisterCheck(&checkDefinition{name: "shared_memory_type", category: CategoryMemory, minVersion: 120000, run: (*Configuration).CheckSharedMemoryType})
+	RegisterCheck(&checkDefinition{name: "dynamic_shared_memory_type", category: CategoryMemory, minVersion: 90400, run: (*Configuration).CheckDynamicSharedMemoryType})
+}
+
+// checks what unit is used for shared_buffers, applies necessary conversions
+// and sets final suggestion as a shared_buffers unit to closest value that's power of 2
+func (conf *Configuration) CheckSharedBuffers(logger *utils.Logger) (*ResourceSetting, error) {
+	var suggestion float32
+	var lowestRecommendedValue float32 = 128 // Cannot suggest value that is lower than 128MB
+	var GigabyteInBytes uint64 = 1073741824  // 1GB
+	sharedBuffers := conf.settings["shared_buffers"]
+	sharedBuffers.Details = "This setting sets the amount of memory the database server uses for shared memory buffers. "
+
+	// 1. Get total server memory, or the container limit if PostgreSQL runs in one
+	memoryInfo, err := conf.getMemoryInfo(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed shared_buffers check because could not get total server memory: %v", err))
+		sharedBuffers.GotError = true
+		return nil, err
+	}
+	totalMemory := memoryInfo.Total
+	sharedBuffers.Details += memoryInfo.Describe()
+
+	// 2. Convert total server memory to a unit that's used by shared_buffers
+	totalMemoryAsString := utils.Uint64ToString(totalMemory)
+	totalMemoryConverted, err := utils.ConvertBasedOnUnit(totalMemoryAsString, "B", sharedBuffers.Unit)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed shared_buffers check: %v", err))
+		sharedBuffers.GotError = true
+		return nil, err
+	}
+
+	// 3. Suggest value that's n% memory depending on how much total and free memory we have
+	//
+	// If total server memory > 1GB, suggest share of total server RAM set by workload profile
+	profile := conf.Profile()
+	if totalMemory > GigabyteInBytes {
+		suggestion = totalMemoryConverted * profile.SharedBuffersRatio
+		sharedBuffers.Details += fmt.Sprintf("Current total server memory is more than 1GB. Suggestion is to use %g%% of total server RAM.", profile.SharedBuffersRatio*100)
+		sharedBuffers.Details += profile.describe()
+	} else { // Else suggest 30% of what memory is currently available on the server
+		availableMemoryAsString := utils.Uint64ToString(memoryInfo.Available)
+		availableMemoryConverted, err := utils.ConvertBasedOnUnit(availableMemoryAsString, "B", sharedBuffers.Unit)
+		if err != nil {
+			logger.LogError(fmt.Errorf("Failed shared_buffers check: %v", err))
+			sharedBuffers.GotError = true
+			return nil, err
+		}
+		suggestion = availableMemoryConverted * 0.30
+		sharedBuffers.Details += "Current total server memory is less than 1GB. Suggestion is to use 30% of available RAM"
+
+		// Suggested value cannot be lower than 128MB
+		lowestRecommendedValueAsString := utils.Float32ToString(lowestRecommendedValue)
+		lowestRecommendedValue, err = utils.ConvertBasedOnUnit(lowestRecommendedValueAsString, "MB", sharedBuffers.Unit)
+		if err != nil {
+			logger.LogError(fmt.Errorf("Failed shared_buffers check: %v", err))
+			sharedBuffers.GotError = true
+			return nil, err
+		}
+		if suggestion < lowestRecommendedValue {
+			suggestion = lowestRecommendedValue
+			sharedBuffers.Details += "Server lacks available memory. Suggestion is to use the lowest recommended value (which is 128MB)"
+		}
+	}
+
+	// Round suggestion to power of 2 and make sure there's no decimal point
+	roundedSuggestion := utils.RoundToPowerOf2(uint64(suggestion))
+	sharedBuffers.SuggestedValue = utils.Uint64ToString(roundedSuggestion)
+	resetSuggestionIfEqual(&sharedBuffers)
+	conf.settings["shared_buffers"] = sharedBuffers
+	return &sharedBuffers, err
+}
+
+func (conf *Configuration) CheckHugePages(logger *utils.Logger) (*ResourceSetting, error) {
+	hugePages := conf.settings["huge_pages"]
+	hugePages.Details = "This setting controls whether huge pages are requested for the main shared memory area. "
+
+	// Get nr_hugepages value
+	kernelNrHugePages, err := conf.getKernelHugePages()
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed huge_pages check: %v", err))
+		hugePages.GotError = true
+		return nil, err
+	}
+
+	// Work out how many huge pages shared memory needs. Not being able to
+	// is not an error, the kernel may simply not support huge pages
+	estimate, err := conf.estimateHugePages(logger)
+	if err != nil {
+		logger.LogWarning(fmt.Errorf("could not estimate huge pages needed: %v", err))
+	}
+	thpWarning := conf.transparentHugePagesWarning(logger)
+
+	// if it's not set in kernel, no point in having hugePages set to on/try
+	if kernelNrHugePages == 0 {
+		hugePages.Details += "System's kernel parameter nr_hugepages is set to 0. Because of that, PostgreSQL cannot request huge pages. Suggestion is to turn off hugePages in postgresql configuration as well. "
+		if estimate != nil {
+			hugePages.Details += "To use huge pages instead, reserve them in the kernel first. " + estimate.describe()
+		}
+		hugePages.Details += thpWarning
+		setEnumTypeSuggestedValue(&hugePages, "off")
+
+		resetSuggestionIfEqual(&hugePages)
+		conf.settings["huge_pages"] = hugePages
+		return &hugePages, nil
+	}
+
+	// Too few reserved pages make "on" fail at startup and "try" silently fall back to normal pages
+	if estimate != nil {
+		if uint64(kernelNrHugePages) < estimate.SuggestedPages {
+			hugePages.Details += fmt.Sprintf("System's kernel parameter nr_hugepages (%d) is lower than the number of huge pages shared memory needs. With huge_pages set to \"on\" the server will fail to start, with \"try\" it will not use huge pages at all. ", kernelNrHugePages)
+		}
+		hugePages.Details += estimate.describe()
+	}
+
+	if hugePages.Value == "on" {
+		hugePages.Details += "Postgresql's configuration parameter \"huge_pages\" current value is equal to 'on'. In case requesting huge pages results in failure, it will prevent the server from starting up. Suggestion is to use the \"try\" option. With huge_pages set to try, the server will try to request huge pages, but fall back to the default if that fails. "
+		setEnumTypeSuggestedValue(&hugePages, "try")
+	} else if hugePages.Value == "off" {
+		hugePages.Details += "Postgresql's configuration parameter \"huge_pages\" current value is equal to 'off'. Usage of huge pages results in smaller page tables and less CPU time spent on memory management, resulting in increased performance. Suggestion is to use the \"try\" option so that huge_pages are turned on but can fallback in case of failure using them. "
+		setEnumTypeSuggestedValue(&hugePages, "try")
+	}
+	hugePages.Details += thpWarning
+
+	resetSuggestionIfEqual(&hugePages)
+	// If kernelNrHugePages > 0 and hugePages.SuggestedValue = "try", make no suggestions
+	conf.settings["huge_pages"] = hugePages
+	return &hugePages, nil
+}
+
+func (conf *Configuration) CheckHugePageSize(logger *utils.Logger) (*ResourceSetting, error) {
+	hugePageSize := conf.settings["huge_page_size"]
+	hugePageSize.Details = "This setting controls the size of huge pages, when they are enabled with huge_pages. "
+
+	// Get nr_hugepages value
+	kernelNrHugePages, err := conf.getKernelHugePages()
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed huge_page_size check: %v", err))
+		hugePageSize.GotError = true
+		return nil, err
+	}
+
+	// If vm.nr_hugepages is 0, then huge_page_size cannot be set
+	if kernelNrHugePages == 0 {
+		conf.settings["huge_page_size"] = hugePageSize
+		return &hugePageSize, nil
+	}
+
+	if hugePageSize.Value != "0" {
+		hugePageSize.SuggestedValue = "0"
+		hugePageSize.Details += "Current PostgreSQL configuration parameter \"huge_page_size\" value is set to a non 0 value. To prevent fragmentation, the same huge page size as the one set in your Linux kernel should be used. When set to 0, the default huge page size on the system will be used. Suggestion is to set \"huge_page_size\" to 0."
+	}
+
+	resetSuggestionIfEqual(&hugePageSize)
+	conf.settings["huge_page_size"] = hugePageSize
+	return &hugePageSize, nil
+}
+
+// GENERALREC
+func (conf *Configuration) CheckTempBuffers(logger *utils.Logger) (*ResourceSetting, error) {
+	tempBuffers := conf.settings["temp_buffers"]
+	tempBuffers.Details = "This setting sets the maximum amount of memory used for temporary buffers within each database session. These are session-local buffers used only for access to temporary tables. "
+
+	currentValue, err := utils.StringToUint64(tempBuffers.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed temp_buffers check: %v", err))
+		tempBuffers.GotError = true
+		return nil, err
+	}
+	currentValueAsString := utils.Uint64ToString(currentValue)
+	currentValueConverted, err := utils.ConvertBasedOnUnit(currentValueAsString, "8kB", tempBuffers.Unit)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed temp_buffers check: %v", err))
+		tempBuffers.GotError = true
+		return nil, err
+	}
+	if currentValueConverted > 1024 { // 1024 8kB is equivalent to 8MB
+		tempBuffers.Details += "Current PostgreSQL configuration parameter \"temp_buffers\" value is set to more than 8MB. If there are multiple databases used by different applications, consider changing this setting per database. It is recommended to increase this value only for applications that rely heavily on temporary tables. Suggestion is to increase this only if the application relies heavily on temporary tables."
+	} else if currentValueConverted < 1024 {
+		tempBuffers.Details += "Current PostgreSQL configuration parameter \"temp_buffers\" value is set to less than 8MB. The cost of setting a large value in sessions that do not actually need many temporary buffers is only a buffer descriptor, or about 64 bytes. Suggestion is to increase this to the recommended default value."
+
+		convertedDefault, err := utils.ConvertBasedOnUnit("1024", "8kB", tempBuffers.Unit)
+		if err != nil {
+			logger.LogError(fmt.Errorf("Failed temp_buffers check: %v", err))
+			tempBuffers.GotError = true
+			return nil, err
+		}
+		tempBuffers.SuggestedValue = utils.Float32ToString(convertedDefault)
+	}
+
+	resetSuggestionIfEqual(&tempBuffers)
+	conf.settings["temp_buffers"] = tempBuffers
+	return &tempBuffers, nil
+}
+
+// GENERALREC
+func (conf *Configuration) CheckMaxPreparedTransactions(logger *utils.Logger) (*ResourceSetting, error) {
+	maxPreparedTransactions := conf.settings["max_prepared_transactions"]
+	maxPreparedTransactions.Details = "This setting sets the maximum number of transactions that can be in the \"prepared\" state simultaneously. "
+
+	// !!! consider passing this as an argument because there are currently two
+	// separate functions that ask for max_connections
+	maxConnections, err := conf.getSpecificPGSetting("max_connections", logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed max_prepared_transactions check: %v", err))
+		maxPreparedTransactions.GotError = true
+		return nil, err
+	}
+
+	if maxPreparedTransactions.Value == "0" {
+		maxPreparedTransactions.Details += "Current PostgreSQL configuration parameter \"max_prepared_transactions\" value is set to 0. If you are using prepared transactions, you will probably want max_prepared_transactions to be at least as large as another another configuration parameter \"max_connections\", so that every session can have a prepared transaction pending. Suggestion is to set \"max_prepared_transactions\" to the same value as \"max_connections\"."
+		maxPreparedTransactions.SuggestedValue = maxConnections.Value
+	}
+
+	resetSuggestionIfEqual(&maxPreparedTransactions)
+	conf.settings["max_prepared_transactions"] = maxPreparedTransactions
+	return &maxPreparedTransactions, nil
+}
+
+func (conf *Configuration) CheckWorkMem(logger *utils.Logger) (*ResourceSetting, error) {
+	workMem := conf.settings["work_mem"]
+	workMem.Details = "This setting sets the base maximum amount of memory to be used by a query operation (such as a sort or hash table) before writing to temporary disk files. "
+
+	// 1. Get amount of available memory and connections
+	memoryInfo, err := conf.getMemoryInfo(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed work_mem check: %v", err))
+		workMem.GotError = true
+		return nil, err
+	}
+	availableMemory := memoryInfo.Available
+	workMem.Details += memoryInfo.Describe()
+	maxConnections, err := conf.getSpecificPGSetting("max_connections", logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed work_mem check: %v", err))
+		workMem.GotError = true
+		return nil, err
+	}
+
+	// 2. suggestion = availablememory / max_connections * multiplier of workload profile
+	profile := conf.Profile()
+	maxConnectionsValue, err := utils.StringToUint64(maxConnections.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed work_mem check: %v", err))
+		workMem.GotError = true
+		return nil, err
+	}
+	suggestion := utils.Uint64ToString(utils.RoundToPowerOf2(uint64(float32(availableMemory/maxConnectionsValue) * profile.WorkMemMultiplier)))
+	suggestionAsWorkMemUnit, err := utils.ConvertBasedOnUnit(suggestion, "B", workMem.Unit)
+	workMem.SuggestedValue = utils.Float32ToString(suggestionAsWorkMemUnit)
+
+	// 3. Add details for decision
+	workMem.Details += fmt.Sprintf("Suggested value is based on currently available memory on the server divided by another configuration parameter \"max_connections\" and multiplied by %g. If using complex queries that involve sorts or hash tables, consider using double this value. It can also be set higher if this server is a dedicated database server and there is no concern that other software will run out of memory.", profile.WorkMemMultiplier)
+	workMem.Details += profile.describe()
+
+	resetSuggestionIfEqual(&workMem)
+	conf.settings["work_mem"] = workMem
+	return &workMem, nil
+}
+
+// GENERALREC
+func (conf *Configuration) CheckHashMemMultiplier(logger *utils.Logger) (*ResourceSetting, error) {
+	hashMemMultiplier := conf.settings["hash_mem_multiplier"]
+	hashMemMultiplier.Details = "This setting is used to compute the maximum amount of memory that hash-based operations can use. "
+
+	workMem := conf.settings["work_mem"]
+	workMemValueAsMB, err := utils.ConvertBasedOnUnit(workMem.Value, workMem.Unit, "MB")
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed hash_mem_multiplier check: %v", err))
+		hashMemMultiplier.GotError = true
+		return nil, err
+	}
+
+	// If more than 40MB
+	if workMemValueAsMB > 40 {
+		hashMemMultiplierAsFloat32, err := utils.StringToFloat32(hashMemMultiplier.Value)
+		if err != nil {
+			logger.LogError(fmt.Errorf("Failed hash_mem_multiplier check: %v", err))
+			hashMemMultiplier.GotError = true
+			return nil, err
+		}
+		suggestion := (hashMemMultiplierAsFloat32 + workMemValueAsMB*0.01)
+		if suggestion > 8 {
+			suggestion = 8
+		}
+		hashMemMultiplier.Details += "\"hash_mem_multiplier\" recommendations depend on \"work_mem\". Often times, it is best to set \"hash_mem_multiplier\" to its default value. If your application uses hash-based operations and PostgreSQL often ends up spilling (creates workfiles on disk to compensate for lack of memory), it should be increased further. However, current PostgreSQL configuration parameter \"work_mem\" is set to more than 40MB, allowing to increase this further. Suggestion here is based on how much working memory is currently set."
+		hashMemMultiplier.SuggestedValue = utils.Float32ToString(suggestion)
+	} else if defaultValue := conf.defaultValue("hash_mem_multiplier"); hashMemMultiplier.Value != defaultValue {
+		// Default changed from 1 to 2 in PostgreSQL 15
+		hashMemMultiplier.Details += fmt.Sprintf("\"hash_mem_multiplier\" recommendations depend on \"work_mem\". Because \"work_mem\" is set to less than 40MB, \"hash_mem_multiplier\" should not be set higher than the default (%s on this PostgreSQL version). Generally default value works best. If your application uses hash-based operations and PostgreSQL often ends up spilling (creates workfiles on disk to compensate for lack of memory), consider increasing this after having increased work_mem above 40MB.", defaultValue)
+		hashMemMultiplier.SuggestedValue = defaultValue
+	}
+
+	resetSuggestionIfEqual(&hashMemMultiplier)
+	conf.settings["hash_mem_multiplier"] = hashMemMultiplier
+	return &hashMemMultiplier, nil
+}
+
+func (conf *Configuration) CheckMaintenanceWorkMem(logger *utils.Logger) (*ResourceSetting, error) {
+	// 1. Get maintenance_work_mem, autovacumm_max_workers and available memory on server
+	maintenanceWorkMem := conf.settings["maintenance_work_mem"]
+	maintenanceWorkMem.Details = "This setting specifies the maximum amount of memory to be used by maintenance operations, such as VACUUM, CREATE INDEX, and ALTER TABLE ADD FOREIGN KEY. "
+
+	autovacuumMaxWorkers, availableMem, err := conf.getWorkMemRelatedValues(logger, &maintenanceWorkMem)
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed maintenance_work_mem check: %v", err))
+		maintenanceWorkMem.GotError = true
+		return nil, err
+	}
+
+	// 2. Divide available memory by profile divisor * autovacuum_max_workers and round to nearest power of 2
+	profile := conf.Profile()
+	suggestion := availableMem / profile.MaintenanceWorkMemDivisor / autovacuumMaxWorkers
+	suggestionRounded := utils.RoundToPowerOf2(uint64(suggestion))
+
+	maintenanceWorkMem.Details += fmt.Sprintf("This suggestion was made by dividing current available memory(%.2f%s) by %g and by how many autovacuum_max_workers(%.0f) are set. Applications that heavily rely on maintenance operations, such as VACUUM, CREATE INDEX, and ALTER TABLE ADD FOREIGN KEY may want to increase this further by multiplying the suggested value by 2.", availableMem, maintenanceWorkMem.Unit, profile.MaintenanceWorkMemDivisor, autovacuumMaxWorkers)
+	maintenanceWorkMem.Details += profile.describe() + " "
+	maintenanceWorkMem.SuggestedValue = utils.Uint64ToString(suggestionRounded)
+
+	// 3. If suggestion is below default value and current value is not equal to default,
+	// suggest default value instead.
+
+	suggestionAsMB, err := utils.ConvertBasedOnUnit(utils.Uint64ToString(suggestionRounded), maintenanceWorkMem.Unit, "MB")
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed maintenance_work_mem check: %v", err))
+		maintenanceWorkMem.GotError = true
+		return nil, err
+	}
+
+	if suggestionAsMB < 64 {
+		maintenanceWorkMemAsMB, err := utils.ConvertBasedOnUnit(maintenanceWorkMem.Value, maintenanceWorkMem.Unit, "MB")
+		if err != nil {
+			logger.LogError(fmt.Errorf("Failed maintenance_work_mem check: %v", err))
+			maintenanceWorkMem.GotError = true
+			return nil, err
+		}
+		if maintenanceWorkMemAsMB != 64 {
+			maintenanceWorkMem.Details += "Currently there is not enough available memory on the server to go above default maintenance_work_mem value. Suggestion is to set this setting to the default value."
+			defaultAsUnit, err := utils.ConvertBasedOnUnit("64", "MB", maintenanceWorkMem.Unit)
+			if err != nil {
+				logger.LogError(fmt.Errorf("Failed maintenance_work_mem check: %v", err))
+				maintenanceWorkMem.GotError = true
+				return nil, err
+			}
+			maintenanceWorkMem.SuggestedValue = utils.Float32ToString(defaultAsUnit)
+		} else { // if suggestion was below default and current value is already set to default
+			maintenanceWorkMem.SuggestedValue = ""
+		}
+	}
+
+	resetSuggestionIfEqual(&maintenanceWorkMem)
+	conf.settings["maintenance_work_mem"] = maintenanceWorkMem
+	return &maintenanceWorkMem, nil
+}
+
+func (conf *Configuration) CheckAutovacuumWorkMem(logger *utils.Logger) (*ResourceSetting, error) {
+	// 1. Get autovacuum_work_mem, autovacumm_max_workers and available memory on server
+	autovacuumWorkMem := conf.settings["autovacuum_work_mem"]
+	autovacuumWorkMem.Details = "This setting specifies the maximum amount of memory to be used by each autovacuum worker process. "
+
+	autovacuumMaxWorkers, availableMem, err := conf.getWorkMemRelatedValues(logger, &autovacuumWorkMem)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed autovacuum_work_mem check: %v", err))
+		autovacuumWorkMem.GotError = true
+		return nil, err
+	}
+
+	// 2. Divide available memory by profile divisor * autovacuum_max_workers and round to nearest power of 2
+	profile := conf.Profile()
+	suggestion := availableMem / profile.AutovacuumWorkMemDivisor / autovacuumMaxWorkers
+	suggestionRounded := utils.RoundToPowerOf2(uint64(suggestion))
+
+	autovacuumWorkMem.Details += fmt.Sprintf("This suggestion was made by dividing current available memory(%.2f%s) by %g and by how many autovacuum_max_workers(%.0f) are set.", availableMem, autovacuumWorkMem.Unit, profile.AutovacuumWorkMemDivisor, autovacuumMaxWorkers)
+	autovacuumWorkMem.Details += profile.describe() + " "
+	autovacuumWorkMem.SuggestedValue = utils.Uint64ToString(suggestionRounded)
+
+	// 3. If suggestion is below default of maintenance_work_mem value
+	// and current value is not already -1, suggest -1 instead.
+
+	suggestionAsMB, err := utils.ConvertBasedOnUnit(utils.Uint64ToString(suggestionRounded), autovacuumWorkMem.Unit, "MB")
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed autovacuum_work_mem check: %v", err))
+		autovacuumWorkMem.GotError = true
+		return nil, err
+	}
+
+	if suggestionAsMB < 64 && autovacuumWorkMem.Value != "-1" {
+		defaultAsUnit, err := utils.ConvertBasedOnUnit("64", "MB", autovacuumWorkMem.Unit)
+		if err != nil {
+			logger.LogError(fmt.Errorf("Failed autovacuum_work_mem check: %v", err))
+			autovacuumWorkMem.GotError = true
+			return nil, err
+		}
+		autovacuumWorkMem.Details += "Currently there is not enough available memory on the server to go above default autovacuum_work_mem value. Suggestion is to set this setting -1 so it will rely on another configuration parameter (\"maintenance_work_mem\") instead."
+		autovacuumWorkMem.SuggestedValue = utils.Float32ToString(defaultAsUnit)
+	} else if suggestionAsMB < 64 { // if already set to -1, don't suggest anything
+		autovacuumWorkMem.SuggestedValue = ""
+	}
+
+	resetSuggestionIfEqual(&autovacuumWorkMem)
+	conf.settings["autovacuum_work_mem"] = autovacuumWorkMem
+	return &autovacuumWorkMem, nil
+}
+
+// Function for getting `autovacuum_max_workers` and available memory on server
+// to later be used in checks for `maintenance_work_mem` and `autovacuum_work_mem`
+func (conf *Configuration) getWorkMemRelatedValues(logger *utils.Logger, setting *ResourceSetting) (float32, float32, error) {
+	// 1. Get autovacuum_max_workers
+	tmpMaxWorkers, err := conf.getSpecificPGSetting("autovacuum_max_workers", logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed getting autovacuum_max_workers: %v", err))
+		tmpMaxWorkers.GotError = true
+		return 0, 0, err
+	}
+	autovacuumMaxWorkers, err := utils.StringToFloat32(tmpMaxWorkers.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed getting autovacuum_max_workers: %v", err))
+		tmpMaxWorkers.GotError = true
+		return 0, 0, err
+	}
+
+	// 2. Get available memory on server
+	memoryInfo, err := conf.getMemoryInfo(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed getting available memory on server check: %v", err))
+		tmpMaxWorkers.GotError = true
+		return 0, 0, err
+	}
+	setting.Details += memoryInfo.Describe()
+	availableMemoryAsString := utils.Uint64ToString(memoryInfo.Available)
+	availableMemoryConverted, err := utils.ConvertBasedOnUnit(availableMemoryAsString, "B", setting.Unit)
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed getting available memory on server check: %v", err))
+		tmpMaxWorkers.GotError = true
+		return 0, 0, err
+	}
+
+	return autovacuumMaxWorkers, availableMemoryConverted, nil
+}
+
+func (conf *Configuration) ChecklogicalDecodingWorkMem(logger *utils.Logger) (*ResourceSetting, error) {
+	logicalDecodingWorkMem := conf.settings["logical_decoding_work_mem"]
+	logicalDecodingWorkMem.Details = "This setting specifies the maximum amount of memory to be used by logical decoding, before some of the decoded changes are written to local disk. "
+
+	// 1. Get available memory on server
+	memoryInfo, err := conf.getMemoryInfo(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed logical_decoding_work_mem check: %v", err))
+		logicalDecodingWorkMem.GotError = true
+		return nil, err
+	}
+	availableMemoryAsString := utils.Uint64ToString(memoryInfo.Available)
+	availableMemoryConverted, err := utils.ConvertBasedOnUnit(availableMemoryAsString, "B", logicalDecodingWorkMem.Unit)
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed logical_decoding_work_mem check: %v", err))
+		logicalDecodingWorkMem.GotError = true
+		return nil, err
+	}
+
+	// 2. make suggestion by dividing available memory by 8 and rounding to nearest power of 2
+	suggestion := availableMemoryConverted / 8
+	suggestionRounded := utils.RoundToPowerOf2(uint64(suggestion))
+
+	// 3. If suggested value is less than 64MB, make no suggestion
+	suggestionAsMB, err := utils.ConvertBasedOnUnit(utils.Uint64ToString(suggestionRounded), logicalDecodingWorkMem.Unit, "MB")
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed logicalDecodingWorkMem check: %v", err))
+		logicalDecodingWorkMem.GotError = true
+		return nil, err
+	}
+
+	if !(suggestionAsMB < 64) {
+		logicalDecodingWorkMem.Details += memoryInfo.Describe()
+		logicalDecodingWorkMem.Details += fmt.Sprintf("There is enough available memory on the server to increase this setting further than the default. Suggestion is to set this to the value calculated after dividing current available memory(%.2f) by 8", availableMemoryConverted)
+		logicalDecodingWorkMem.SuggestedValue = utils.Uint64ToString(suggestionRounded)
+	}
+
+	resetSuggestionIfEqual(&logicalDecodingWorkMem)
+	conf.settings["logical_decoding_work_mem"] = logicalDecodingWorkMem
+	return &logicalDecodingWorkMem, nil
+}
+
+func (conf *Configuration) CheckMaxStackDepth(logger *utils.Logger) (*ResourceSetting, error) {
+	maxStackDepth := conf.settings["max_stack_depth"]
+	maxStackDepth.Details = "This setting specifies the maximum safe depth of the server's execution stack. "
+
+	// 1. Get system stack depth
+	systemStackDepth, err := conf.getStackSize()
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed max_stack_depth check: %v", err))
+		maxStackDepth.GotError = true
+		return nil, err
+	}
+
+	// 2. System set stack depth is not equal to max_stack_depth, suggest system stack depth
+	systemStackDepthAsUnit, err := utils.ConvertBasedOnUnit(utils.Uint64ToString(systemStackDepth), "B", maxStackDepth.Unit)
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed max_stack_depth check: %v", err))
+		maxStackDepth.GotError = true
+		return nil, err
+	}
+
+	maxStackDepthFloat32, err := utils.StringToFloat32(maxStackDepth.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed max_stack_depth check: %v", err))
+		maxStackDepth.GotError = true
+		return nil, err
+	}
+
+	if systemStackDepthAsUnit != maxStackDepthFloat32 {
+		maxStackDepth.SuggestedValue = utils.Float32ToString(systemStackDepthAsUnit)
+		maxStackDepth.Details += "The ideal setting for this parameter is the actual stack size limit enforced by the kernel (as set by ulimit -s or local equivalent). Suggestion is to set this to the kernel stack size limit"
+	}
+
+	/* For whatever reason, it's impossible to apply 8192 maxStackDepth value on the system I tested with.
+	This is in spite of the fact that it's what the PostgreSql documentation suggests (that it should ideally
+  be the same as the system stack depth) and despite the fact that it doesn't exceed max_val as returned
+	by `pg_settings`.
+	Temporary work-around is to cap it out at 4096. I think this is a bug with PostgreSql itself.
+	*/
+	if maxStackDepth.SuggestedValue > "4096" {
+		maxStackDepth.SuggestedValue = "4096"
+	}
+
+	resetSuggestionIfEqual(&maxStackDepth)
+	conf.settings["max_stack_depth"] = maxStackDepth
+	return &maxStackDepth, nil
+}
+
+func (conf *Configuration) CheckSharedMemoryType(logger *utils.Logger) (*ResourceSetting, error) {
+	sharedMemoryType := conf.settings["shared_memory_type"]
+	sharedMemoryType.Details = "This setting specifies the shared memory implementation that the server should use for the main shared memory region that holds PostgreSQL's shared buffers and other shared data. "
+
+	// Suggest boot_val (default) value
+	details := "sysv option is discouraged because it typically requires non-default kernel settings to allow for large allocations. Suggestion is to set this to the default."
+	err := conf.suggestDefault(&sharedMemoryType, details)
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed shared_memory_type check: %v", err))
+		sharedMemoryType.GotError = true
+		return nil, err
+	}
+
+	resetSuggestionIfEqual(&sharedMemoryType)
+	conf.settings["shared_memory_type"] = sharedMemoryType
+	return &sharedMemoryType, nil
+}
+
+func (conf *Configuration) CheckDynamicSharedMemoryType(logger *utils.Logger) (*ResourceSetting, error) {
+	dynamicSharedMemoryType := conf.settings["dynamic_shared_memory_type"]
+	dynamicSharedMemoryType.Details = "This setting specifies the dynamic shared memory implementation that the server should use. "
+
+	// Suggest boot_val (default) value
+	details := "Typically default value is best for this option. Suggestion is to set this to the default"
+	err := conf.suggestDefault(&dynamicSharedMemoryType, details)
+	if err != nil {
+		logger.LogError(fmt.Errorf("failed dynamic_shared_memory_type check: %v", err))
+		dynamicSharedMemoryType.GotError = true
+		return nil, err
+	}
+
+	resetSuggestionIfEqual(&dynamicSharedMemoryType)
+	conf.settings["dynamic_shared_memory_type"] = dynamicSharedMemoryType
+	return &dynamicSharedMemoryType, nil
+}
@@ -0,0 +1,253 @@
+// Offline analysis of configuration files. Settings are read from a
+// postgresql.conf (and postgresql.auto.conf) instead of `pg_settings` and
+// memory, CPU and storage come from a description of the hardware the
+// configuration is meant for. Checks that need statistics of a running
+// server are skipped.
+package resourceConfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+// Machine a configuration is analysed for. Sizes accept PostgreSQL style units, e.g. `16GB`.
+type Hardware struct {
+	Memory               string `json:"memory"`               // total memory, required
+	AvailableMemory      string `json:"available_memory"`     // defaults to total memory minus shared_buffers
+	Cpus                 int    `json:"cpus"`                 // cores available to PostgreSQL, required
+	Disk                 string `json:"disk"`                 // ssd, hdd or unknown
+	NrHugePages          int    `json:"nr_hugepages"`         // huge pages reserved in the kernel
+	HugePageSize         string `json:"huge_page_size"`       // defaults to 2MB
+	TransparentHugePages string `json:"transparent_hugepage"` // always, madvise or never. Not checked if empty
+	StackSize            string `json:"stack_size"`           // `ulimit -s` of the server, defaults to 8MB
+}
+
+// Files and hardware to run an offline analysis for
+type OfflineSource struct {
+	ConfigFile     string // postgresql.conf to analyse
+	AutoConfigFile string // read after ConfigFile. Defaults to postgresql.auto.conf next to it if that exists
+	Hardware       *Hardware
+	ServerVersion  int    // server_version_num the configuration is meant for, 0 if unknown
+	Profile        string // workload profile, default profile if empty
+}
+
+// Parsed hardware description and settings used in place of the host and `pg_settings`
+type offlineEnvironment struct {
+	settings             map[string]ResourceSetting // settings read from configuration files
+	entries              []utils.ConfigEntry        // every entry of the configuration files, in the order they apply
+	totalMemory          uint64                     // bytes
+	availableMemory      uint64                     // bytes, 0 to derive from shared_buffers
+	cpus                 int
+	storage              utils.StorageType
+	nrHugePages          int
+	hugePageSize         uint64 // bytes
+	transparentHugePages string
+	stackSize            uint64 // bytes
+}
+
+// Reads a hardware description from a JSON file. Unknown fields are rejected to catch typos.
+func LoadHardware(path string) (*Hardware, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read hardware description: %v", err)
+	}
+
+	hardware := &Hardware{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(hardware); err != nil {
+		return nil, fmt.Errorf("could not parse hardware description %s: %v", path, err)
+	}
+	return hardware, nil
+}
+
+// Validates hardware description and converts sizes to bytes
+func newOfflineEnvironment(hardware *Hardware) (*offlineEnvironment, error) {
+	if hardware == nil || hardware.Memory == "" {
+		return nil, fmt.Errorf("hardware description needs total memory")
+	}
+	if hardware.Cpus <= 0 {
+		return nil, fmt.Errorf("hardware description needs a positive number of CPUs")
+	}
+
+	env := &offlineEnvironment{cpus: hardware.Cpus, nrHugePages: hardware.NrHugePages, transparentHugePages: hardware.TransparentHugePages}
+	var err error
+	if env.totalMemory, err = utils.ParseMemorySize(hardware.Memory); err != nil {
+		return nil, fmt.Errorf("invalid memory in hardware description: %v", err)
+	}
+	if hardware.AvailableMemory != "" {
+		if env.availableMemory, err = utils.ParseMemorySize(hardware.AvailableMemory); err != nil {
+			return nil, fmt.Errorf("invalid available_memory in hardware description: %v", err)
+		}
+		if env.availableMemory > env.totalMemory {
+			return nil, fmt.Errorf("available_memory in hardware description is larger than memory")
+		}
+	}
+
+	switch utils.StorageType(hardware.Disk) {
+	case utils.StorageSSD, utils.StorageHDD:
+		env.storage = utils.StorageType(hardware.Disk)
+	case utils.StorageUnknown, "":
+		env.storage = utils.StorageUnknown
+	default:
+		return nil, fmt.Errorf("invalid disk in hardware description: %s, expected ssd, hdd or unknown", hardware.Disk)
+	}
+
+	hugePageSize := hardware.HugePageSize
+	if hugePageSize == "" {
+		hugePageSize = "2MB"
+	}
+	if env.hugePageSize, err = utils.ParseMemorySize(hugePageSize); err != nil {
+		return nil, fmt.Errorf("invalid huge_page_size in hardware description: %v", err)
+	}
+
+	stackSize := hardware.StackSize
+	if stackSize == "" {
+		stackSize = "8MB"
+	}
+	if env.stackSize, err = utils.ParseMemorySize(stackSize); err != nil {
+		return nil, fmt.Errorf("invalid stack_size in hardware description: %v", err)
+	}
+
+	if hardware.NrHugePages < 0 {
+		return nil, fmt.Errorf("nr_hugepages in hardware description must not be negative")
+	}
+	return env, nil
+}
+
+// Creates a configuration that runs checks against configuration files instead of a live server.
+// Nothing is applied in this mode, so no database connection or sudo rights are needed.
+func InitOfflineChecks(source *OfflineSource, logger *utils.Logger) (*Configuration, error) {
+	// 1. Hardware the configuration is meant for
+	env, err := newOfflineEnvironment(source.Hardware)
+	if err != nil {
+		logger.LogError(err)
+		return nil, err
+	}
+
+	// 2. postgresql.conf and postgresql.auto.conf, which overrides it
+	entries, err := utils.ParseConfigFile(source.ConfigFile)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed parsing configuration file: %v", err))
+		return nil, err
+	}
+	autoConfPath := source.AutoConfigFile
+	if autoConfPath == "" {
+		autoConfPath = filepath.Join(filepath.Dir(source.ConfigFile), "postgresql.auto.conf")
+		if _, err := os.Stat(autoConfPath); err != nil {
+			autoConfPath = ""
+		}
+	}
+	if autoConfPath != "" {
+		autoEntries, err := utils.ParseConfigFile(autoConfPath)
+		if err != nil {
+			logger.LogError(fmt.Errorf("Failed parsing configuration file: %v", err))
+			return nil, err
+		}
+		entries = append(entries, autoEntries...)
+	}
+
+	// 3. Build settings the way `pg_settings` would report them
+	env.entries = entries
+	env.settings, err = offlineSettings(entries, source.ServerVersion)
+	if err != nil {
+		logger.LogError(err)
+		return nil, err
+	}
+
+	conf := Configuration{path: source.ConfigFile, autoConfPath: autoConfPath, serverVersion: source.ServerVersion, offline: env}
+	conf.settings = env.copySettings()
+	conf.profile = workloadProfiles[DefaultWorkloadProfile]
+	if source.Profile != "" {
+		if conf.profile, err = GetWorkloadProfile(source.Profile); err != nil {
+			logger.LogError(err)
+			return nil, err
+		}
+	}
+
+	return &conf, nil
+}
+
+// Starts from catalog defaults and applies configuration file entries in order, so the last entry wins.
+// Settings keep the file and line of the entry they were set by, like `pg_settings` reports them.
+func offlineSettings(entries []utils.ConfigEntry, version int) (map[string]ResourceSetting, error) {
+	settings := make(map[string]ResourceSetting)
+	for _, name := range RequiredSettings() {
+		definition, ok := getSettingDefinition(name, version)
+		if !ok {
+			continue
+		}
+		value := definition.value
+		if check, ok := GetCheck(name); ok && value == "" {
+			value = check.DefaultValue(version)
+		}
+		settings[name] = ResourceSetting{Name: name, Value: value, Unit: definition.unit, EnumVals: definition.enumVals, Source: sourceDefault, RequiresRestart: definition.context == contextPostmaster}
+	}
+
+	// Settings no check reads are not validated, the server would complain about those itself
+	for _, entry := range entries {
+		setting, ok := settings[entry.Name]
+		if !ok {
+			continue
+		}
+		definition, _ := getSettingDefinition(entry.Name, version)
+		value, err := definition.normalize(entry.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s: %v", entry.File, entry.Line, entry.Name, err)
+		}
+		setting.Value = value
+		setting.Source = sourceConfigFile
+		setting.SourceFile = entry.File
+		setting.SourceLine = entry.Line
+		settings[entry.Name] = setting
+	}
+	return settings, nil
+}
+
+// Checks store their results in `conf.settings`, so every run starts from a fresh copy
+func (env *offlineEnvironment) copySettings() map[string]ResourceSetting {
+	settings := make(map[string]ResourceSetting, len(env.settings))
+	for name, setting := range env.settings {
+		settings[name] = setting
+	}
+	return settings
+}
+
+// Memory as described. Unless given, available memory is total memory without
+// shared_buffers, which a running server would already have allocated.
+func (env *offlineEnvironment) memoryInfo() (*utils.MemoryInfo, error) {
+	info := &utils.MemoryInfo{Total: env.totalMemory, Available: env.availableMemory, Source: "the hardware description"}
+	if env.availableMemory != 0 {
+		return info, nil
+	}
+
+	sharedBuffers := env.settings["shared_buffers"]
+	sharedBuffersBytes, err := utils.ConvertBasedOnUnit(sharedBuffers.Value, sharedBuffers.Unit, "B")
+	if err != nil {
+		return nil, err
+	}
+	info.Available = env.totalMemory
+	if uint64(sharedBuffersBytes) < env.totalMemory {
+		info.Available -= uint64(sharedBuffersBytes)
+	}
+	info.Source = "the hardware description, with shared_buffers taken out of available memory"
+	return info, nil
+}
+
+// CPUs as described. Hyperthreading isn't described, so every CPU counts as a physical core.
+func (env *offlineEnvironment) cpuInfo() *utils.CpuInfo {
+	return &utils.CpuInfo{Sockets: 1, PhysicalCores: env.cpus, LogicalCores: env.cpus, AvailableCores: env.cpus}
+}
+
+// Reason a check is skipped in offline mode, empty if it can run
+func offlineReason(check Check) string {
+	if !check.NeedsServer() {
+		return ""
+	}
+	return fmt.Sprintf("Check skipped: %s is based on statistics of a running server, which are not available when analysing configuration files.", check.Name())
+}
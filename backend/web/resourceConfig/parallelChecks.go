@@ -0,0 +1,172 @@
+// Parallel query and worker process checks. Suggestions are based on the
+// number of CPU cores PostgreSQL can actually make use of.
+package resourceConfig
+
+import (
+	"fmt"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+func init() {
+	RegisterCheck(&checkDefinition{name: "max_worker_processes", category: CategoryParallel, minVersion: 90400, run: (*Configuration).CheckMaxWorkerProcesses})
+	RegisterCheck(&checkDefinition{name: "max_parallel_workers", category: CategoryParallel, minVersion: 100000, requiredSettings: []string{"max_worker_processes"}, dependsOn: []string{"max_worker_processes"}, run: (*Configuration).CheckMaxParallelWorkers})
+	RegisterCheck(&checkDefinition{name: "max_parallel_workers_per_gather", category: CategoryParallel, minVersion: 90600, requiredSettings: []string{"max_parallel_workers"}, dependsOn: []string{"max_parallel_workers"}, run: (*Configuration).CheckMaxParallelWorkersPerGather})
+	RegisterCheck(&checkDefinition{name: "max_parallel_maintenance_workers", category: CategoryParallel, minVersion: 110000, requiredSettings: []string{"max_parallel_workers"}, dependsOn: []string{"max_parallel_workers"}, run: (*Configuration).CheckMaxParallelMaintenanceWorkers})
+}
+
+// Returns what a setting will be after its suggestion is applied
+func suggestedOrCurrent(setting ResourceSetting) string {
+	if setting.SuggestedValue != "" {
+		return setting.SuggestedValue
+	}
+	return setting.Value
+}
+
+// Half of the cores for a single operation, leaving the rest for other
+// sessions, but no more than the workload profile allows
+func workersPerOperation(cores int, profile *WorkloadProfile) int {
+	workers := cores / 2
+	if workers > profile.MaxWorkersPerOperation {
+		workers = profile.MaxWorkersPerOperation
+	}
+	return workers
+}
+
+func (conf *Configuration) CheckMaxWorkerProcesses(logger *utils.Logger) (*ResourceSetting, error) {
+	maxWorkerProcesses := conf.settings["max_worker_processes"]
+	maxWorkerProcesses.Details = "This setting sets the maximum number of background processes that the system can support. Parallel workers, logical replication workers and extensions are all taken from this pool. "
+
+	cpuInfo, err := conf.getCpuInfo(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed max_worker_processes check: %v", err))
+		maxWorkerProcesses.GotError = true
+		return nil, err
+	}
+	currentValue, err := utils.StringToInt(maxWorkerProcesses.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed max_worker_processes check: %v", err))
+		maxWorkerProcesses.GotError = true
+		return nil, err
+	}
+
+	cores := cpuInfo.UsableCores()
+	maxWorkerProcesses.Details += fmt.Sprintf("Server has %s. ", cpuInfo)
+	if cores > currentValue {
+		maxWorkerProcesses.SuggestedValue = fmt.Sprint(cores)
+		maxWorkerProcesses.Details += fmt.Sprintf("Current value is lower than the number of usable cores (%d), so not all of them can be used by parallel queries. Suggestion is to set this to the number of usable cores.", cores)
+	} else if currentValue < 8 {
+		maxWorkerProcesses.SuggestedValue = "8"
+		maxWorkerProcesses.Details += "Current value is lower than the default. Since this pool is shared with extensions and replication, it is best not to go below the default. Suggestion is to set this to 8."
+	}
+
+	resetSuggestionIfEqual(&maxWorkerProcesses)
+	conf.settings["max_worker_processes"] = maxWorkerProcesses
+	return &maxWorkerProcesses, nil
+}
+
+func (conf *Configuration) CheckMaxParallelWorkers(logger *utils.Logger) (*ResourceSetting, error) {
+	maxParallelWorkers := conf.settings["max_parallel_workers"]
+	maxParallelWorkers.Details = "This setting sets the maximum number of workers that the system can support for parallel operations. "
+
+	cpuInfo, err := conf.getCpuInfo(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed max_parallel_workers check: %v", err))
+		maxParallelWorkers.GotError = true
+		return nil, err
+	}
+	// Parallel workers are taken from max_worker_processes, so they cannot exceed it
+	workerProcesses, err := utils.StringToInt(suggestedOrCurrent(conf.settings["max_worker_processes"]))
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed max_parallel_workers check: %v", err))
+		maxParallelWorkers.GotError = true
+		return nil, err
+	}
+
+	suggestion := cpuInfo.UsableCores()
+	if suggestion > workerProcesses {
+		suggestion = workerProcesses
+	}
+	maxParallelWorkers.SuggestedValue = fmt.Sprint(suggestion)
+	maxParallelWorkers.Details += fmt.Sprintf("Server has %s. Running more parallel workers than there are cores only adds context switching, while fewer leaves cores idle during parallel queries. Suggestion is to set this to the number of usable cores, limited by max_worker_processes (%d).", cpuInfo, workerProcesses)
+
+	resetSuggestionIfEqual(&maxParallelWorkers)
+	conf.settings["max_parallel_workers"] = maxParallelWorkers
+	return &maxParallelWorkers, nil
+}
+
+// Workers available to parallel operations once suggestions are applied. Before PostgreSQL 10
+// there is no max_parallel_workers, so only max_worker_processes limits them.
+func (conf *Configuration) parallelWorkerLimit() (int, error) {
+	parallelWorkers := conf.settings["max_parallel_workers"]
+	if parallelWorkers.Value == "" {
+		parallelWorkers = conf.settings["max_worker_processes"]
+	}
+	return utils.StringToInt(suggestedOrCurrent(parallelWorkers))
+}
+
+func (conf *Configuration) CheckMaxParallelWorkersPerGather(logger *utils.Logger) (*ResourceSetting, error) {
+	perGather := conf.settings["max_parallel_workers_per_gather"]
+	perGather.Details = "This setting sets the maximum number of workers that can be started by a single Gather or Gather Merge node. "
+
+	cpuInfo, err := conf.getCpuInfo(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed max_parallel_workers_per_gather check: %v", err))
+		perGather.GotError = true
+		return nil, err
+	}
+	parallelWorkers, err := conf.parallelWorkerLimit()
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed max_parallel_workers_per_gather check: %v", err))
+		perGather.GotError = true
+		return nil, err
+	}
+
+	profile := conf.Profile()
+	suggestion := workersPerOperation(cpuInfo.UsableCores(), profile)
+	if suggestion > parallelWorkers {
+		suggestion = parallelWorkers
+	}
+	perGather.SuggestedValue = fmt.Sprint(suggestion)
+	if suggestion == 0 {
+		perGather.Details += fmt.Sprintf("Server has %s. With a single usable core parallel query only adds overhead. Suggestion is to set this to 0, which disables parallel query.", cpuInfo)
+	} else {
+		perGather.Details += fmt.Sprintf("Server has %s. A single query should not take up every core, otherwise concurrent queries are starved. Suggestion is to use half of the usable cores, but no more than %d since gains diminish quickly beyond that.", cpuInfo, profile.MaxWorkersPerOperation)
+		perGather.Details += profile.describe()
+	}
+
+	resetSuggestionIfEqual(&perGather)
+	conf.settings["max_parallel_workers_per_gather"] = perGather
+	return &perGather, nil
+}
+
+func (conf *Configuration) CheckMaxParallelMaintenanceWorkers(logger *utils.Logger) (*ResourceSetting, error) {
+	maintenanceWorkers := conf.settings["max_parallel_maintenance_workers"]
+	maintenanceWorkers.Details = "This setting sets the maximum number of parallel workers that can be started by a single utility command, such as CREATE INDEX or VACUUM. "
+
+	cpuInfo, err := conf.getCpuInfo(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed max_parallel_maintenance_workers check: %v", err))
+		maintenanceWorkers.GotError = true
+		return nil, err
+	}
+	parallelWorkers, err := conf.parallelWorkerLimit()
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed max_parallel_maintenance_workers check: %v", err))
+		maintenanceWorkers.GotError = true
+		return nil, err
+	}
+
+	profile := conf.Profile()
+	suggestion := workersPerOperation(cpuInfo.UsableCores(), profile)
+	if suggestion > parallelWorkers {
+		suggestion = parallelWorkers
+	}
+	maintenanceWorkers.SuggestedValue = fmt.Sprint(suggestion)
+	maintenanceWorkers.Details += fmt.Sprintf("Server has %s. Suggestion is to use half of the usable cores, but no more than %d. Keep in mind that each worker may use up to maintenance_work_mem, so index builds with more workers use more memory.", cpuInfo, profile.MaxWorkersPerOperation)
+	maintenanceWorkers.Details += profile.describe()
+
+	resetSuggestionIfEqual(&maintenanceWorkers)
+	conf.settings["max_parallel_maintenance_workers"] = maintenanceWorkers
+	return &maintenanceWorkers, nil
+}
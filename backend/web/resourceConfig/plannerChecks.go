@@ -0,0 +1,162 @@
+// Planner cost checks. Page cost and I/O concurrency suggestions depend on
+// whether the data directory is on solid state or rotational storage.
+package resourceConfig
+
+import (
+	"fmt"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+func init() {
+	RegisterCheck(&checkDefinition{name: "random_page_cost", category: CategoryPlanner, run: (*Configuration).CheckRandomPageCost})
+	RegisterCheck(&checkDefinition{name: "seq_page_cost", category: CategoryPlanner, run: (*Configuration).CheckSeqPageCost})
+	RegisterCheck(&checkDefinition{name: "effective_io_concurrency", category: CategoryPlanner, run: (*Configuration).CheckEffectiveIoConcurrency})
+	RegisterCheck(&checkDefinition{name: "effective_cache_size", category: CategoryPlanner, requiredSettings: []string{"shared_buffers"}, run: (*Configuration).CheckEffectiveCacheSize})
+}
+
+// Returns what kind of storage the data directory resides on
+func (conf *Configuration) getDataDirectoryStorage(logger *utils.Logger) (*utils.StorageInfo, error) {
+	if conf.offline != nil {
+		return &utils.StorageInfo{Device: "as given in the hardware description", Type: conf.offline.storage}, nil
+	}
+	dataDirectory, err := utils.FindDataDirectory(conf.dbHandler, logger)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := utils.GetStorageInfo(dataDirectory)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed detecting storage type of %s: %v", dataDirectory, err))
+		return nil, err
+	}
+	return storage, nil
+}
+
+func (conf *Configuration) CheckRandomPageCost(logger *utils.Logger) (*ResourceSetting, error) {
+	randomPageCost := conf.settings["random_page_cost"]
+	randomPageCost.Details = "This setting sets the planner's estimate of the cost of a non-sequentially-fetched disk page. "
+
+	storage, err := conf.getDataDirectoryStorage(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed random_page_cost check: %v", err))
+		randomPageCost.GotError = true
+		return nil, err
+	}
+
+	switch storage.Type {
+	case utils.StorageSSD:
+		randomPageCost.SuggestedValue = "1.1"
+		randomPageCost.Details += fmt.Sprintf("Data directory is on solid state storage (%s). Random reads on SSDs are barely more expensive than sequential ones, while the default of 4 assumes spinning disks and makes the planner avoid index scans. Suggestion is to set this to 1.1.", storage.Device)
+	case utils.StorageHDD:
+		randomPageCost.SuggestedValue = "4"
+		randomPageCost.Details += fmt.Sprintf("Data directory is on rotational storage (%s). Random reads require disk seeks, which the default of 4 accounts for. Suggestion is to set this to the default.", storage.Device)
+	default:
+		randomPageCost.Details += fmt.Sprintf("Could not determine what kind of storage the data directory is on (%s), so no suggestion is made. Use 1.1 for SSDs and 4 for spinning disks.", storage.Device)
+	}
+
+	resetSuggestionIfEqual(&randomPageCost)
+	conf.settings["random_page_cost"] = randomPageCost
+	return &randomPageCost, nil
+}
+
+// GENERALREC
+func (conf *Configuration) CheckSeqPageCost(logger *utils.Logger) (*ResourceSetting, error) {
+	seqPageCost := conf.settings["seq_page_cost"]
+	seqPageCost.Details = "This setting sets the planner's estimate of the cost of a disk page fetch that is part of a series of sequential fetches. "
+
+	if seqPageCost.Value != "1" {
+		seqPageCost.SuggestedValue = "1"
+		seqPageCost.Details += "All other planner costs are relative to this value, so it is best left at 1 and random_page_cost adjusted instead. Suggestion is to set this to the default."
+	}
+
+	resetSuggestionIfEqual(&seqPageCost)
+	conf.settings["seq_page_cost"] = seqPageCost
+	return &seqPageCost, nil
+}
+
+func (conf *Configuration) CheckEffectiveIoConcurrency(logger *utils.Logger) (*ResourceSetting, error) {
+	ioConcurrency := conf.settings["effective_io_concurrency"]
+	ioConcurrency.Details = "This setting sets the number of concurrent disk I/O operations that PostgreSQL expects can be executed simultaneously. "
+
+	storage, err := conf.getDataDirectoryStorage(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed effective_io_concurrency check: %v", err))
+		ioConcurrency.GotError = true
+		return nil, err
+	}
+
+	switch storage.Type {
+	case utils.StorageSSD:
+		ioConcurrency.SuggestedValue = "200"
+		ioConcurrency.Details += fmt.Sprintf("Data directory is on solid state storage (%s), which can serve many requests in parallel. Suggestion is to set this to 200.", storage.Device)
+	case utils.StorageHDD:
+		ioConcurrency.SuggestedValue = "2"
+		ioConcurrency.Details += fmt.Sprintf("Data directory is on rotational storage (%s). A single spinning disk can only serve a couple of concurrent requests. Suggestion is to set this to 2, or to the number of disks if they're in a RAID array.", storage.Device)
+	default:
+		ioConcurrency.Details += fmt.Sprintf("Could not determine what kind of storage the data directory is on (%s), so no suggestion is made.", storage.Device)
+	}
+
+	resetSuggestionIfEqual(&ioConcurrency)
+	conf.settings["effective_io_concurrency"] = ioConcurrency
+	return &ioConcurrency, nil
+}
+
+// Estimates the memory available for caching data: shared_buffers plus 3/4 of the
+// remaining memory, which is roughly how much the kernel page cache can grow to.
+func (conf *Configuration) CheckEffectiveCacheSize(logger *utils.Logger) (*ResourceSetting, error) {
+	effectiveCacheSize := conf.settings["effective_cache_size"]
+	effectiveCacheSize.Details = "This setting sets the planner's assumption about the effective size of the disk cache that is available to a single query. "
+
+	// 1. Get total memory and shared_buffers in effective_cache_size's unit
+	memoryInfo, err := conf.getMemoryInfo(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed effective_cache_size check: %v", err))
+		effectiveCacheSize.GotError = true
+		return nil, err
+	}
+	totalMemoryConverted, err := utils.ConvertBasedOnUnit(utils.Uint64ToString(memoryInfo.Total), "B", effectiveCacheSize.Unit)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed effective_cache_size check: %v", err))
+		effectiveCacheSize.GotError = true
+		return nil, err
+	}
+	sharedBuffers, err := conf.getSpecificPGSetting("shared_buffers", logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed effective_cache_size check: %v", err))
+		effectiveCacheSize.GotError = true
+		return nil, err
+	}
+	sharedBuffersConverted, err := utils.ConvertBasedOnUnit(sharedBuffers.Value, sharedBuffers.Unit, effectiveCacheSize.Unit)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed effective_cache_size check: %v", err))
+		effectiveCacheSize.GotError = true
+		return nil, err
+	}
+
+	// 2. shared_buffers + share of what's left for the OS set by workload profile
+	profile := conf.Profile()
+	suggestion := sharedBuffersConverted
+	if totalMemoryConverted > sharedBuffersConverted {
+		suggestion += (totalMemoryConverted - sharedBuffersConverted) * profile.EffectiveCacheRatio
+	}
+	currentValue, err := utils.StringToFloat32(effectiveCacheSize.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed effective_cache_size check: %v", err))
+		effectiveCacheSize.GotError = true
+		return nil, err
+	}
+	// This is only an estimate, don't suggest changes that are within 10% of the current value
+	if currentValue > suggestion*0.9 && currentValue < suggestion*1.1 {
+		conf.settings["effective_cache_size"] = effectiveCacheSize
+		return &effectiveCacheSize, nil
+	}
+
+	effectiveCacheSize.SuggestedValue = utils.Uint64ToString(uint64(suggestion))
+	effectiveCacheSize.Details += memoryInfo.Describe()
+	effectiveCacheSize.Details += fmt.Sprintf("Data can be cached both in shared_buffers (%s%s) and the kernel's page cache. Suggestion is shared_buffers plus %g%% of the remaining server memory (%.0f%s total), leaving the rest for connections and other processes. This setting does not allocate any memory, it only makes the planner more willing to use index scans.", sharedBuffers.Value, sharedBuffers.Unit, profile.EffectiveCacheRatio*100, totalMemoryConverted, effectiveCacheSize.Unit)
+	effectiveCacheSize.Details += profile.describe()
+
+	resetSuggestionIfEqual(&effectiveCacheSize)
+	conf.settings["effective_cache_size"] = effectiveCacheSize
+	return &effectiveCacheSize, nil
+}
@@ -0,0 +1,177 @@
+// Workload profiles. Checks that split memory or CPU between consumers use
+// the ratios of the selected profile instead of a single hard-coded heuristic.
+// The selected profile is persisted so that the UI and API agree on it.
+package resourceConfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+// Kept in the backups directory of each instance, so that every instance has its own profile
+const profileFileName = "workload_profile"
+
+// Where the profile was kept before each instance had its own
+const legacyProfileFile = "/usr/local/postgrescrutiniser/confs/workload_profile"
+
+type WorkloadProfile struct {
+	Name        string
+	Description string
+
+	SharedBuffersRatio        float32 // share of total memory used for shared_buffers
+	WorkMemMultiplier         float32 // multiplies (available memory / max_connections)
+	MaintenanceWorkMemDivisor float32 // available memory is divided by this and autovacuum_max_workers
+	AutovacuumWorkMemDivisor  float32 // available memory is divided by this and autovacuum_max_workers
+	EffectiveCacheRatio       float32 // share of memory outside shared_buffers expected to be page cache
+	MaxWorkersPerOperation    int     // upper limit for parallel workers used by a single query or command
+}
+
+// "mixed" keeps the ratios checks used before profiles were introduced
+var workloadProfiles = map[string]*WorkloadProfile{
+	"oltp": {
+		Name:                      "oltp",
+		Description:               "Many short transactions touching few rows. Keeps per-query memory low so that many connections can run at once",
+		SharedBuffersRatio:        0.25,
+		WorkMemMultiplier:         0.5,
+		MaintenanceWorkMemDivisor: 8,
+		AutovacuumWorkMemDivisor:  4,
+		EffectiveCacheRatio:       0.75,
+		MaxWorkersPerOperation:    2,
+	},
+	"olap": {
+		Name:                      "olap",
+		Description:               "Few long running analytical queries over large amounts of data. Gives each query more memory and parallel workers",
+		SharedBuffersRatio:        0.25,
+		WorkMemMultiplier:         4,
+		MaintenanceWorkMemDivisor: 4,
+		AutovacuumWorkMemDivisor:  4,
+		EffectiveCacheRatio:       0.75,
+		MaxWorkersPerOperation:    8,
+	},
+	"web": {
+		Name:                      "web",
+		Description:               "Web application backend with many mostly idle connections and simple queries. Gives each connection less memory and doesn't spread queries over many workers",
+		SharedBuffersRatio:        0.25,
+		WorkMemMultiplier:         0.25,
+		MaintenanceWorkMemDivisor: 16,
+		AutovacuumWorkMemDivisor:  8,
+		EffectiveCacheRatio:       0.75,
+		MaxWorkersPerOperation:    1,
+	},
+	"mixed": {
+		Name:                      "mixed",
+		Description:               "General purpose server running both transactional and reporting queries",
+		SharedBuffersRatio:        0.25,
+		WorkMemMultiplier:         1,
+		MaintenanceWorkMemDivisor: 8,
+		AutovacuumWorkMemDivisor:  4,
+		EffectiveCacheRatio:       0.75,
+		MaxWorkersPerOperation:    4,
+	},
+	"desktop": {
+		Name:                      "desktop",
+		Description:               "Developer machine or a server shared with other applications. PostgreSQL only gets a small part of the resources",
+		SharedBuffersRatio:        0.0625,
+		WorkMemMultiplier:         0.25,
+		MaintenanceWorkMemDivisor: 16,
+		AutovacuumWorkMemDivisor:  8,
+		EffectiveCacheRatio:       0.25,
+		MaxWorkersPerOperation:    2,
+	},
+}
+
+const DefaultWorkloadProfile = "mixed"
+
+// Returns profile by name
+func GetWorkloadProfile(name string) (*WorkloadProfile, error) {
+	profile, ok := workloadProfiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown workload profile: %s", name)
+	}
+	return profile, nil
+}
+
+// Returns all profiles ordered by name
+func WorkloadProfiles() []*WorkloadProfile {
+	names := []string{"desktop", "mixed", "olap", "oltp", "web"}
+	profiles := make([]*WorkloadProfile, 0, len(names))
+	for _, name := range names {
+		profiles = append(profiles, workloadProfiles[name])
+	}
+	return profiles
+}
+
+// Reads the persisted profile. Falls back to the default profile if
+// nothing has been persisted yet or the file contains an unknown profile.
+func loadWorkloadProfile(path string, logger *utils.Logger) *WorkloadProfile {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.LogWarning(fmt.Errorf("could not read workload profile from %s: %v", path, err))
+		}
+		return workloadProfiles[DefaultWorkloadProfile]
+	}
+
+	profile, err := GetWorkloadProfile(strings.TrimSpace(string(content)))
+	if err != nil {
+		logger.LogWarning(fmt.Errorf("%v, falling back to %s", err, DefaultWorkloadProfile))
+		return workloadProfiles[DefaultWorkloadProfile]
+	}
+	return profile
+}
+
+// Returns the profile checks are currently run with
+func (conf *Configuration) Profile() *WorkloadProfile {
+	if conf.profile == nil {
+		return workloadProfiles[DefaultWorkloadProfile]
+	}
+	return conf.profile
+}
+
+// Changes the profile checks are run with and persists it
+func (conf *Configuration) SetProfile(name string, logger *utils.Logger) error {
+	profile, err := GetWorkloadProfile(name)
+	if err != nil {
+		return err
+	}
+	if conf.profile != nil && conf.profile.Name == profile.Name {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(conf.profilePath), 0755); err != nil {
+		logger.LogError(fmt.Errorf("failed to persist workload profile: %v", err))
+		return err
+	}
+	if err := os.WriteFile(conf.profilePath, []byte(profile.Name+"\n"), 0644); err != nil {
+		logger.LogError(fmt.Errorf("failed to persist workload profile: %v", err))
+		return err
+	}
+	conf.profile = profile
+	return nil
+}
+
+// Sentence appended to Details of checks whose suggestion depends on the profile
+func (profile *WorkloadProfile) describe() string {
+	return fmt.Sprintf(" Suggestion assumes the \"%s\" workload profile.", profile.Name)
+}
+
+// Moves the profile selected before each instance had its own into @backupDir of the default
+// instance, since it was selected for it. A profile already selected there is kept.
+func MigrateProfile(backupDir string, logger *utils.Logger) error {
+	profilePath := filepath.Join(backupDir, profileFileName)
+	if _, err := os.Stat(legacyProfileFile); os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := os.Stat(profilePath); err == nil {
+		return nil
+	}
+	if err := os.Rename(legacyProfileFile, profilePath); err != nil {
+		logger.LogError(fmt.Errorf("could not move %s to %s: %v", legacyProfileFile, profilePath, err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+// Check results as a standalone report. Besides JSON, reports can be rendered
+// as Markdown for tickets, HTML to share with people without access to the
+// application and SARIF so findings show up in code scanning dashboards.
+package resourceConfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+var ErrUnknownReportFormat = errors.New("unknown report format")
+
+// Content type and file extension of each report format
+var reportFormats = map[GetReportParamsFormat]struct {
+	contentType string
+	extension   string
+}{
+	Json:     {"application/json", "json"},
+	Markdown: {"text/markdown; charset=utf-8", "md"},
+	Html:     {"text/html; charset=utf-8", "html"},
+	Sarif:    {"application/sarif+json", "sarif"},
+}
+
+// Returns an error if @format is not a report format
+func ValidateReportFormat(format string) error {
+	if _, ok := reportFormats[GetReportParamsFormat(format)]; !ok {
+		return fmt.Errorf("%w: %s, expected json, markdown, html or sarif", ErrUnknownReportFormat, format)
+	}
+	return nil
+}
+
+// Name a report in @format is downloaded as
+func ReportFileName(format GetReportParamsFormat) string {
+	return "postgrescrutiniser-report." + reportFormats[format].extension
+}
+
+/*
+Builds a report from check results.
+@results - results as returned by `RunChecks`
+*/
+func (conf *Configuration) BuildReport(results map[string]ResourceSetting, logger *utils.Logger) *Report {
+	report := &Report{
+		GeneratedAt: time.Now().UTC().Truncate(time.Second),
+		Host:        conf.reportHost(logger),
+		Settings:    []ReportSetting{},
+	}
+
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		setting := results[name]
+		item := ReportSetting{
+			Name:           name,
+			Status:         setting.Status(),
+			Value:          setting.Value,
+			Unit:           setting.Unit,
+			SuggestedValue: setting.SuggestedValue,
+			Details:        setting.Details,
+		}
+		if check, ok := GetCheck(name); ok {
+			item.Category = check.Category()
+		}
+		item.SourceFile, item.SourceLine = setting.SourceFile, setting.SourceLine
+		if len(setting.TableSuggestions) > 0 {
+			tables := setting.TableSuggestions
+			item.TableSuggestions = &tables
+		}
+		report.Settings = append(report.Settings, item)
+
+		report.Summary.Checks++
+		switch item.Status {
+		case StatusSuggestion:
+			report.Summary.Suggestions++
+		case StatusError:
+			report.Summary.Errors++
+		case StatusSkipped:
+			report.Summary.Skipped++
+		}
+	}
+	return report
+}
+
+// Host facts checks based their suggestions on. Facts that can't be
+// gathered are left empty rather than failing the whole report.
+func (conf *Configuration) reportHost(logger *utils.Logger) ReportHost {
+	host := ReportHost{Mode: "live", ConfigFile: conf.path, Profile: conf.Profile().Name, Storage: string(utils.StorageUnknown)}
+	if conf.offline != nil {
+		host.Mode = "offline"
+	}
+	if conf.serverVersion != 0 {
+		host.ServerVersion = utils.FormatServerVersion(conf.serverVersion)
+	}
+
+	var err error
+	if host.Hostname, err = os.Hostname(); err != nil {
+		logger.LogWarning(fmt.Errorf("Could not get hostname for report: %v", err))
+	}
+	if memoryInfo, err := conf.getMemoryInfo(logger); err == nil {
+		host.TotalMemory = int64(memoryInfo.Total)
+		host.AvailableMemory = int64(memoryInfo.Available)
+		host.MemorySource = memoryInfo.Source
+	} else {
+		logger.LogWarning(fmt.Errorf("Could not get memory for report: %v", err))
+	}
+	if cpuInfo, err := conf.getCpuInfo(logger); err == nil {
+		host.Cpu = cpuInfo.String()
+		host.UsableCores = cpuInfo.UsableCores()
+	} else {
+		logger.LogWarning(fmt.Errorf("Could not get CPUs for report: %v", err))
+	}
+	if storage, err := conf.getDataDirectoryStorage(logger); err == nil {
+		host.Storage = string(storage.Type)
+	} else {
+		logger.LogWarning(fmt.Errorf("Could not get storage type for report: %v", err))
+	}
+	return host
+}
+
+// Renders report in @format. Returns the rendered report and its content type.
+func (report *Report) Render(format GetReportParamsFormat) ([]byte, string, error) {
+	var content []byte
+	var err error
+	switch format {
+	case Json:
+		content, err = json.MarshalIndent(report, "", "  ")
+	case Markdown:
+		content = report.markdown()
+	case Html:
+		content, err = report.html()
+	case Sarif:
+		content, err = report.sarif()
+	default:
+		return nil, "", ValidateReportFormat(string(format))
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return content, reportFormats[format].contentType, nil
+}
+
+// Formats bytes with the largest unit that keeps the value at or above 1
+func formatBytes(bytes int64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB"}
+	value := float64(bytes)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(fmt.Sprintf("%.1f", value), "0"), ".") + units[unit]
+}
+
+// Value with its unit the way it would be written in postgresql.conf
+func withUnit(value string, unit string) string {
+	if value == "" || unit == "" {
+		return value
+	}
+	return value + " " + unit
+}
+
+// Escapes characters that would break a Markdown table cell
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func (report *Report) markdown() []byte {
+	var builder strings.Builder
+	host := report.Host
+
+	fmt.Fprintf(&builder, "# PostgreScrutiniser report\n\n")
+	fmt.Fprintf(&builder, "Generated %s on %s.\n\n", report.GeneratedAt.Format(time.RFC3339), markdownCell(host.Hostname))
+
+	// 1. Facts suggestions are based on
+	builder.WriteString("## Host\n\n| | |\n|---|---|\n")
+	facts := [][2]string{
+		{"Mode", host.Mode},
+		{"Server version", host.ServerVersion},
+		{"Configuration file", host.ConfigFile},
+		{"Workload profile", host.Profile},
+		{"Total memory", formatBytes(host.TotalMemory)},
+		{"Available memory", formatBytes(host.AvailableMemory)},
+		{"Memory source", host.MemorySource},
+		{"CPU", host.Cpu},
+		{"Usable cores", fmt.Sprint(host.UsableCores)},
+		{"Storage", host.Storage},
+	}
+	for _, fact := range facts {
+		fmt.Fprintf(&builder, "| %s | %s |\n", fact[0], markdownCell(fact[1]))
+	}
+
+	// 2. Summary and a table of all settings
+	summary := report.Summary
+	fmt.Fprintf(&builder, "\n## Summary\n\n%d checks: %d suggestions, %d errors, %d skipped.\n\n", summary.Checks, summary.Suggestions, summary.Errors, summary.Skipped)
+	builder.WriteString("| Setting | Category | Value | Suggested | Status |\n|---|---|---|---|---|\n")
+	for _, setting := range report.Settings {
+		fmt.Fprintf(&builder, "| %s | %s | %s | %s | %s |\n", setting.Name, setting.Category,
+			markdownCell(withUnit(setting.Value, setting.Unit)), markdownCell(withUnit(setting.SuggestedValue, setting.Unit)), setting.Status)
+	}
+
+	// 3. Why each value was suggested
+	builder.WriteString("\n## Details\n")
+	for _, setting := range report.Settings {
+		fmt.Fprintf(&builder, "\n### %s\n\n", setting.Name)
+		if setting.SourceFile != "" {
+			fmt.Fprintf(&builder, "Set in `%s:%d`.\n\n", setting.SourceFile, setting.SourceLine)
+		}
+		if setting.Details != "" {
+			fmt.Fprintf(&builder, "%s\n", strings.TrimSpace(setting.Details))
+		}
+		if setting.TableSuggestions != nil {
+			builder.WriteString("\n```sql\n")
+			for _, table := range *setting.TableSuggestions {
+				fmt.Fprintf(&builder, "-- connect to %s\n%s\n", table.Database, table.Statement)
+			}
+			builder.WriteString("```\n")
+		}
+	}
+	return []byte(builder.String())
+}
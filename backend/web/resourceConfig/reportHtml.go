@@ -0,0 +1,86 @@
+// Report rendered as a single HTML page. Styles are inlined so the
+// file can be attached to a ticket or mailed without anything else.
+package resourceConfig
+
+import (
+	"bytes"
+	"html/template"
+	"time"
+)
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"bytes":    formatBytes,
+	"withUnit": withUnit,
+	"time":     func(t time.Time) string { return t.Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>PostgreScrutiniser report - {{.Host.Hostname}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 70em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; }
+.status-ok { color: #2e7d32; }
+.status-suggestion { color: #e65100; font-weight: bold; }
+.status-error { color: #c62828; font-weight: bold; }
+.status-skipped { color: #757575; }
+.source { color: #757575; font-size: 0.9em; }
+pre { background: #f6f6f6; padding: 0.6em; overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>PostgreScrutiniser report</h1>
+<p>Generated {{time .GeneratedAt}} on {{.Host.Hostname}}.</p>
+
+<h2>Host</h2>
+<table>
+<tr><th>Mode</th><td>{{.Host.Mode}}</td></tr>
+<tr><th>Server version</th><td>{{.Host.ServerVersion}}</td></tr>
+<tr><th>Configuration file</th><td>{{.Host.ConfigFile}}</td></tr>
+<tr><th>Workload profile</th><td>{{.Host.Profile}}</td></tr>
+<tr><th>Total memory</th><td>{{bytes .Host.TotalMemory}}</td></tr>
+<tr><th>Available memory</th><td>{{bytes .Host.AvailableMemory}}</td></tr>
+<tr><th>Memory source</th><td>{{.Host.MemorySource}}</td></tr>
+<tr><th>CPU</th><td>{{.Host.Cpu}}</td></tr>
+<tr><th>Usable cores</th><td>{{.Host.UsableCores}}</td></tr>
+<tr><th>Storage</th><td>{{.Host.Storage}}</td></tr>
+</table>
+
+<h2>Summary</h2>
+<p>{{.Summary.Checks}} checks: {{.Summary.Suggestions}} suggestions, {{.Summary.Errors}} errors, {{.Summary.Skipped}} skipped.</p>
+<table>
+<tr><th>Setting</th><th>Category</th><th>Value</th><th>Suggested</th><th>Status</th></tr>
+{{- range .Settings}}
+<tr><td><a href="#{{.Name}}">{{.Name}}</a></td><td>{{.Category}}</td><td>{{withUnit .Value .Unit}}</td><td>{{withUnit .SuggestedValue .Unit}}</td><td class="status-{{.Status}}">{{.Status}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Details</h2>
+{{- range .Settings}}
+<h3 id="{{.Name}}">{{.Name}} <span class="status-{{.Status}}">({{.Status}})</span></h3>
+{{- if .SourceFile}}
+<p class="source">Set in {{.SourceFile}}:{{.SourceLine}}</p>
+{{- end}}
+<p>{{.Details}}</p>
+{{- if .TableSuggestions}}
+<pre>
+{{- range .TableSuggestions}}
+-- connect to {{.Database}}
+{{.Statement}}
+{{- end}}
+</pre>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+func (report *Report) html() ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := reportTemplate.Execute(&buffer, report); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
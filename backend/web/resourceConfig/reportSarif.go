@@ -0,0 +1,159 @@
+// Report rendered as a SARIF 2.1.0 log so suggestions show up in code scanning
+// dashboards of the repository configuration files are kept in. Every check is
+// a rule and every suggestion a result pointing at the line the setting was set on.
+package resourceConfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// Subset of the SARIF object model that reports make use of
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+	Properties  map[string]any    `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id               string         `json:"id"`
+	ShortDescription sarifMessage   `json:"shortDescription"`
+	Properties       map[string]any `json:"properties,omitempty"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	EndTimeUtc                 string              `json:"endTimeUtc"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+type sarifResult struct {
+	RuleId     string          `json:"ruleId"`
+	RuleIndex  int             `json:"ruleIndex"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations,omitempty"`
+	Properties map[string]any  `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func (report *Report) sarif() ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "PostgreScrutiniser",
+			InformationUri: "https://github.com/Globys031/PostgreScrutiniser",
+			Rules:          []sarifRule{},
+		}},
+		Results:    []sarifResult{},
+		Properties: map[string]any{"host": report.Host, "summary": report.Summary},
+	}
+	invocation := sarifInvocation{ExecutionSuccessful: report.Summary.Errors == 0, EndTimeUtc: report.GeneratedAt.Format("2006-01-02T15:04:05Z")}
+
+	for index, setting := range report.Settings {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			Id:               setting.Name,
+			ShortDescription: sarifMessage{Text: fmt.Sprintf("Checks whether %s suits the server it runs on", setting.Name)},
+			Properties:       map[string]any{"category": setting.Category},
+		})
+
+		// Checks that failed are problems of the tool rather than of the configuration
+		if setting.Status == StatusError {
+			invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, sarifNotification{
+				Level:   "error",
+				Message: sarifMessage{Text: fmt.Sprintf("%s check failed. %s", setting.Name, strings.TrimSpace(setting.Details))},
+			})
+			continue
+		}
+		if setting.Status != StatusSuggestion {
+			continue
+		}
+
+		result := sarifResult{
+			RuleId:    setting.Name,
+			RuleIndex: index,
+			Level:     "warning",
+			Message: sarifMessage{Text: fmt.Sprintf("%s is set to %s, suggested value is %s. %s", setting.Name,
+				withUnit(setting.Value, setting.Unit), withUnit(setting.SuggestedValue, setting.Unit), strings.TrimSpace(setting.Details))},
+			Properties: map[string]any{"value": setting.Value, "suggested_value": setting.SuggestedValue, "unit": setting.Unit},
+		}
+		file := setting.SourceFile
+		if file == "" {
+			file = report.Host.ConfigFile
+		}
+		if file != "" {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{Uri: sarifUri(file)}}}
+			if setting.SourceFile != "" && setting.SourceLine > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: setting.SourceLine}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		run.Results = append(run.Results, result)
+	}
+	run.Invocations = []sarifInvocation{invocation}
+
+	return json.MarshalIndent(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}, "", "  ")
+}
+
+// Files inside the working directory are referenced relative to it, so
+// that code scanning can match them to files in the checked out repository
+func sarifUri(path string) string {
+	if absolute, err := filepath.Abs(path); err == nil {
+		path = absolute
+	}
+	if workDir, err := os.Getwd(); err == nil {
+		if relative, err := filepath.Rel(workDir, path); err == nil && !strings.HasPrefix(relative, "..") {
+			return filepath.ToSlash(relative)
+		}
+	}
+	return "file://" + filepath.ToSlash(path)
+}
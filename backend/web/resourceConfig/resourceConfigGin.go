@@ -14,17 +14,41 @@ import (
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 
+	// (POST /export)
+	ExportResourceConfigs(c *gin.Context, params ExportResourceConfigsParams)
+
+	// (GET /file-settings)
+	GetFileSettings(c *gin.Context, params GetFileSettingsParams)
+
+	// (GET /memory-budget)
+	GetMemoryBudget(c *gin.Context)
+
+	// (GET /pending-restart)
+	GetPendingRestart(c *gin.Context)
+
+	// (GET /profile)
+	GetWorkloadProfile(c *gin.Context)
+
+	// (PUT /profile)
+	PutWorkloadProfile(c *gin.Context)
+
+	// (GET /report)
+	GetReport(c *gin.Context, params GetReportParams)
+
 	// (DELETE /resource)
 	DeleteResourceConfigs(c *gin.Context)
 
 	// (GET /resource)
-	GetResourceConfigs(c *gin.Context)
+	GetResourceConfigs(c *gin.Context, params GetResourceConfigsParams)
 
 	// (PATCH /resource)
-	PatchResourceConfigs(c *gin.Context)
+	PatchResourceConfigs(c *gin.Context, params PatchResourceConfigsParams)
 
 	// (GET /resource/{config})
-	GetResourceConfigById(c *gin.Context, config GetResourceConfigByIdParamsConfig)
+	GetResourceConfigById(c *gin.Context, config string, params GetResourceConfigByIdParams)
+
+	// (GET /role-settings)
+	GetRoleSettings(c *gin.Context)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -36,6 +60,144 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(c *gin.Context)
 
+// ExportResourceConfigs operation middleware
+func (siw *ServerInterfaceWrapper) ExportResourceConfigs(c *gin.Context) {
+
+	var err error
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExportResourceConfigsParams
+
+	// ------------- Required query parameter "format" -------------
+
+	if paramValue := c.Query("format"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument format is required, but not found: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "format", c.Request.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter format: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.ExportResourceConfigs(c, params)
+}
+
+// GetFileSettings operation middleware
+func (siw *ServerInterfaceWrapper) GetFileSettings(c *gin.Context) {
+
+	var err error
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetFileSettingsParams
+
+	// ------------- Optional query parameter "all" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "all", c.Request.URL.Query(), &params.All)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter all: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetFileSettings(c, params)
+}
+
+// GetMemoryBudget operation middleware
+func (siw *ServerInterfaceWrapper) GetMemoryBudget(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetMemoryBudget(c)
+}
+
+// GetPendingRestart operation middleware
+func (siw *ServerInterfaceWrapper) GetPendingRestart(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetPendingRestart(c)
+}
+
+// GetWorkloadProfile operation middleware
+func (siw *ServerInterfaceWrapper) GetWorkloadProfile(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetWorkloadProfile(c)
+}
+
+// PutWorkloadProfile operation middleware
+func (siw *ServerInterfaceWrapper) PutWorkloadProfile(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.PutWorkloadProfile(c)
+}
+
+// GetReport operation middleware
+func (siw *ServerInterfaceWrapper) GetReport(c *gin.Context) {
+
+	var err error
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetReportParams
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", c.Request.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter format: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "profile" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "profile", c.Request.URL.Query(), &params.Profile)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter profile: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetReport(c, params)
+}
+
 // DeleteResourceConfigs operation middleware
 func (siw *ServerInterfaceWrapper) DeleteResourceConfigs(c *gin.Context) {
 
@@ -51,25 +213,67 @@ func (siw *ServerInterfaceWrapper) DeleteResourceConfigs(c *gin.Context) {
 // GetResourceConfigs operation middleware
 func (siw *ServerInterfaceWrapper) GetResourceConfigs(c *gin.Context) {
 
+	var err error
+
 	c.Set(BearerAuthScopes, []string{""})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetResourceConfigsParams
+
+	// ------------- Optional query parameter "profile" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "profile", c.Request.URL.Query(), &params.Profile)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter profile: %s", err), http.StatusBadRequest)
+		return
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
 
-	siw.Handler.GetResourceConfigs(c)
+	siw.Handler.GetResourceConfigs(c, params)
 }
 
 // PatchResourceConfigs operation middleware
 func (siw *ServerInterfaceWrapper) PatchResourceConfigs(c *gin.Context) {
 
+	var err error
+
 	c.Set(BearerAuthScopes, []string{""})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PatchResourceConfigsParams
+
+	// ------------- Optional query parameter "dry_run" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dry_run", c.Request.URL.Query(), &params.DryRun)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter dry_run: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "apply_at" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "apply_at", c.Request.URL.Query(), &params.ApplyAt)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter apply_at: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "window" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "window", c.Request.URL.Query(), &params.Window)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter window: %s", err), http.StatusBadRequest)
+		return
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
 
-	siw.Handler.PatchResourceConfigs(c)
+	siw.Handler.PatchResourceConfigs(c, params)
 }
 
 // GetResourceConfigById operation middleware
@@ -78,7 +282,7 @@ func (siw *ServerInterfaceWrapper) GetResourceConfigById(c *gin.Context) {
 	var err error
 
 	// ------------- Path parameter "config" -------------
-	var config GetResourceConfigByIdParamsConfig
+	var config string
 
 	err = runtime.BindStyledParameter("simple", false, "config", c.Param("config"), &config)
 	if err != nil {
@@ -88,11 +292,34 @@ func (siw *ServerInterfaceWrapper) GetResourceConfigById(c *gin.Context) {
 
 	c.Set(BearerAuthScopes, []string{""})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetResourceConfigByIdParams
+
+	// ------------- Optional query parameter "profile" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "profile", c.Request.URL.Query(), &params.Profile)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter profile: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetResourceConfigById(c, config, params)
+}
+
+// GetRoleSettings operation middleware
+func (siw *ServerInterfaceWrapper) GetRoleSettings(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{""})
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
 
-	siw.Handler.GetResourceConfigById(c, config)
+	siw.Handler.GetRoleSettings(c)
 }
 
 // GinServerOptions provides options for the Gin server.
@@ -124,6 +351,20 @@ func RegisterHandlersWithOptions(router *gin.Engine, si ServerInterface, options
 		ErrorHandler:       errorHandler,
 	}
 
+	router.POST(options.BaseURL+"/export", wrapper.ExportResourceConfigs)
+
+	router.GET(options.BaseURL+"/file-settings", wrapper.GetFileSettings)
+
+	router.GET(options.BaseURL+"/memory-budget", wrapper.GetMemoryBudget)
+
+	router.GET(options.BaseURL+"/pending-restart", wrapper.GetPendingRestart)
+
+	router.GET(options.BaseURL+"/profile", wrapper.GetWorkloadProfile)
+
+	router.PUT(options.BaseURL+"/profile", wrapper.PutWorkloadProfile)
+
+	router.GET(options.BaseURL+"/report", wrapper.GetReport)
+
 	router.DELETE(options.BaseURL+"/resource", wrapper.DeleteResourceConfigs)
 
 	router.GET(options.BaseURL+"/resource", wrapper.GetResourceConfigs)
@@ -132,5 +373,7 @@ func RegisterHandlersWithOptions(router *gin.Engine, si ServerInterface, options
 
 	router.GET(options.BaseURL+"/resource/:config", wrapper.GetResourceConfigById)
 
+	router.GET(options.BaseURL+"/role-settings", wrapper.GetRoleSettings)
+
 	return router
 }
@@ -7,10 +7,15 @@ package resourceConfig
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/auth"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/schedule"
 	"github.com/gin-gonic/gin"
 	// "github.com/Globys031/plotzemis/go/auth"
 	// "github.com/Globys031/plotzemis/go/db"
@@ -21,22 +26,58 @@ After `oapi-codegen` was used to generate API endpoints,
 `ServerInterface` can be used to add our actual implementation
 */
 type ResourceConfigImpl struct {
+	Instance      string // id of the instance routes act on
 	ConfigFile    string
+	BackupDir     string // directory postgresql.auto.conf of the instance is backed up to
 	AppUser       *utils.User
 	PostgresUser  *utils.User
 	Logger        *utils.Logger
 	Configuration *Configuration
 	DbHandler     *sql.DB
+	DbInfo        *utils.DbConnectionInfo
+	Service       utils.ServiceController
+	Scheduler     *schedule.Scheduler
+	Audit         *utils.AuditLog // changes that went through are recorded here
+	Mutex         *sync.Mutex     // shared with the instance's FileImpl, held while Configuration is used
 }
 
-func (impl *ResourceConfigImpl) GetResourceConfigs(c *gin.Context) {
+// Switches to the profile passed as a query parameter, if any.
+// Returns false if a response has already been written.
+func (impl *ResourceConfigImpl) selectProfile(c *gin.Context, profile *Profile) bool {
+	if profile == nil {
+		return true
+	}
+	if _, err := GetWorkloadProfile(string(*profile)); err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: err.Error(),
+		}
+		c.JSON(http.StatusBadRequest, errorMsg)
+		return false
+	}
+	if err := impl.Configuration.SetProfile(string(*profile), impl.Logger); err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: "Could not save workload profile. See /var/log/postgrescrutiniser/error.log for more details",
+		}
+		c.JSON(http.StatusInternalServerError, errorMsg)
+		return false
+	}
+	return true
+}
+
+func (impl *ResourceConfigImpl) GetResourceConfigs(c *gin.Context, params GetResourceConfigsParams) {
 	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
 		return
 	}
 
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
+
 	// Reuse the same variable that contains resource setting details
 	if impl.Configuration == nil {
-		impl.Configuration = InitChecks(impl.ConfigFile, impl.DbHandler, impl.AppUser, impl.PostgresUser, impl.Logger)
+		impl.Configuration = InitChecks(impl.ConfigFile, impl.BackupDir, impl.DbHandler, impl.DbInfo, impl.AppUser, impl.PostgresUser, impl.Service, impl.Logger)
+	}
+	if !impl.selectProfile(c, params.Profile) {
+		return
 	}
 
 	data := RunChecks(impl.Configuration, impl.Logger)
@@ -52,53 +93,30 @@ func (impl *ResourceConfigImpl) GetResourceConfigs(c *gin.Context) {
 	c.Data(http.StatusAccepted, "application/json", jsonData)
 }
 
-func (impl *ResourceConfigImpl) GetResourceConfigById(c *gin.Context, config GetResourceConfigByIdParamsConfig) {
+func (impl *ResourceConfigImpl) GetResourceConfigById(c *gin.Context, config string, params GetResourceConfigByIdParams) {
 	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
 		return
 	}
 
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
+
 	// Reuse the same reference that contains resource setting details
 	if impl.Configuration == nil {
-		impl.Configuration = InitChecks(impl.ConfigFile, impl.DbHandler, impl.AppUser, impl.PostgresUser, impl.Logger)
-	}
-
-	var configData *ResourceSetting
-	var err error
-	switch config {
-	case "autovacuum_work_mem":
-		configData, err = impl.Configuration.CheckAutovacuumWorkMem(impl.Logger)
-	case "dynamic_shared_memory_type":
-		configData, err = impl.Configuration.CheckDynamicSharedMemoryType(impl.Logger)
-	case "hash_mem_multiplier":
-		configData, err = impl.Configuration.CheckHashMemMultiplier(impl.Logger)
-	case "huge_page_size":
-		configData, err = impl.Configuration.CheckHugePageSize(impl.Logger)
-	case "huge_pages":
-		configData, err = impl.Configuration.CheckHugePages(impl.Logger)
-	case "maintenance_work_mem":
-		configData, err = impl.Configuration.CheckMaintenanceWorkMem(impl.Logger)
-	case "max_prepared_transactions":
-		configData, err = impl.Configuration.CheckMaxPreparedTransactions(impl.Logger)
-	case "max_stack_depth":
-		configData, err = impl.Configuration.CheckMaxStackDepth(impl.Logger)
-	case "shared_buffers":
-		configData, err = impl.Configuration.CheckSharedBuffers(impl.Logger)
-	case "shared_memory_type":
-		configData, err = impl.Configuration.CheckSharedMemoryType(impl.Logger)
-	case "temp_buffers":
-		configData, err = impl.Configuration.CheckTempBuffers(impl.Logger)
-	case "work_mem":
-		configData, err = impl.Configuration.CheckWorkMem(impl.Logger)
-	case "logical_decoding_work_mem":
-		configData, err = impl.Configuration.ChecklogicalDecodingWorkMem(impl.Logger)
-	default:
+		impl.Configuration = InitChecks(impl.ConfigFile, impl.BackupDir, impl.DbHandler, impl.DbInfo, impl.AppUser, impl.PostgresUser, impl.Service, impl.Logger)
+	}
+	if !impl.selectProfile(c, params.Profile) {
+		return
+	}
+
+	configData, err := RunCheck(impl.Configuration, config, impl.Logger)
+	if errors.Is(err, ErrUnknownCheck) {
 		errorMsg := &ErrorMessage{
 			ErrorMessage: fmt.Sprintf("No resource configuration with name: %s", config),
 		}
 		c.JSON(http.StatusBadRequest, errorMsg)
 		return
 	}
-
 	if err != nil {
 		errorMsg := &ErrorMessage{
 			ErrorMessage: "Could not get suggestion. See /var/log/postgrescrutiniser/error.log for more details",
@@ -109,15 +127,66 @@ func (impl *ResourceConfigImpl) GetResourceConfigById(c *gin.Context, config Get
 	c.JSON(http.StatusAccepted, configData)
 }
 
-// Accepts a request body containing an array of suggestions
-func (impl *ResourceConfigImpl) PatchResourceConfigs(c *gin.Context) {
+// Runs all checks and renders the results together with host facts they are based on
+func (impl *ResourceConfigImpl) GetReport(c *gin.Context, params GetReportParams) {
 	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
 		return
 	}
 
+	format := Json
+	if params.Format != nil {
+		format = *params.Format
+	}
+	if err := ValidateReportFormat(string(format)); err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: err.Error(),
+		}
+		c.JSON(http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
+
 	// Reuse the same reference that contains resource setting details
 	if impl.Configuration == nil {
-		impl.Configuration = InitChecks(impl.ConfigFile, impl.DbHandler, impl.AppUser, impl.PostgresUser, impl.Logger)
+		impl.Configuration = InitChecks(impl.ConfigFile, impl.BackupDir, impl.DbHandler, impl.DbInfo, impl.AppUser, impl.PostgresUser, impl.Service, impl.Logger)
+	}
+	if !impl.selectProfile(c, params.Profile) {
+		return
+	}
+
+	results := RunChecks(impl.Configuration, impl.Logger)
+	report := impl.Configuration.BuildReport(*results, impl.Logger)
+	content, contentType, err := report.Render(format)
+	if err != nil {
+		impl.Logger.LogError(fmt.Errorf("Failed rendering %s report: %v", format, err))
+		errorMsg := &ErrorMessage{
+			ErrorMessage: "Could not render report. See /var/log/postgrescrutiniser/error.log for more details",
+		}
+		c.JSON(http.StatusInternalServerError, errorMsg)
+		return
+	}
+
+	// JSON is also what the frontend displays, other formats are meant to be saved
+	if format != Json {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", ReportFileName(format)))
+	}
+	c.Data(http.StatusAccepted, contentType, content)
+}
+
+// Accepts a request body containing an array of suggestions. With `dry_run` they're only validated.
+func (impl *ResourceConfigImpl) PatchResourceConfigs(c *gin.Context, params PatchResourceConfigsParams) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
+
+	// Reuse the same reference that contains resource setting details
+	if impl.Configuration == nil {
+		impl.Configuration = InitChecks(impl.ConfigFile, impl.BackupDir, impl.DbHandler, impl.DbInfo, impl.AppUser, impl.PostgresUser, impl.Service, impl.Logger)
 	}
 
 	// Bind post body and validate
@@ -139,10 +208,36 @@ func (impl *ResourceConfigImpl) PatchResourceConfigs(c *gin.Context) {
 
 	// Reuse the same reference that contains resource setting details
 	if impl.Configuration == nil {
-		impl.Configuration = InitChecks(impl.ConfigFile, impl.DbHandler, impl.AppUser, impl.PostgresUser, impl.Logger)
+		impl.Configuration = InitChecks(impl.ConfigFile, impl.BackupDir, impl.DbHandler, impl.DbInfo, impl.AppUser, impl.PostgresUser, impl.Service, impl.Logger)
 	}
 
-	err := impl.Configuration.ApplySuggestions(&suggestions, impl.Logger)
+	if params.DryRun != nil && *params.DryRun {
+		validation, err := impl.Configuration.ValidateSuggestions(&suggestions, impl.Logger)
+		if err != nil {
+			errorMsg := &ErrorMessage{
+				ErrorMessage: "Could not validate suggestions. See /var/log/postgrescrutiniser/error.log for more details",
+			}
+			c.JSON(http.StatusInternalServerError, errorMsg)
+			return
+		}
+		c.JSON(http.StatusOK, validation)
+		return
+	}
+
+	if params.ApplyAt != nil || params.Window != nil {
+		impl.scheduleSuggestions(c, suggestions, params)
+		return
+	}
+
+	validation, err := impl.Configuration.ApplySuggestions(&suggestions, impl.Logger)
+	if errors.Is(err, ErrInvalidSuggestions) {
+		c.JSON(http.StatusUnprocessableEntity, validation)
+		return
+	}
+	if validation != nil && validation.RolledBack {
+		c.JSON(http.StatusInternalServerError, validation)
+		return
+	}
 	if err != nil {
 		errorMsg := &ErrorMessage{
 			ErrorMessage: fmt.Sprintf("%s. See /var/log/postgrescrutiniser/error.log for more details", err.Error()),
@@ -150,6 +245,201 @@ func (impl *ResourceConfigImpl) PatchResourceConfigs(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errorMsg)
 		return
 	}
+	impl.Audit.Record(SuggestionsAuditEntry(impl.Instance, auth.Username(c), suggestions), impl.Logger)
+	c.JSON(http.StatusCreated, validation)
+}
+
+// Validates suggestions and schedules them to be applied at `apply_at` or in `window`.
+// They are validated again when they run since settings may change in the meantime.
+func (impl *ResourceConfigImpl) scheduleSuggestions(c *gin.Context, suggestions PatchResourceConfigsJSONBody, params PatchResourceConfigsParams) {
+	validation, err := impl.Configuration.ValidateSuggestions(&suggestions, impl.Logger)
+	if err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: "Could not validate suggestions. See /var/log/postgrescrutiniser/error.log for more details",
+		}
+		c.JSON(http.StatusInternalServerError, errorMsg)
+		return
+	}
+	if !validation.Valid {
+		c.JSON(http.StatusUnprocessableEntity, validation)
+		return
+	}
+
+	scheduled := make([]schedule.ChangeSuggestion, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		scheduled = append(scheduled, schedule.ChangeSuggestion{Name: suggestion.Name, SuggestedValue: suggestion.SuggestedValue, Database: suggestion.Database, Role: suggestion.Role})
+	}
+	instanceId := impl.Instance
+	createdBy := auth.Username(c)
+	change, err := impl.Scheduler.Schedule(schedule.ScheduledChange{Kind: schedule.ApplySuggestions, Instance: &instanceId, CreatedBy: &createdBy, Suggestions: &scheduled}, params.ApplyAt, params.Window)
+	if errors.Is(err, schedule.ErrInvalidSchedule) || errors.Is(err, schedule.ErrWindowNotFound) {
+		c.JSON(http.StatusBadRequest, &ErrorMessage{ErrorMessage: err.Error()})
+		return
+	}
+	if err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: "Could not schedule suggestions. See /var/log/postgrescrutiniser/error.log for more details",
+		}
+		c.JSON(http.StatusInternalServerError, errorMsg)
+		return
+	}
+	c.JSON(http.StatusAccepted, change)
+}
+
+// Applies suggestions of a scheduled change, registered with the scheduler for `apply_suggestions` changes
+func (impl *ResourceConfigImpl) RunScheduledChange(change *schedule.ScheduledChange) (*schedule.ChangeOutcome, error) {
+	if change.Suggestions == nil || len(*change.Suggestions) == 0 {
+		return nil, fmt.Errorf("scheduled change %s has no suggestions", change.Id)
+	}
+
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
+
+	if impl.Configuration == nil {
+		impl.Configuration = InitChecks(impl.ConfigFile, impl.BackupDir, impl.DbHandler, impl.DbInfo, impl.AppUser, impl.PostgresUser, impl.Service, impl.Logger)
+	}
+
+	suggestions := make(PatchResourceConfigsJSONBody, 0, len(*change.Suggestions))
+	for _, suggestion := range *change.Suggestions {
+		suggestions = append(suggestions, ResourceConfigPatchSchema{Name: suggestion.Name, SuggestedValue: suggestion.SuggestedValue, Database: suggestion.Database, Role: suggestion.Role})
+	}
+	validation, err := impl.Configuration.ApplySuggestions(&suggestions, impl.Logger)
+	if validation == nil {
+		return nil, err
+	}
+
+	// Values valid when scheduled may not be anymore, e.g. after an upgrade
+	if errors.Is(err, ErrInvalidSuggestions) {
+		reasons := []string{}
+		for _, item := range validation.Items {
+			if !item.Valid {
+				reasons = append(reasons, fmt.Sprintf("%s: %s", item.Name, item.Reason))
+			}
+		}
+		err = fmt.Errorf("%w: %s", err, strings.Join(reasons, "; "))
+	}
+	if err == nil {
+		impl.Audit.Record(SuggestionsAuditEntry(impl.Instance, schedule.CreatedBy(change), suggestions), impl.Logger)
+	}
+	return &schedule.ChangeOutcome{Restarted: validation.Restarted, RolledBack: validation.RolledBack, Steps: validation.Steps}, err
+}
+
+// Returns settings that are waiting for PostgreSQL to be restarted
+func (impl *ResourceConfigImpl) GetPendingRestart(c *gin.Context) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
+
+	// Reuse the same reference that contains resource setting details
+	if impl.Configuration == nil {
+		impl.Configuration = InitChecks(impl.ConfigFile, impl.BackupDir, impl.DbHandler, impl.DbInfo, impl.AppUser, impl.PostgresUser, impl.Service, impl.Logger)
+	}
+
+	pending, err := impl.Configuration.GetPendingRestart(impl.Logger)
+	if err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: "Could not get settings pending restart. See /var/log/postgrescrutiniser/error.log for more details",
+		}
+		c.JSON(http.StatusInternalServerError, errorMsg)
+		return
+	}
+	c.JSON(http.StatusAccepted, pending)
+}
+
+// Returns configuration file entries and why they are or aren't in effect
+func (impl *ResourceConfigImpl) GetFileSettings(c *gin.Context, params GetFileSettingsParams) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
+
+	// Reuse the same reference that contains resource setting details
+	if impl.Configuration == nil {
+		impl.Configuration = InitChecks(impl.ConfigFile, impl.BackupDir, impl.DbHandler, impl.DbInfo, impl.AppUser, impl.PostgresUser, impl.Service, impl.Logger)
+	}
+
+	entries, err := impl.Configuration.GetFileSettings(params.All != nil && *params.All, impl.Logger)
+	if err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: "Could not read configuration file entries. See /var/log/postgrescrutiniser/error.log for more details",
+		}
+		c.JSON(http.StatusInternalServerError, errorMsg)
+		return
+	}
+	c.JSON(http.StatusAccepted, entries)
+}
+
+// Returns per-database and per-role setting overrides
+func (impl *ResourceConfigImpl) GetRoleSettings(c *gin.Context) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	settings, err := utils.GetRoleSettings(impl.DbHandler, impl.Logger)
+	if err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: "Could not get per-database and per-role settings. See /var/log/postgrescrutiniser/error.log for more details",
+		}
+		c.JSON(http.StatusInternalServerError, errorMsg)
+		return
+	}
+	c.JSON(http.StatusAccepted, settings)
+}
+
+// Accepts the same body as `PatchResourceConfigs`, but returns suggestions as a file instead of applying them
+func (impl *ResourceConfigImpl) ExportResourceConfigs(c *gin.Context, params ExportResourceConfigsParams) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	if err := ValidateExportFormat(string(params.Format)); err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: err.Error(),
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	// Bind post body and validate
+	suggestions := ExportResourceConfigsJSONBody{}
+	if err := c.BindJSON(&suggestions); err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: "incorrect payload format",
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorMsg)
+		return
+	}
+	if len(suggestions) == 0 {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: "empty payload array",
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
+
+	// Reuse the same reference that contains resource setting details. Units are taken from it.
+	if impl.Configuration == nil {
+		impl.Configuration = InitChecks(impl.ConfigFile, impl.BackupDir, impl.DbHandler, impl.DbInfo, impl.AppUser, impl.PostgresUser, impl.Service, impl.Logger)
+	}
+
+	content, contentType, err := impl.Configuration.ExportSuggestions(suggestions, params.Format)
+	if err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: err.Error(),
+		}
+		c.JSON(http.StatusBadRequest, errorMsg)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", ExportFileName(params.Format)))
+	c.Data(http.StatusOK, contentType, content)
 }
 
 func (impl *ResourceConfigImpl) DeleteResourceConfigs(c *gin.Context) {
@@ -157,9 +447,12 @@ func (impl *ResourceConfigImpl) DeleteResourceConfigs(c *gin.Context) {
 		return
 	}
 
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
+
 	// Reuse the same reference that contains resource setting details
 	if impl.Configuration == nil {
-		impl.Configuration = InitChecks(impl.ConfigFile, impl.DbHandler, impl.AppUser, impl.PostgresUser, impl.Logger)
+		impl.Configuration = InitChecks(impl.ConfigFile, impl.BackupDir, impl.DbHandler, impl.DbInfo, impl.AppUser, impl.PostgresUser, impl.Service, impl.Logger)
 	}
 
 	if err := impl.Configuration.DiscardConfigs(impl.Logger); err != nil {
@@ -167,5 +460,103 @@ func (impl *ResourceConfigImpl) DeleteResourceConfigs(c *gin.Context) {
 			ErrorMessage: err.Error(),
 		}
 		c.JSON(http.StatusInternalServerError, errorMsg)
+		return
+	}
+	impl.Audit.Record(utils.AuditEntry{Instance: impl.Instance, Actor: auth.Username(c), Action: utils.AuditReset}, impl.Logger)
+}
+
+// Returns worst case memory usage of current settings and of settings with suggestions applied
+func (impl *ResourceConfigImpl) GetMemoryBudget(c *gin.Context) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
+
+	// Reuse the same reference that contains resource setting details
+	if impl.Configuration == nil {
+		impl.Configuration = InitChecks(impl.ConfigFile, impl.BackupDir, impl.DbHandler, impl.DbInfo, impl.AppUser, impl.PostgresUser, impl.Service, impl.Logger)
+	}
+
+	// Suggestions have to be up to date for the second estimate
+	RunChecks(impl.Configuration, impl.Logger)
+
+	current, err := impl.Configuration.CalculateMemoryBudget(false, impl.Logger)
+	if err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: fmt.Sprintf("%s. See /var/log/postgrescrutiniser/error.log for more details", err.Error()),
+		}
+		c.JSON(http.StatusInternalServerError, errorMsg)
+		return
+	}
+	withSuggestions, err := impl.Configuration.CalculateMemoryBudget(true, impl.Logger)
+	if err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: fmt.Sprintf("%s. See /var/log/postgrescrutiniser/error.log for more details", err.Error()),
+		}
+		c.JSON(http.StatusInternalServerError, errorMsg)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, &MemoryBudget{
+		Current:         *current,
+		WithSuggestions: *withSuggestions,
+	})
+}
+
+// Returns the workload profile checks are run with and all available profiles
+func (impl *ResourceConfigImpl) GetWorkloadProfile(c *gin.Context) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
+
+	// Reuse the same reference that contains resource setting details
+	if impl.Configuration == nil {
+		impl.Configuration = InitChecks(impl.ConfigFile, impl.BackupDir, impl.DbHandler, impl.DbInfo, impl.AppUser, impl.PostgresUser, impl.Service, impl.Logger)
+	}
+
+	profiles := WorkloadProfileList{
+		Selected:  WorkloadProfileName(impl.Configuration.Profile().Name),
+		Available: []WorkloadProfileDescription{},
+	}
+	for _, profile := range WorkloadProfiles() {
+		profiles.Available = append(profiles.Available, WorkloadProfileDescription{
+			Name:        WorkloadProfileName(profile.Name),
+			Description: profile.Description,
+		})
+	}
+	c.JSON(http.StatusAccepted, &profiles)
+}
+
+// Changes the workload profile checks are run with
+func (impl *ResourceConfigImpl) PutWorkloadProfile(c *gin.Context) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	impl.Mutex.Lock()
+	defer impl.Mutex.Unlock()
+
+	// Reuse the same reference that contains resource setting details
+	if impl.Configuration == nil {
+		impl.Configuration = InitChecks(impl.ConfigFile, impl.BackupDir, impl.DbHandler, impl.DbInfo, impl.AppUser, impl.PostgresUser, impl.Service, impl.Logger)
+	}
+
+	selection := PutWorkloadProfileJSONRequestBody{}
+	if err := c.BindJSON(&selection); err != nil {
+		errorMsg := &ErrorMessage{
+			ErrorMessage: "incorrect payload format",
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorMsg)
+		return
+	}
+	profile := Profile(selection.Profile)
+	if !impl.selectProfile(c, &profile) {
+		return
 	}
+	c.Status(http.StatusCreated)
 }
@@ -18,29 +18,97 @@ import (
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xYW2/bNhT+KwS3hxTQbDlpt8JvzW7Ibujabn3oDOGYOpJYi6TKix0v8H8fDiXbiiwk",
-	"LYoFLdCXQBap79y+c8sNF0Y1RqP2js9vuBMVKoiPP1pr7O/oHJRIvxtrGrReYjxFOs3U8dhvG+Rz7ryV",
-	"uuS7XcItvgvSYs7nbwbXF8n+ulm+ReF5vO1MsAK/N7qQZRRxDaqpkc/f3PAcPcja8Tl/VUnHXChLdF4a",
-	"zTbgmIIc2XLLcrmWudQlE8Fa1J7BGmQNyxqZQmXs9uz8/OLb2ex8kqary0f0yWMGOqeHymyYAr1lELxZ",
-	"gwhBZQqus42xK7Tu7OIRA4vMoecJRx1UtoaoEE94aXwWLeTzAmqHCdegyLweFuFkChVPeKc95gQR6N7s",
-	"YpZ+d84THrT0fM5Xlzzh+8NvZnyX9F3wK1qNNWvAgkKPlmmbVaHEBkp0jNyDnnnD0gm7RAHBITMF8xX4",
-	"hD03zpcWX/75GxOgtfGMwoTOM0JgEWJg4I0pisToxNvt7i5jCSDbA5zaaIriaGDPPHq/WyQDfh2spUcn",
-	"rGwo2nzOf2gPmNSFsYqCvam2DFiEi2w4iObJkJW3DBsiuwaFLCQ6tqnAM/qU/BaBo099pJYJdc4qWOMY",
-	"fM85d8Gjr9AyUaFYsdJ4Bpq1Xx0gl8bUCJowW/cO4f4A1YUV97qNKdQlocssOg/WnwK97rQB1l0hBu1z",
-	"l0Hho6agS7KepLWBG9P0JOhDWX/HIBET2UbWNVviMVqdKBu0jn4m54xZ1DJoiPyXlp7coRBcsKgo+89c",
-	"wpRL2OoyYU/pD3oxeTSGeae69zl5UOpiuPaQ91e65+BF9TKW3WHR+9AikqZpGkvF+yfkaeJ9FNsegADj",
-	"3h4KPvU7aYciWOm30duttUsEi/ZZ8NXx10/GKqAi9cvrVwQdbxPR4+lRo8r7hu8ImGrRqa0vujizNtDB",
-	"Ah049uz5FYFIT2Hm+4IsbPBSSxclrNG6FmQ2SScUVG4a1NBIPucX8VXCG/BVNGK6Z1SrQ41+xPMWHXrH",
-	"oK6ZMNpTfkjtZB7D2rRKuHf1hNg2EUYX7GwjG+on3u2/oNwhrkRDrvJYjUnai1uMJrZZdI3RrnXyefpk",
-	"pBwGIdBRpWlvko2P0xld7KTRIzRNLUWUN33r6MP9jEJPX1ss+Jx/NT0OMdNugpneGl9ilG7Lf2VWqJmS",
-	"zhHZjGUKauoomJMmT9L0wTR5iXaNlsVYtF0gXikg1P7BlAgarxsUlIadDruEeygdJdmBXwtqcejHuO6D",
-	"1S299reJNUfed93Oogu1d//oEyr9jP5eHp1/kD+kR+Xuc8xg7twd0hushe2Yq74w9zNlbkOt9pS7UTV0",
-	"zGiM3jQWewuGOyFq7NhjVI1z9KXJt/83S/szwyhhjx3S24C7kzya3dGtBllrEci1HemLUNfblvAPR7Mr",
-	"vYZa5odNZUku/qSS7lPl/C45DgfTmzayOxJ+dxFn3a4ierW8m8buqdmX26s8jibdauq67b0vRnez5KBP",
-	"0MZattv1fgbmrgKLebYMRUFYNGnxeRx8+GHtbL/mQ9InPVfT0keeGZ+k860GJUXWCWv/U5DFpEp4Ba6i",
-	"V5kKtZdUKGg+O0zWmZP/Yv8FKalAEgdAC+zLUXCdNRabKMVb0A7EvsLQmfMgVlmOTbTuxPRR9Tyqpnen",
-	"J602pRRQZzkKk0tdHjVZnM7Ui4/stB/SYN+3oT58fTmw9ktH/yw6en+ni3Wmv829WRCrXbSnrULB1t3W",
-	"Np9OayOgrozz86fp03RKm9VusfsvAAD//0llGoMPFQAA",
+	"H4sIAAAAAAAC/+x9e3Pctnb4V8Hw9+tcuaVXK9lxXc3cP+RHbtzasSvp3kwn69lgSewSVyBAA6DW24y+",
+	"e+ccgCRIYh+yfF079T9JtAQPDg7O+8H8nmSqrJRk0prk7PekopqWzDKNf9GqEptzC/+ZM5NpXlmuZHKW",
+	"XNSS2IKRrKByxQi1xBbcEMtLRrg0ltGcqCXRfFVYQtd0kxLDGDmmFT82WcHyWrD8oXvbJGnCAeiHmulN",
+	"kiaSliw5c5vPqU3SBF4pKaCxVLqkNjlLcmrZQ9gvSRO7qeAFYzWXq+T2Nk0qrZZcsDHivyh9LRTNiV9B",
+	"rCK6liQrWHZtyJrbYkIumWAZvEC4IRXThhvLcqLcmQ3TN0ynM2kUEdQyTTIqhHtX1Z4SLR3JNWMVqQ2X",
+	"K8LtTG45bINweNb/r9kyOUv+33F3RcfuqTle+3O8c+/9DFDg4Gsuc7Xee2Hc/SXZR0tUltVaM5kxuDLE",
+	"vqRcWiYp/OYgxm91y2E8EuFZhld02zxEPnuptdJvmDF0hXdWaVUxbTnDpwyezsvu8fi+NftQc83y5OzX",
+	"wfL3LXuoxd9ZZpPbNMmUXPLVcyTFpWXVeEuaObr9njBZlwBVMyB3AlsZS7VN0qRgVNhijqwDD5QQC5pd",
+	"B1s2GKZJzizlwkQYsqCWrKkhuZIsJUqTdbEh3JIl5YLlSQSWqbOMGRNQYqGUYFSOSOGP0b3SITKiS5p8",
+	"fLhSD/2PteXCTAISBY8f8rJS2jp1YYvkLFlxW9SLSabK478ItdiY6aOT43fK2JVml5muLZfcMH0M5GEy",
+	"P0boiC0w7yWzFo42voWqEpzlY6JVqzm8ODfuTTPxK1OyLpgtmEbuZtLqDdLWPyaeDMtaiE2SjqiXOt45",
+	"YD9cl+JVdTtlqhY5kcqSBWu2jN2fk5LhHiDCTgAZ8fvEXlY3TGue50zOF5sxlB9Bp1GZE8FlC86hZ4HT",
+	"LL1mhrDlkmW2leml0sQUNFdrluNizkwKb85kteoObVStM9Qd/n14r8OHKMnMTMaQ1kqwuV/JIkLwtnk0",
+	"IADsVa3m+WKOEPyvE3JVsA1RUmyQzBtQ4oYZw5VsIHBNcmrpgho2k0oTeD8lRsGzDcjan2yjC42ltnaI",
+	"c8tKs0/1atWx7G17WKo13cDfpuPm/iH/RkXNCDVkrbm1TDZK2Ov9sZgjueNm7DlqsFpT+Js4Q9ZeNDeE",
+	"y+0QgTPGEF8Dv3BJul37EFUAkUvLVkwjSCTeGFwjcXw5RMwzT9pxHF8S6i2pW2eYNfiaAZnwBE1nssf7",
+	"c1pbNQdV7jahtgfD7VU5HWQ+iAksn+Dyo/PXVy8vyOV/XV69fPOgB9fh4hkdDDqhpOAr0CiV5kpzi3Ik",
+	"STa6AJMSwa8d1TJVllTmMwm0Trvz3DgWkDlBBdKnTgGKSronyI2N6elUSUOyJE220KL3JGkUWswiIS7b",
+	"uLTzc4CQupYSpBEIEnW3QpuD2q0Tgx4f91gwDQ/m2ChmrEtWKr15VucrZscmwrkudp/MhkBeGstLalmC",
+	"7pIt5qZerZiB85tPgzOgQINTBPy+A7YwRwfd6kC8cA8I+1gJyvGekKcaSJGrZx8zxnIzt8pSMXf7xzwT",
+	"Z0rXShtLMmoYcUtJDZ4VMEapNHPygKAalvEQYwa21bAHqdqQNK8sK2P6dvch3jiMG1/kP1+TksIBUNct",
+	"NpaZCflJGetxRgcMJXilVV01Bxa85BakFf5piFBrppO0C0a4tE8eR/UjEm8OxBvjdlmXYK2oEASp0aJ0",
+	"COgBz/Wo0Nt2y3U3V7HDIxzwJl4AeMQfaVk5o+SwPXv66OnTJ9On06nDuxY0OUtK+hE0knSRlDk6mU4f",
+	"kH8GbroGHI4eT//tyfUz+KmgpoCf5mUtLAeVoI9OH3ThRAAluU0HcuFRiMR5caa9A4mD0wzB/6TWgUIH",
+	"FzOjIqvBAN3B5UPf3xYtitwAZ6I/dqiaDQ8DmMYusWIy53J14QKXrd72vdxSv8d8p1VZalV6+ziynhH3",
+	"lC7BnFPiI66UsLKyGxDDWl5Ltd7h48zv5DZ55P1tcgMHJVwetmEteSRB8lfJLZCtZNTUmpVMWnJkUlKa",
+	"lFw/S8lT+Aez2eRB8uVtswPvUR/eXZ+EMX7SrA3/egy0YpJpkABI2hyYq0mTQpm95tvtCHo68LAjUv8c",
+	"QnHgl1pYQ5TOmWY5WWzaeMIT4DA/Hzfd5enXZUn15kA4fvHwSnpE89ToQAeH3X4TPykTuQ16Q7mgC8H2",
+	"2cZ2IYRRgaG8m6p0Ir1F7gI3HNaFysSAs+xUQ4w7sqqOXPO7v5ptaG9jsbh2Q9MP2DhKoiZvb6QX83TQ",
+	"HDnnTkqibpNuNTpqGxackRwBMmnjXyhNCqrzNdWMBGCiSqFUeWQ7wW8YZD3aFKamktAV5dJYQr2iSIla",
+	"LjEj4FZG1O8asKaSio2Jm7DDU6pNLhUgQm41rprSxOE2v2Ha+GRbH3DAi37NCPRS6QPNglXaZw8HTph7",
+	"gEwAGQOSc80yq5qolxwZk6ekyHO4LL9D9H7u64geJme1QZnOlI65Pc/hZ0w+C8EENyUJog8CXAYZkTwe",
+	"zQ80E/JbIDyjC+sLfRrksAee5kgVDYXIyfngcN2lbVd9W52ZjFq2il5E4+ivqUgJxM03NKvrMiWVoFKC",
+	"rDTkgwsPfc+7pHWbqIxLuFM00MWG0MBh9BfzD0gRfooLFODlfR++JPucrEMySfMtWyiZkmlUaA/ILr11",
+	"uU11nQb8nRJzzauKoaC6vEc0ee7Jvs1VvWwWOIQD9SJVWz7Zc30W2XiQVzjI6cA3L9sXY27HF3Q4n7tM",
+	"hr+6fZwXdzZbSWyvc+SADu+kk6s+M/cZb4dW6ByzgVZA8xEUTgJeQ47Z8swz1paH/Wveo1U9Cv3X2t27",
+	"reKnc+d3MtyLxX8NMkTJVdExKEg5yFxJcwaecM5vOAY7PksV+FFOMx6dnj56cnJyOplOITxfbMhjzFgu",
+	"NqRQa1JSuQnU5hzCfIjpmTZHjx6giTHM+uwl3CcglKTJStm5r64sqTCsK/N2sJrcQIQlzpKTRyfTfz1t",
+	"mOYsuX7WMtJZ8vAkuU1DEvwH05KJoAwr9byoV6yiK2aaEM8qMp2QZyyjtfHsTW0a2uiMSqksgQtkxhKA",
+	"QBDE4IC/q+UyVTK1enO767AAYN4AGJ9RLZfdAYPjwe+379O7pgQ/0fgEBxtCNhXL+JKDv4jh+qZCuiFg",
+	"00ZZrhBW0JtowBcQZxd4l3xEcSErZdvUeDSteC976cXTzJvS7tZUaJuLwMjbS7XPU6BpaLKvjR4bYxqo",
+	"kljsmitmsITYFra6cP9Phoxd4gm5YNS4VoUVv3FlpU59RhDYEreMK31YTdW+otGzBBDOGIxnUsImqwnJ",
+	"2ZLWAuKakXuRzqQvhxBXDWnqcuhPG6Zn8jNVv/o4hr5Ma8I5hnhQ/HOPCN1TfrtTsSyOwGGezj63pMn9",
+	"QLcAFwJKzO07ngObVFDTkHCYWzKIuZgmuIy4ItnV+bPXL7FEii6FtyNUKLkyPHfH7gCSo06bN7EalGkf",
+	"HJpz+brcn7/dw+1xIPdb8XfUZsVl2ycTGvS7GsjpdDpFM3i4sQlWB7C1Agy8M+XOPH79yQ8/PHoSs0pe",
+	"vMfkPEeVhlVVx1wvzq/On51fviSTyYRcvrzChgJsQOp0BBT5g96jsHQ7mckrtfJFKoDqivyuqmjCjS7e",
+	"vnabvPp5tGlc/dzPoChxyPFbrHpHh5d3HvuTopr7q48tZd7BxlGGVzt6fLazywv/BKndNI+0t4tq/Ybp",
+	"TccrfEkEW1qiarvrSg+8rgsltmxtVbM13tWebdsLuY/aiDVnXQRk/Qd1Z7Wtmq4RLOa0wO9kTTk6fq6Z",
+	"MvWpzAXLmx6XBhA5f/cq2X24ZunkcrD5Zz3jmi3aRlR31Nbo/I0KntOm/6/PrXcrXwcwmc65k4ahPfMe",
+	"Jcu3u52B1wdORftGUyEDxYJ9UX3HiDvjS1zrIstnspaCGYNpYeepozaqlLElNQAqU9JCT6jLc+QTci7W",
+	"dGMIRjGoo3Jg+1r2Gr0C7xKaIFk+BzJvPw/W3DS74ao28RYd8BYrph+2st38gBLXlg4wD1zVlsB+6Uwy",
+	"jvAXPqKjoWsS684jqlscEDnnOfaHQdIeIA/ovOXoxrIq4lFdIPXTrorp2kZ9ZAPnahpHCULAGqgcXe1L",
+	"dGHDGzjUpxp1u0aY8AZ4fvuFOW0XEJMb4l5J97WgNss6Nu8zSdeH4OgXMyBjORpndhzj7g5r/CIfswRs",
+	"D2RtmQqNpGQgXqRr9h2nfg8zXH2iNXyHdQtqfDxCDJPRPe7lhUgoJgj+3/ucAq+iwa9GdnRFCBIQzlfH",
+	"EN1+OCUHXBAG1dTEijq/FJsIXTygfqQ2ZrJPidl7cXmj91yfQRfOa+oVExTPSNvrHdVvu3yFe912K4Tj",
+	"bXde4CGQtfttCAC2SImPQ0FHQVHEvYdZdFmXhDb370rpAV/ctd+guc0RZ3YYpq0kR+645aqYkhiGjv1O",
+	"pU5aE1OoKkgxnyVX8Ca2Xz4+hRhqSrCoCrLwBIMqkoMbbusKCqVHJ4/+CX94MPEpbYw2wcEPIuZZUtUL",
+	"wbNZMpkl2IhgZgk6+kGIPPf/MhkVbL6kmVWa/JlMJ9PTlIyX2UIzUyiRkz8TwAtDWcA9OUui+427pQ5U",
+	"WgjVyeWEXDZHRBJZRRau9rousNjrCmQshyfcfoYiGcpRu+febGVwA6OIK5bC8E7q1vxIpCKEhpR8qKng",
+	"S5BnRx7P3LsloCV4Az1EeHfv3WDE50WI07g7tPcw0mZ2zSVGkg1U74QhaGzlZFTaeOdZZ4c+ZSIpog9C",
+	"9A44+Wu+s7/lYHd8B0GjffwC+fpznLuFFdTCDzn5z57uTSe4ErZK0kQJCv9as0WSJiX/iIBzZq6tqqK9",
+	"3gOw7XzbQEm2LR5uh5H2qLoWkPtSpAE1JgKSPqs1txsUPN/myahm+ry2RffXj023xL//ctUMm6HdxKcd",
+	"HxfWVm7kDBRNzEP3Df+98Mk0sSq3SJJxMAlmq+ldSU4m0wlk3xJVMUkrnpwlj/CnFENUPMQx+9iGrb5n",
+	"a4iJBM3db9oww6S60sQqJbynWlIJ/StB+NKY55kMMkhNJAFyX4ZZKJ9LI1foErVTFwuVb2Dvd+dXz38i",
+	"x03ScjKTPytbACDeTbL0JiMxPAK2QXRf5clZ8hLPfdFLfBqkTDdt+uuQGOaDwLOGaAaZaFdrwBWUDBvM",
+	"uMxEnbvZmpRQafhCsJl0i2+oNv5JRa1Wknso+UbSkmcDei+E8oFaJlSdS2r5DatW8M5MUvJc1BhDmIpl",
+	"xEheVWz7mKdv8AllweqahZOSjaybD8J32CRp4k/gmAkwhkchNhGxv33vtmHGPlP5po2SnKVE3zTDEx7/",
+	"3XvqHRIHNkhuS2SP1Ont7fDI+IOplDROwE+n0x0YAjF6CI6tbrh8Q8u968HJPMaRid0rb9MhX7ohQtKg",
+	"D6d9PJ3eib67yNobho3s/8rFSj5OS9viNEps16PWhoaI3skXQ+9KQfqi5Mb4IKKkAlBlufMFsVD4xbCp",
+	"JftYOf/UVY/x6unKuJlex8DJe/j1GFTCw7C52E8cDWt/xppmSrIJw2MdlUfDqdEHrm7mlG8wqLrBrgku",
+	"Z9I1vk/ISzfLY1zdXhKWczjBUMvlHBNaoI3PgkEyaCyE/H4zX7fYEDqTbjjODRl3826LTQi1S8BBYmzT",
+	"jcINJ9t8KRfbV2/YTPbm1vq6/y/M/tgN+e7V+ufCKCK4sS2J0co5CpGGQG8hr1lptRCsdH2Nws3oQ4eL",
+	"Z7EtXxUQIjaY3qWv3o8U0+nnV53h2HNUWR6icr4Wmf7hCyq/Szf4gLVnL9Bft1JxTVUPF+38YlSpXDBb",
+	"a+ncr23zdmrZNhi0iTnqgrrO43Mzq0L0XchmOh7OSrWL1SMTe3HpfRMOYN5TOA4d9vsuBH8oIfATRg+D",
+	"ZO0O29qVdwplGJFs3TSggtaPjIiFQytBeWwmj8LsfzPm5J8/8GUAnIgY23Cc6XNVMKjs+maXJqnrKBqX",
+	"l3e9cbvkS5iT+ITfd8Pyh5apLhlziEmJjujQYEIHbQlYjq4h2K82MS7/pZ/k+Ucahlg68Jvj5a+VldKk",
+	"qu22xo7Dueewj2aNGOldHWWkT0ta3IGHWmQPS02cHDT15oxF7xtD/0upgTAl8F2lfzMqvRusjmv0Gnx5",
+	"IVr5k3DTrZbHSi1tJlmDVAPIX6GMJVBh7CWXZxI7aJqZwAl5Q/V1DskjgP3T1ZvXKORtbYhYnl0z67Y2",
+	"BdVcrlJyeX7x6kd8nKmczaTJqOskzKkpForq3MQ9pQt33j0ZAbfKp7sm5IW7QKxE4lXtybSOM6v+rdKf",
+	"NUmTwpaYFaCaL2OZ1DTOAx3arTW+d/pg/yT5KNuJaP/LGNRAGvCWTicnkykRyrOGksyPy4PKDlgjidVl",
+	"MGWKtDokt9rS91tOr4ICa+/2uyL9ZhRpOOUimI1U9TXDz3KhPnX4Ey7xiGq55btha14xQ7g1zRsPRlrt",
+	"Be42rnQNdMIPY3y+h2LfJLulu6MvYK9m9SDDkIUfK9lmIPdUTL+UVfqEeuD39MMfmOcrKPZu++qjQbdC",
+	"afd5uMDdnJCX2EgM3NR2d+KXVpoPloRNp0uujYXSUlvkClsOODSJb3z9rQx6SCOShKXpO3YfYIWpQbCf",
+	"TW/dbuglcA3J6P0ymhUNQlu80lxv5q75bEcFar+32XyX+4Cl/lvMf6RmgLsxfXSwI8L8L1xbfdpcqenu",
+	"1PQGEk8/o4I6FLmLLSZEMxSfYc7hcwYdwxmgmPIKpAMDykCwIVBsIPjm0aYtmlryW/OR99/wK71ckt8c",
+	"w/6WdpXxgZogC7YExbJgoBI8sJn8GnItoPS6qaI0eXx6+sVZ5W1c9/oKum+zbxRq8HHsT7F4SrK3y62O",
+	"yG7M07uQ//0hJnNCXi3vMMaDvBl8E39NzUy60RRcHmiCZqWBu/YfynfDOkBVZwxw2K1rt/MNEcR/h34m",
+	"v2aDHsZMx787HXO7t7JAif9eQxa4uH54dI8r+2zzKt9ngaUfcxm4z6BEXCW67VxNIBUFA0X1cgmwvO3F",
+	"0cDwK6Lgle5qvftq0z59v/rrzZm0lPnuyH87uRJ1eNfb3pHMdljatb8NPtz/AD+FjpnHmYx/AiCNzcYr",
+	"Pfw1Msfv/q8AMwn9Ad3H2gGfeDaHy+7/GeBcDf/FlS2huOr1sH2BaFp9bxH7PyCBwbgD2sBw0OHX92BX",
+	"XOnUWchaCz/QcHZ8LFRGRaGMPXs6fTo9phUHR+l/BgC6VTEW0mkAAA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -3,25 +3,50 @@
 // Code generated by github.com/deepmap/oapi-codegen version v1.12.4 DO NOT EDIT.
 package resourceConfig
 
+import (
+	"time"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+	"github.com/Globys031/PostgreScrutiniser/backend/web/schedule"
+)
+
 const (
 	BearerAuthScopes = "bearerAuth.Scopes"
 )
 
-// Defines values for GetResourceConfigByIdParamsConfig.
+// Defines values for FileSettingStatus.
+const (
+	Applied              FileSettingStatus = "applied"
+	Error                FileSettingStatus = "error"
+	Overridden           FileSettingStatus = "overridden"
+	OverriddenByAutoConf FileSettingStatus = "overridden_by_auto_conf"
+	Shadowed             FileSettingStatus = "shadowed"
+)
+
+// Defines values for WorkloadProfileName.
+const (
+	Desktop WorkloadProfileName = "desktop"
+	Mixed   WorkloadProfileName = "mixed"
+	Olap    WorkloadProfileName = "olap"
+	Oltp    WorkloadProfileName = "oltp"
+	Web     WorkloadProfileName = "web"
+)
+
+// Defines values for ExportResourceConfigsParamsFormat.
 const (
-	AutovacuumWorkMem       GetResourceConfigByIdParamsConfig = "autovacuum_work_mem"
-	DynamicSharedMemoryType GetResourceConfigByIdParamsConfig = "dynamic_shared_memory_type"
-	HashMemMultiplier       GetResourceConfigByIdParamsConfig = "hash_mem_multiplier"
-	HugePageSize            GetResourceConfigByIdParamsConfig = "huge_page_size"
-	HugePages               GetResourceConfigByIdParamsConfig = "huge_pages"
-	LogicalDecodingWorkMem  GetResourceConfigByIdParamsConfig = "logical_decoding_work_mem"
-	MaintenanceWorkMem      GetResourceConfigByIdParamsConfig = "maintenance_work_mem"
-	MaxPreparedTransactions GetResourceConfigByIdParamsConfig = "max_prepared_transactions"
-	MaxStackDepth           GetResourceConfigByIdParamsConfig = "max_stack_depth"
-	SharedBuffers           GetResourceConfigByIdParamsConfig = "shared_buffers"
-	SharedMemoryType        GetResourceConfigByIdParamsConfig = "shared_memory_type"
-	TempBuffers             GetResourceConfigByIdParamsConfig = "temp_buffers"
-	WorkMem                 GetResourceConfigByIdParamsConfig = "work_mem"
+	Ansible       ExportResourceConfigsParamsFormat = "ansible"
+	Cloudnativepg ExportResourceConfigsParamsFormat = "cloudnativepg"
+	Conf          ExportResourceConfigsParamsFormat = "conf"
+	Patroni       ExportResourceConfigsParamsFormat = "patroni"
+	Sql           ExportResourceConfigsParamsFormat = "sql"
+)
+
+// Defines values for GetReportParamsFormat.
+const (
+	Html     GetReportParamsFormat = "html"
+	Json     GetReportParamsFormat = "json"
+	Markdown GetReportParamsFormat = "markdown"
+	Sarif    GetReportParamsFormat = "sarif"
 )
 
 // ErrorMessage defines model for ErrorMessage.
@@ -29,6 +54,190 @@ type ErrorMessage struct {
 	ErrorMessage string `json:"error_message"`
 }
 
+// ConfigChangeStep defines model for configChangeStep.
+type ConfigChangeStep = utils.ChangeStep
+
+// FileSetting defines model for fileSetting.
+type FileSetting struct {
+	// Applied pg_file_settings.applied, whether the entry was applied successfully
+	Applied bool `json:"applied"`
+
+	// Error pg_file_settings.error, why the entry could not be applied
+	Error *string `json:"error,omitempty"`
+
+	// Name Name of the setting
+	Name string `json:"name"`
+
+	// OverriddenBy File and line of the entry that takes effect instead for shadowed entries, the
+	// pg_settings.source in effect for overridden ones
+	OverriddenBy *string `json:"overridden_by,omitempty"`
+
+	// RoleOverrides Overrides of the setting in pg_db_role_setting. They only apply to sessions of their database
+	// or role, so they don't change status
+	RoleOverrides *[]RoleSetting `json:"role_overrides,omitempty"`
+
+	// Setting Value as written in the file
+	Setting string `json:"setting"`
+
+	// Sourcefile Configuration file the entry is in
+	Sourcefile string `json:"sourcefile"`
+
+	// Sourceline Line in sourcefile the entry is on
+	Sourceline int `json:"sourceline"`
+
+	// Status applied if the entry is in effect, shadowed if a later entry sets the same setting,
+	// overridden_by_auto_conf if that later entry is in postgresql.auto.conf (ALTER SYSTEM),
+	// overridden if a source with a higher priority than configuration files, like the command
+	// line, sets the value and error if the entry has an error
+	Status FileSettingStatus `json:"status"`
+
+	// Value Value the server is running with
+	Value *string `json:"value,omitempty"`
+}
+
+// FileSettingStatus applied if the entry is in effect, shadowed if a later entry sets the same setting,
+// overridden_by_auto_conf if that later entry is in postgresql.auto.conf (ALTER SYSTEM),
+// overridden if a source with a higher priority than configuration files, like the command
+// line, sets the value and error if the entry has an error
+type FileSettingStatus string
+
+// MemoryBudget defines model for memoryBudget.
+type MemoryBudget struct {
+	Current         MemoryBudgetEstimate `json:"current"`
+	WithSuggestions MemoryBudgetEstimate `json:"with_suggestions"`
+}
+
+// MemoryBudgetEstimate defines model for memoryBudgetEstimate.
+type MemoryBudgetEstimate struct {
+	// Details Details explaining the estimate
+	Details string `json:"details"`
+
+	// ExceedsTotalMemory Whether worst case memory usage is more than total server memory
+	ExceedsTotalMemory bool               `json:"exceeds_total_memory"`
+	Items              []MemoryBudgetItem `json:"items"`
+
+	// TotalMemory Memory PostgreSQL may use in bytes. Host memory, or the cgroup memory limit if it is lower
+	TotalMemory int64 `json:"total_memory"`
+
+	// WorstCase Sum of all items in bytes
+	WorstCase int64 `json:"worst_case"`
+}
+
+// MemoryBudgetItem defines model for memoryBudgetItem.
+type MemoryBudgetItem struct {
+	// Bytes Worst case memory usage in bytes
+	Bytes int64 `json:"bytes"`
+
+	// Formula How the value was calculated
+	Formula string `json:"formula"`
+
+	// Name What the memory is used for
+	Name string `json:"name"`
+}
+
+// PendingRestartSetting defines model for pendingRestartSetting.
+type PendingRestartSetting struct {
+	// Name Name of the setting
+	Name string `json:"name"`
+
+	// PendingValue Value from the configuration files that takes effect after a restart, empty if unknown
+	PendingValue string `json:"pending_value"`
+
+	// SourceFile Configuration file the pending value is set in, empty if unknown
+	SourceFile string `json:"source_file"`
+
+	// Unit Unit of measurement (s, ms, kB, 8kB, etc.)
+	Unit string `json:"unit"`
+
+	// Value Value the server is running with
+	Value string `json:"value"`
+}
+
+// Report defines model for report.
+type Report struct {
+	GeneratedAt time.Time  `json:"generated_at"`
+	Host        ReportHost `json:"host"`
+
+	// Settings Check results ordered by setting name
+	Settings []ReportSetting `json:"settings"`
+	Summary  ReportSummary   `json:"summary"`
+}
+
+// ReportHost defines model for reportHost.
+type ReportHost struct {
+	// AvailableMemory Memory available to PostgreSQL in bytes
+	AvailableMemory int64 `json:"available_memory"`
+
+	// ConfigFile postgresql.conf the settings come from
+	ConfigFile string `json:"config_file"`
+
+	// Cpu CPUs available to PostgreSQL
+	Cpu string `json:"cpu"`
+
+	// Hostname Host the report was generated on
+	Hostname string `json:"hostname"`
+
+	// MemorySource Where memory figures come from (host, cgroup or hardware description)
+	MemorySource string `json:"memory_source"`
+
+	// Mode live when checks ran against a server, offline when configuration files were analysed
+	Mode string `json:"mode"`
+
+	// Profile Workload profile checks were run with
+	Profile string `json:"profile"`
+
+	// ServerVersion PostgreSQL version checks were run for, empty if unknown
+	ServerVersion string `json:"server_version"`
+
+	// Storage Storage the data directory is on (ssd, hdd or unknown)
+	Storage string `json:"storage"`
+
+	// TotalMemory Memory PostgreSQL may use in bytes
+	TotalMemory int64 `json:"total_memory"`
+
+	// UsableCores Cores parallelism suggestions are based on
+	UsableCores int `json:"usable_cores"`
+}
+
+// ReportSetting defines model for reportSetting.
+type ReportSetting struct {
+	// Category memory, wal, autovacuum, planner, parallel or connections
+	Category string `json:"category"`
+
+	// Details Details informing why a value was suggested
+	Details string `json:"details"`
+
+	// Name Name of the setting
+	Name string `json:"name"`
+
+	// SourceFile Configuration file the value was set in, if known
+	SourceFile string `json:"source_file"`
+
+	// SourceLine Line in source_file the value was set on, 0 if unknown
+	SourceLine int `json:"source_line"`
+
+	// Status One of ok, suggestion, skipped or error
+	Status string `json:"status"`
+
+	// SuggestedValue Suggested value, empty if no change is suggested
+	SuggestedValue   string             `json:"suggested_value"`
+	TableSuggestions *[]TableSuggestion `json:"table_suggestions,omitempty"`
+
+	// Unit Unit of measurement (s, ms, kB, 8kB, etc.)
+	Unit string `json:"unit"`
+
+	// Value Current value of the setting
+	Value string `json:"value"`
+}
+
+// ReportSummary defines model for reportSummary.
+type ReportSummary struct {
+	Checks      int `json:"checks"`
+	Errors      int `json:"errors"`
+	Skipped     int `json:"skipped"`
+	Suggestions int `json:"suggestions"`
+}
+
 // ResourceConfig defines model for resourceConfig.
 type ResourceConfig struct {
 	// Details Details informing why a value was suggested
@@ -46,9 +255,25 @@ type ResourceConfig struct {
 	// RequiresRestart Whether a restart is required after changing the value
 	RequiresRestart *bool `json:"requires_restart,omitempty"`
 
+	// Skipped Check does not apply to the server's PostgreSQL version. Reason is given in details
+	Skipped *bool `json:"skipped,omitempty"`
+
+	// Source pg_settings.source, where the current value comes from, e.g. default, configuration file,
+	// command line, database or user
+	Source *string `json:"source,omitempty"`
+
+	// Sourcefile Configuration file the current value was set in, empty if it wasn't set in a file
+	Sourcefile *string `json:"sourcefile,omitempty"`
+
+	// Sourceline Line in sourcefile the current value was set on, 0 if unknown
+	Sourceline *int `json:"sourceline,omitempty"`
+
 	// SuggestedValue Value that will be suggested after running check
 	SuggestedValue *string `json:"suggested_value,omitempty"`
 
+	// TableSuggestions Per table ALTER TABLE statements made alongside the suggestion (autovacuum checks only)
+	TableSuggestions *[]TableSuggestion `json:"table_suggestions,omitempty"`
+
 	// Unit Unit of measurement (s, ms, kB, 8kB, etc.)
 	Unit *string `json:"unit,omitempty"`
 
@@ -58,18 +283,188 @@ type ResourceConfig struct {
 
 // ResourceConfigPatchSchema defines model for resourceConfigPatchSchema.
 type ResourceConfigPatchSchema struct {
+	// Database Apply with ALTER DATABASE ... SET for this database only instead of ALTER SYSTEM.
+	// Together with role, applies with ALTER ROLE ... IN DATABASE ... SET
+	Database *string `json:"database,omitempty"`
+
 	// Name Name of the setting
 	Name string `json:"name"`
 
+	// Role Apply with ALTER ROLE ... SET for this role only instead of ALTER SYSTEM
+	Role *string `json:"role,omitempty"`
+
 	// SuggestedValue Value that will be suggested after running check
 	SuggestedValue string `json:"suggested_value"`
 }
 
+// RoleSetting defines model for roleSetting.
+type RoleSetting = utils.RoleSetting
+
+// ScheduledChange Change waiting to run, described in the schedule API
+type ScheduledChange = schedule.ScheduledChange
+
+// SuggestionValidation defines model for suggestionValidation.
+type SuggestionValidation struct {
+	Items []SuggestionVerdict `json:"items"`
+
+	// Restarted Whether PostgreSQL was restarted after applying. Configuration is only reloaded
+	// unless a setting with postmaster context changed. Always false for dry runs
+	Restarted bool `json:"restarted"`
+
+	// RolledBack Whether the previous postgresql.auto.conf and per-database and per-role settings were put back,
+	// either because a suggestion could not be applied or because PostgreSQL didn't come back after applying
+	RolledBack bool `json:"rolled_back"`
+
+	// Steps Reload, restart, health check and rollback steps taken after applying. Empty for dry runs
+	Steps []ConfigChangeStep `json:"steps"`
+
+	// Valid Whether every suggestion is valid
+	Valid bool `json:"valid"`
+}
+
+// SuggestionVerdict defines model for suggestionVerdict.
+type SuggestionVerdict struct {
+	// Context pg_settings.context, e.g. postmaster for settings that need a restart
+	Context string `json:"context"`
+
+	// Database Database the suggestion is applied for, as it was sent
+	Database *string `json:"database,omitempty"`
+
+	// Name Name of the setting
+	Name string `json:"name"`
+
+	// NormalizedValue Value in the unit and format pg_settings reports it in, empty if invalid
+	NormalizedValue string `json:"normalized_value"`
+
+	// Reason Why the suggestion is invalid, empty if it is valid
+	Reason string `json:"reason"`
+
+	// RequiresRestart Whether changing the setting takes a restart rather than a reload
+	RequiresRestart bool `json:"requires_restart"`
+
+	// Role Role the suggestion is applied for, as it was sent
+	Role  *string `json:"role,omitempty"`
+	Valid bool    `json:"valid"`
+
+	// Value Value as it was sent
+	Value string `json:"value"`
+
+	// Vartype bool, integer, real, string or enum as reported by pg_settings
+	Vartype string `json:"vartype"`
+}
+
+// TableSuggestion defines model for tableSuggestion.
+type TableSuggestion struct {
+	// Database Database the table is in. Statement has to be run while connected to it
+	Database string `json:"database"`
+
+	// Details Details informing why the statement was suggested
+	Details string `json:"details"`
+
+	// Statement ALTER TABLE statement to run
+	Statement string `json:"statement"`
+
+	// Table Schema qualified table name
+	Table string `json:"table"`
+}
+
+// WorkloadProfileDescription defines model for workloadProfileDescription.
+type WorkloadProfileDescription struct {
+	// Description What kind of workload the profile is meant for
+	Description string              `json:"description"`
+	Name        WorkloadProfileName `json:"name"`
+}
+
+// WorkloadProfileList defines model for workloadProfileList.
+type WorkloadProfileList struct {
+	Available []WorkloadProfileDescription `json:"available"`
+	Selected  WorkloadProfileName          `json:"selected"`
+}
+
+// WorkloadProfileName defines model for workloadProfileName.
+type WorkloadProfileName string
+
+// WorkloadProfileSelection defines model for workloadProfileSelection.
+type WorkloadProfileSelection struct {
+	Profile WorkloadProfileName `json:"profile"`
+}
+
+// ApplyAt defines model for applyAt.
+type ApplyAt = time.Time
+
+// Profile defines model for profile.
+type Profile = WorkloadProfileName
+
+// Window defines model for window.
+type Window = string
+
+// ExportResourceConfigsJSONBody defines parameters for ExportResourceConfigs.
+type ExportResourceConfigsJSONBody = []ResourceConfigPatchSchema
+
+// ExportResourceConfigsParams defines parameters for ExportResourceConfigs.
+type ExportResourceConfigsParams struct {
+	// Format sql for ALTER SYSTEM statements, conf for a postgresql.conf include file, ansible
+	// for a vars file, patroni for a dynamic configuration block and cloudnativepg for
+	// a Cluster spec snippet
+	Format ExportResourceConfigsParamsFormat `form:"format" json:"format"`
+}
+
+// ExportResourceConfigsParamsFormat defines parameters for ExportResourceConfigs.
+type ExportResourceConfigsParamsFormat string
+
+// GetFileSettingsParams defines parameters for GetFileSettings.
+type GetFileSettingsParams struct {
+	// All Also list entries that are in effect. Only problems are listed by default
+	All *bool `form:"all,omitempty" json:"all,omitempty"`
+}
+
+// GetReportParams defines parameters for GetReport.
+type GetReportParams struct {
+	// Format Report format. Defaults to json
+	Format *GetReportParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+
+	// Profile Workload profile to run checks with. Selection is persisted on the server,
+	// so later calls without this parameter keep using it
+	Profile *Profile `form:"profile,omitempty" json:"profile,omitempty"`
+}
+
+// GetReportParamsFormat defines parameters for GetReport.
+type GetReportParamsFormat string
+
+// GetResourceConfigsParams defines parameters for GetResourceConfigs.
+type GetResourceConfigsParams struct {
+	// Profile Workload profile to run checks with. Selection is persisted on the server,
+	// so later calls without this parameter keep using it
+	Profile *Profile `form:"profile,omitempty" json:"profile,omitempty"`
+}
+
 // PatchResourceConfigsJSONBody defines parameters for PatchResourceConfigs.
 type PatchResourceConfigsJSONBody = []ResourceConfigPatchSchema
 
-// GetResourceConfigByIdParamsConfig defines parameters for GetResourceConfigById.
-type GetResourceConfigByIdParamsConfig string
+// PatchResourceConfigsParams defines parameters for PatchResourceConfigs.
+type PatchResourceConfigsParams struct {
+	// DryRun Only validate suggestions and return a verdict for each of them
+	DryRun *bool `form:"dry_run,omitempty" json:"dry_run,omitempty"`
+
+	// ApplyAt Run the change at this time instead of right away, see /api/scheduled-changes
+	ApplyAt *ApplyAt `form:"apply_at,omitempty" json:"apply_at,omitempty"`
+
+	// Window Run the change in the next occurrence of this maintenance window instead of right away
+	Window *Window `form:"window,omitempty" json:"window,omitempty"`
+}
+
+// GetResourceConfigByIdParams defines parameters for GetResourceConfigById.
+type GetResourceConfigByIdParams struct {
+	// Profile Workload profile to run checks with. Selection is persisted on the server,
+	// so later calls without this parameter keep using it
+	Profile *Profile `form:"profile,omitempty" json:"profile,omitempty"`
+}
+
+// ExportResourceConfigsJSONRequestBody defines body for ExportResourceConfigs for application/json ContentType.
+type ExportResourceConfigsJSONRequestBody = ExportResourceConfigsJSONBody
+
+// PutWorkloadProfileJSONRequestBody defines body for PutWorkloadProfile for application/json ContentType.
+type PutWorkloadProfileJSONRequestBody = WorkloadProfileSelection
 
 // PatchResourceConfigsJSONRequestBody defines body for PatchResourceConfigs for application/json ContentType.
 type PatchResourceConfigsJSONRequestBody = PatchResourceConfigsJSONBody
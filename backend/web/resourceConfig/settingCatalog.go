@@ -0,0 +1,129 @@
+// Catalog of settings checks read, used in place of `pg_settings` when
+// configuration files are analysed without a running server. Values are
+// what the server runs with when a setting isn't set in any file, in the
+// same unit and format `pg_settings.setting` reports them in.
+package resourceConfig
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+const (
+	vartypeBool    = "bool"
+	vartypeInteger = "integer"
+	vartypeReal    = "real"
+	vartypeEnum    = "enum"
+
+	// `pg_settings.context` of settings that only change when the server starts
+	contextPostmaster = "postmaster"
+)
+
+// How a setting is stored starting from server_version_num `since`
+type settingDefinition struct {
+	since    int
+	vartype  string
+	unit     string
+	value    string // empty if the default is declared by the setting's check instead
+	enumVals string // formatted like `pg_settings.enumvals`, e.g. `{off,on,try}`
+	context  string // `pg_settings.context`, only declared for postmaster settings
+}
+
+// Every setting returned by `RequiredSettings()` needs an entry here for offline analysis.
+// Settings computed by the server at startup (`shared_memory_size`, etc...) are left out.
+var settingCatalog = map[string][]settingDefinition{
+	"autovacuum_max_workers":           {{vartype: vartypeInteger, value: "3", context: contextPostmaster}},
+	"autovacuum_vacuum_threshold":      {{vartype: vartypeInteger, value: "50"}},
+	"autovacuum_vacuum_scale_factor":   {{vartype: vartypeReal, value: "0.2"}},
+	"autovacuum_naptime":               {{vartype: vartypeInteger, unit: "s", value: "60"}},
+	"autovacuum_vacuum_cost_limit":     {{vartype: vartypeInteger, value: "-1"}},
+	"autovacuum_vacuum_cost_delay":     {{vartype: vartypeInteger, unit: "ms"}, {since: 120000, vartype: vartypeReal, unit: "ms"}},
+	"vacuum_cost_limit":                {{vartype: vartypeInteger, value: "200"}},
+	"max_connections":                  {{vartype: vartypeInteger, value: "100", context: contextPostmaster}},
+	"shared_buffers":                   {{vartype: vartypeInteger, unit: "8kB", value: "1024", context: contextPostmaster}},
+	"wal_buffers":                      {{vartype: vartypeInteger, unit: "8kB", value: "-1", context: contextPostmaster}},
+	"work_mem":                         {{vartype: vartypeInteger, unit: "kB", value: "4096"}},
+	"hash_mem_multiplier":              {{vartype: vartypeReal}},
+	"autovacuum_work_mem":              {{vartype: vartypeInteger, unit: "kB", value: "-1"}},
+	"maintenance_work_mem":             {{vartype: vartypeInteger, unit: "kB", value: "65536"}},
+	"huge_pages":                       {{vartype: vartypeEnum, value: "try", enumVals: "{off,on,try}", context: contextPostmaster}},
+	"huge_page_size":                   {{vartype: vartypeInteger, unit: "kB", value: "0", context: contextPostmaster}},
+	"temp_buffers":                     {{vartype: vartypeInteger, unit: "8kB", value: "1024"}},
+	"max_prepared_transactions":        {{vartype: vartypeInteger, value: "0", context: contextPostmaster}},
+	"logical_decoding_work_mem":        {{vartype: vartypeInteger, unit: "kB", value: "65536"}},
+	"max_stack_depth":                  {{vartype: vartypeInteger, unit: "kB", value: "2048"}},
+	"shared_memory_type":               {{vartype: vartypeEnum, value: "mmap", enumVals: "{sysv,mmap}", context: contextPostmaster}},
+	"dynamic_shared_memory_type":       {{vartype: vartypeEnum, value: "posix", enumVals: "{posix,sysv,mmap}", context: contextPostmaster}},
+	"max_worker_processes":             {{vartype: vartypeInteger, value: "8", context: contextPostmaster}},
+	"max_parallel_workers":             {{vartype: vartypeInteger, value: "8"}},
+	"max_parallel_workers_per_gather":  {{vartype: vartypeInteger, value: "0"}, {since: 100000, vartype: vartypeInteger, value: "2"}},
+	"max_parallel_maintenance_workers": {{vartype: vartypeInteger, value: "2"}},
+	"random_page_cost":                 {{vartype: vartypeReal, value: "4"}},
+	"seq_page_cost":                    {{vartype: vartypeReal, value: "1"}},
+	"effective_io_concurrency":         {{vartype: vartypeInteger, value: "1"}},
+	"effective_cache_size":             {{vartype: vartypeInteger, unit: "8kB", value: "524288"}},
+	"max_wal_size":                     {{vartype: vartypeInteger, unit: "MB", value: "1024"}},
+	"min_wal_size":                     {{vartype: vartypeInteger, unit: "MB", value: "80"}},
+	"checkpoint_timeout":               {{vartype: vartypeInteger, unit: "s", value: "300"}},
+	"checkpoint_completion_target":     {{vartype: vartypeReal}},
+	// Compression methods were added in PostgreSQL 15, before that this was a boolean
+	"wal_compression": {{vartype: vartypeBool, value: "off"}, {since: 150000, vartype: vartypeEnum, value: "off", enumVals: "{pglz,lz4,zstd,on,off}"}},
+}
+
+// Returns how @name is stored on server @version. Unknown version (0) gets the newest definition.
+func getSettingDefinition(name string, version int) (settingDefinition, bool) {
+	definitions, ok := settingCatalog[name]
+	if !ok {
+		return settingDefinition{}, false
+	}
+	definition := definitions[0]
+	for _, candidate := range definitions {
+		if version >= candidate.since || version == 0 {
+			definition = candidate
+		}
+	}
+	return definition, true
+}
+
+// Converts a value as written in a configuration file into the format `pg_settings` reports it in.
+// Units are converted to the setting's unit and booleans are spelled as on/off.
+func (definition settingDefinition) normalize(value string) (string, error) {
+	switch definition.vartype {
+	case vartypeInteger:
+		number, err := utils.ParseValueWithUnit(value, definition.unit)
+		if err != nil {
+			return "", err
+		}
+		// PostgreSQL rounds to the nearest whole unit, e.g. 100kB for an 8kB setting is 12
+		return strconv.FormatInt(int64(math.Round(number)), 10), nil
+	case vartypeReal:
+		number, err := utils.ParseValueWithUnit(value, definition.unit)
+		if err != nil {
+			return "", err
+		}
+		return utils.Float64ToString(number), nil
+	case vartypeBool:
+		return normalizeBool(value)
+	case vartypeEnum:
+		// Enum values are case insensitive
+		return strings.ToLower(strings.TrimSpace(value)), nil
+	}
+	return value, nil
+}
+
+// Accepts the same spellings PostgreSQL does: on/off, true/false, yes/no, 1/0 and unique prefixes of them
+func normalizeBool(value string) (string, error) {
+	lower := strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case lower == "":
+	case lower == "1" || lower == "on" || strings.HasPrefix("true", lower) || strings.HasPrefix("yes", lower):
+		return "on", nil
+	case lower == "0" || lower == "of" || lower == "off" || strings.HasPrefix("false", lower) || strings.HasPrefix("no", lower):
+		return "off", nil
+	}
+	return "", fmt.Errorf("invalid value %q: requires a Boolean value", value)
+}
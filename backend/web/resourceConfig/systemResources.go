@@ -0,0 +1,85 @@
+// Memory and CPU available to PostgreSQL. Limits are read for the PostgreSQL
+// process where possible, since it may run in a different container or
+// systemd slice than this application. In offline mode everything comes
+// from the hardware description instead.
+package resourceConfig
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	sysctl "github.com/lorenzosaino/go-sysctl"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+// Returns pid of the backend serving our connection. Backends run in the same cgroup
+// as the postmaster. Returns 0 (this process) if that pid is not visible to us,
+// such as when PostgreSQL runs on another host or in another pid namespace.
+func (conf *Configuration) getPostgresPid(logger *utils.Logger) int {
+	var pid int
+	if err := conf.dbHandler.QueryRow("SELECT pg_backend_pid()").Scan(&pid); err != nil {
+		logger.LogWarning(fmt.Errorf("could not get PostgreSQL backend pid, reading limits of this process instead: %v", err))
+		return 0
+	}
+
+	// Make sure the pid belongs to PostgreSQL and not to an unrelated process with the same pid
+	comm, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil || !strings.HasPrefix(strings.TrimSpace(string(comm)), "postgres") {
+		return 0
+	}
+	return pid
+}
+
+// Returns total and available memory PostgreSQL can use, taking cgroup limits into account
+func (conf *Configuration) getMemoryInfo(logger *utils.Logger) (*utils.MemoryInfo, error) {
+	if conf.offline != nil {
+		return conf.offline.memoryInfo()
+	}
+	return utils.GetEffectiveMemory(conf.getPostgresPid(logger))
+}
+
+// Returns CPU topology and the restrictions that apply to PostgreSQL
+func (conf *Configuration) getCpuInfo(logger *utils.Logger) (*utils.CpuInfo, error) {
+	if conf.offline != nil {
+		return conf.offline.cpuInfo(), nil
+	}
+	return utils.GetCpuInfoForProcess(conf.getPostgresPid(logger))
+}
+
+// Returns stack size limit PostgreSQL runs with in bytes
+func (conf *Configuration) getStackSize() (uint64, error) {
+	if conf.offline != nil {
+		return conf.offline.stackSize, nil
+	}
+	return utils.GetStackSize()
+}
+
+// Returns number of huge pages reserved in the kernel (vm.nr_hugepages)
+func (conf *Configuration) getKernelHugePages() (int, error) {
+	if conf.offline != nil {
+		return conf.offline.nrHugePages, nil
+	}
+	kernelPagesString, err := sysctl.Get("vm.nr_hugepages")
+	if err != nil {
+		return 0, err
+	}
+	return utils.StringToInt(kernelPagesString)
+}
+
+// Returns default huge page size of the kernel in bytes
+func (conf *Configuration) getHugePageSize() (uint64, error) {
+	if conf.offline != nil {
+		return conf.offline.hugePageSize, nil
+	}
+	return utils.GetHugePageSize()
+}
+
+// Returns transparent huge pages mode (always, madvise or never). Empty offline if not described.
+func (conf *Configuration) getTransparentHugePages() (string, error) {
+	if conf.offline != nil {
+		return conf.offline.transparentHugePages, nil
+	}
+	return utils.GetTransparentHugePages()
+}
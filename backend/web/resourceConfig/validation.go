@@ -0,0 +1,195 @@
+// Validation of suggestions against `pg_settings` before anything is written.
+// Values are parsed the way the server would parse them (units, boolean
+// spellings, enum members) and checked against min_val and max_val, so that
+// an apply either goes through for every item or doesn't start at all.
+package resourceConfig
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+	"github.com/lib/pq"
+)
+
+var ErrInvalidSuggestions = errors.New("one or more suggestions are invalid")
+
+// `pg_settings.context` of settings ALTER DATABASE and ALTER ROLE can set. Others can
+// only change for the whole server or, like backend settings, not once a session started.
+var roleSettingContexts = map[string]bool{"user": true, "superuser": true}
+
+// What `pg_settings` allows for a setting
+type settingConstraints struct {
+	definition settingDefinition // vartype, unit and enumvals
+	minVal     sql.NullString
+	maxVal     sql.NullString
+	context    string
+}
+
+// Reads constraints for @names from `pg_settings`. Names the server doesn't know are left out.
+func (conf *Configuration) getSettingConstraints(names []string, logger *utils.Logger) (map[string]settingConstraints, error) {
+	if conf.offline != nil || conf.dbHandler == nil {
+		return nil, fmt.Errorf("validating suggestions needs a database connection")
+	}
+
+	rows, err := conf.dbHandler.Query("SELECT name, vartype, unit, enumvals, min_val, max_val, context FROM pg_settings WHERE name = ANY($1)", pq.Array(names))
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed querying Postgres: %v", err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	constraints := make(map[string]settingConstraints, len(names))
+	for rows.Next() {
+		var name, vartype, unit, enumVals sql.NullString
+		var constraint settingConstraints
+		if err := rows.Scan(&name, &vartype, &unit, &enumVals, &constraint.minVal, &constraint.maxVal, &constraint.context); err != nil {
+			logger.LogError(fmt.Errorf("Failed scanning row: %v", err))
+			return nil, err
+		}
+		constraint.definition = settingDefinition{vartype: vartype.String, unit: unit.String, enumVals: enumVals.String}
+		constraints[name.String] = constraint
+	}
+	if err := rows.Err(); err != nil {
+		logger.LogError(fmt.Errorf("Failed reading pg_settings: %v", err))
+		return nil, err
+	}
+	return constraints, nil
+}
+
+// Returns which of @names exist, read with @query that takes an array of names
+func (conf *Configuration) getExistingNames(query string, names []string, logger *utils.Logger) (map[string]bool, error) {
+	existing := make(map[string]bool, len(names))
+	if len(names) == 0 {
+		return existing, nil
+	}
+	rows, err := conf.dbHandler.Query(query, pq.Array(names))
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed querying Postgres: %v", err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			logger.LogError(fmt.Errorf("Failed scanning row: %v", err))
+			return nil, err
+		}
+		existing[name] = true
+	}
+	return existing, rows.Err()
+}
+
+// Validates every suggestion and returns a verdict for each of them. Only
+// returns an error if `pg_settings` couldn't be read, not for invalid values.
+func (conf *Configuration) ValidateSuggestions(suggestions *PatchResourceConfigsJSONBody, logger *utils.Logger) (*SuggestionValidation, error) {
+	names := make([]string, 0, len(*suggestions))
+	databases, roles := []string{}, []string{}
+	for _, suggestion := range *suggestions {
+		names = append(names, suggestion.Name)
+		database, role := suggestion.scope()
+		if database != "" {
+			databases = append(databases, database)
+		}
+		if role != "" {
+			roles = append(roles, role)
+		}
+	}
+	constraints, err := conf.getSettingConstraints(names, logger)
+	if err != nil {
+		return nil, err
+	}
+	existingDatabases, err := conf.getExistingNames("SELECT datname FROM pg_database WHERE datname = ANY($1)", databases, logger)
+	if err != nil {
+		return nil, err
+	}
+	existingRoles, err := conf.getExistingNames("SELECT rolname FROM pg_roles WHERE rolname = ANY($1)", roles, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	validation := &SuggestionValidation{Valid: true, Items: []SuggestionVerdict{}, Steps: []ConfigChangeStep{}}
+	seen := make(map[string]bool, len(names))
+	for _, suggestion := range *suggestions {
+		verdict := SuggestionVerdict{Name: suggestion.Name, Value: suggestion.SuggestedValue, Database: suggestion.Database, Role: suggestion.Role}
+		database, role := suggestion.scope()
+		scoped := database != "" || role != ""
+		// The same setting can be suggested once for the cluster and once for each database and role
+		key := database + "\x00" + role + "\x00" + suggestion.Name
+		constraint, known := constraints[suggestion.Name]
+		if known {
+			verdict.Vartype = constraint.definition.vartype
+			verdict.Context = constraint.context
+			verdict.RequiresRestart = constraint.context == contextPostmaster
+		}
+
+		switch {
+		case !known:
+			verdict.Reason = "unknown setting"
+		case seen[key]:
+			verdict.Reason = "setting is listed more than once"
+		case constraint.context == "internal":
+			verdict.Reason = "setting can't be changed, it is fixed when PostgreSQL is built or the cluster is initialised"
+		case scoped && !roleSettingContexts[constraint.context]:
+			verdict.Reason = fmt.Sprintf("setting can't be set per database or role, only for the whole server (context %s)", constraint.context)
+		case database != "" && !existingDatabases[database]:
+			verdict.Reason = fmt.Sprintf("database %s does not exist", database)
+		case role != "" && !existingRoles[role]:
+			verdict.Reason = fmt.Sprintf("role %s does not exist", role)
+		default:
+			verdict.NormalizedValue, err = constraint.validate(suggestion.SuggestedValue)
+			if err != nil {
+				verdict.Reason = err.Error()
+			}
+		}
+		seen[key] = true
+
+		verdict.Valid = verdict.Reason == ""
+		if !verdict.Valid {
+			verdict.NormalizedValue = ""
+			validation.Valid = false
+		}
+		validation.Items = append(validation.Items, verdict)
+	}
+	return validation, nil
+}
+
+// Parses @value the way the server would and checks it against allowed values.
+// Returns the value in the format `pg_settings` reports it in.
+func (constraint settingConstraints) validate(value string) (string, error) {
+	if strings.ContainsAny(value, "\r\n\x00") {
+		return "", fmt.Errorf("invalid value %q: has to be on a single line", value)
+	}
+
+	normalized, err := constraint.definition.normalize(value)
+	if err != nil {
+		return "", err
+	}
+
+	switch constraint.definition.vartype {
+	case vartypeEnum:
+		allowed := strings.Split(strings.Trim(constraint.definition.enumVals, "{}"), ",")
+		for _, member := range allowed {
+			if strings.Trim(member, `"`) == normalized {
+				return normalized, nil
+			}
+		}
+		return "", fmt.Errorf("invalid value %q: available values are %s", value, strings.Join(allowed, ", "))
+	case vartypeInteger, vartypeReal:
+		number, _ := strconv.ParseFloat(normalized, 64)
+		minVal, minErr := strconv.ParseFloat(constraint.minVal.String, 64)
+		maxVal, maxErr := strconv.ParseFloat(constraint.maxVal.String, 64)
+		if (minErr == nil && number < minVal) || (maxErr == nil && number > maxVal) {
+			unit := ""
+			if constraint.definition.unit != "" {
+				unit = " " + constraint.definition.unit
+			}
+			return "", fmt.Errorf("%s%s is outside the valid range for this setting (%s .. %s)", normalized, unit, constraint.minVal.String, constraint.maxVal.String)
+		}
+	}
+	return normalized, nil
+}
@@ -0,0 +1,308 @@
+// WAL and checkpoint related checks. Suggestions are based on how often
+// checkpoints are forced by WAL volume (requested) as opposed to being
+// triggered by `checkpoint_timeout` (timed).
+package resourceConfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+func init() {
+	RegisterCheck(&checkDefinition{name: "max_wal_size", category: CategoryWal, minVersion: 90500, needsServer: true, run: (*Configuration).CheckMaxWalSize})
+	RegisterCheck(&checkDefinition{name: "min_wal_size", category: CategoryWal, minVersion: 90500, requiredSettings: []string{"max_wal_size"}, dependsOn: []string{"max_wal_size"}, run: (*Configuration).CheckMinWalSize})
+	RegisterCheck(&checkDefinition{name: "checkpoint_timeout", category: CategoryWal, run: (*Configuration).CheckCheckpointTimeout})
+	RegisterCheck(&checkDefinition{name: "checkpoint_completion_target", category: CategoryWal, defaults: []versionDefault{{0, "0.5"}, {140000, "0.9"}}, run: (*Configuration).CheckCheckpointCompletionTarget})
+	RegisterCheck(&checkDefinition{name: "wal_buffers", category: CategoryWal, requiredSettings: []string{"shared_buffers"}, run: (*Configuration).CheckWalBuffers})
+	RegisterCheck(&checkDefinition{name: "wal_compression", category: CategoryWal, run: (*Configuration).CheckWalCompression})
+}
+
+// If more than this share of checkpoints are requested, WAL is filling up
+// before `checkpoint_timeout` is reached and `max_wal_size` is too small
+const requestedCheckpointThreshold = 0.1
+
+// Below this many checkpoints statistics are too young to base suggestions on
+const minCheckpointSample = 10
+
+type checkpointStats struct {
+	timed     float64 // checkpoints triggered by checkpoint_timeout
+	requested float64 // checkpoints triggered by WAL volume or explicitly
+	source    string  // statistics view the values were read from
+}
+
+// Share of checkpoints that were requested rather than timed
+func (stats *checkpointStats) requestedRatio() float64 {
+	total := stats.timed + stats.requested
+	if total == 0 {
+		return 0
+	}
+	return stats.requested / total
+}
+
+func (stats *checkpointStats) total() float64 {
+	return stats.timed + stats.requested
+}
+
+// Reads checkpoint counters. PostgreSQL 17 moved them from `pg_stat_bgwriter` to `pg_stat_checkpointer`.
+// There are no statistics to read when analysing configuration files, so none are returned.
+func (conf *Configuration) getCheckpointStats(logger *utils.Logger) (*checkpointStats, error) {
+	if conf.offline != nil {
+		return &checkpointStats{source: "configuration files"}, nil
+	}
+	hasCheckpointer := conf.serverVersion >= 170000
+	// Fall back to looking for the view if the version couldn't be detected
+	if conf.serverVersion == 0 {
+		row := conf.dbHandler.QueryRow("SELECT to_regclass('pg_catalog.pg_stat_checkpointer') IS NOT NULL")
+		if err := row.Scan(&hasCheckpointer); err != nil {
+			logger.LogError(fmt.Errorf("Failed checking for pg_stat_checkpointer: %v", err))
+			return nil, err
+		}
+	}
+
+	stats := &checkpointStats{source: "pg_stat_bgwriter"}
+	query := "SELECT checkpoints_timed, checkpoints_req FROM pg_stat_bgwriter"
+	if hasCheckpointer {
+		stats.source = "pg_stat_checkpointer"
+		query = "SELECT num_timed, num_requested FROM pg_stat_checkpointer"
+	}
+
+	if err := conf.dbHandler.QueryRow(query).Scan(&stats.timed, &stats.requested); err != nil {
+		logger.LogError(fmt.Errorf("Failed reading checkpoint statistics from %s: %v", stats.source, err))
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (conf *Configuration) CheckMaxWalSize(logger *utils.Logger) (*ResourceSetting, error) {
+	maxWalSize := conf.settings["max_wal_size"]
+	maxWalSize.Details = "This setting sets the maximum size the WAL is allowed to grow to between automatic checkpoints. "
+
+	stats, err := conf.getCheckpointStats(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed max_wal_size check: %v", err))
+		maxWalSize.GotError = true
+		return nil, err
+	}
+
+	currentAsMB, err := utils.ConvertBasedOnUnit(maxWalSize.Value, maxWalSize.Unit, "MB")
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed max_wal_size check: %v", err))
+		maxWalSize.GotError = true
+		return nil, err
+	}
+
+	if stats.total() < minCheckpointSample {
+		maxWalSize.Details += fmt.Sprintf("Only %.0f checkpoints have been recorded in %s since statistics were last reset. This is not enough to judge whether WAL fills up before checkpoint_timeout, so no suggestion is made.", stats.total(), stats.source)
+	} else if stats.requestedRatio() > requestedCheckpointThreshold {
+		// Double the current value so that WAL volume stops forcing checkpoints
+		suggestion := utils.RoundToPowerOf2(uint64(currentAsMB * 2))
+		suggestionAsUnit, err := utils.ConvertBasedOnUnit(utils.Uint64ToString(suggestion), "MB", maxWalSize.Unit)
+		if err != nil {
+			logger.LogError(fmt.Errorf("Failed max_wal_size check: %v", err))
+			maxWalSize.GotError = true
+			return nil, err
+		}
+		maxWalSize.SuggestedValue = utils.Float32ToString(suggestionAsUnit)
+		maxWalSize.Details += fmt.Sprintf("%.0f of %.0f checkpoints (%.0f%%) recorded in %s were requested rather than timed, meaning WAL reaches max_wal_size before checkpoint_timeout elapses. Frequent checkpoints cause extra I/O and full page writes. Suggestion is to double max_wal_size so that most checkpoints are triggered by checkpoint_timeout instead.", stats.requested, stats.total(), stats.requestedRatio()*100, stats.source)
+	} else if currentAsMB < 1024 {
+		suggestionAsUnit, err := utils.ConvertBasedOnUnit("1024", "MB", maxWalSize.Unit)
+		if err != nil {
+			logger.LogError(fmt.Errorf("Failed max_wal_size check: %v", err))
+			maxWalSize.GotError = true
+			return nil, err
+		}
+		maxWalSize.SuggestedValue = utils.Float32ToString(suggestionAsUnit)
+		maxWalSize.Details += "Current value is lower than the default of 1GB. Suggestion is to set this to the default."
+	} else {
+		maxWalSize.Details += fmt.Sprintf("Most checkpoints recorded in %s were triggered by checkpoint_timeout (%.0f of %.0f), so WAL does not fill up too early. No change is needed.", stats.source, stats.timed, stats.total())
+	}
+
+	resetSuggestionIfEqual(&maxWalSize)
+	conf.settings["max_wal_size"] = maxWalSize
+	return &maxWalSize, nil
+}
+
+// Keeps min_wal_size between a quarter and a half of max_wal_size
+func (conf *Configuration) CheckMinWalSize(logger *utils.Logger) (*ResourceSetting, error) {
+	minWalSize := conf.settings["min_wal_size"]
+	minWalSize.Details = "This setting sets the amount of WAL files that are recycled for future use instead of being removed, as long as WAL disk usage stays below it. "
+
+	// Base suggestion on what max_wal_size will be after applying its suggestion
+	maxWalSize := conf.settings["max_wal_size"]
+	maxWalSizeValue := maxWalSize.Value
+	if maxWalSize.SuggestedValue != "" {
+		maxWalSizeValue = maxWalSize.SuggestedValue
+	}
+	maxWalSizeAsUnit, err := utils.ConvertBasedOnUnit(maxWalSizeValue, maxWalSize.Unit, minWalSize.Unit)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed min_wal_size check: %v", err))
+		minWalSize.GotError = true
+		return nil, err
+	}
+	currentValue, err := utils.StringToFloat32(minWalSize.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed min_wal_size check: %v", err))
+		minWalSize.GotError = true
+		return nil, err
+	}
+
+	suggestion := utils.RoundToPowerOf2(uint64(maxWalSizeAsUnit / 4))
+	if currentValue < float32(suggestion) {
+		minWalSize.SuggestedValue = utils.Uint64ToString(suggestion)
+		minWalSize.Details += fmt.Sprintf("Current value is less than a quarter of max_wal_size (%s%s). Keeping more WAL segments around avoids creating new ones during write bursts. Suggestion is to set this to a quarter of max_wal_size.", maxWalSizeValue, maxWalSize.Unit)
+	} else if currentValue > maxWalSizeAsUnit/2 {
+		minWalSize.SuggestedValue = utils.Uint64ToString(suggestion)
+		minWalSize.Details += fmt.Sprintf("Current value is more than half of max_wal_size (%s%s), so disk space is being reserved for WAL that is rarely needed. Suggestion is to set this to a quarter of max_wal_size.", maxWalSizeValue, maxWalSize.Unit)
+	}
+
+	resetSuggestionIfEqual(&minWalSize)
+	conf.settings["min_wal_size"] = minWalSize
+	return &minWalSize, nil
+}
+
+// GENERALREC
+func (conf *Configuration) CheckCheckpointTimeout(logger *utils.Logger) (*ResourceSetting, error) {
+	checkpointTimeout := conf.settings["checkpoint_timeout"]
+	checkpointTimeout.Details = "This setting sets the maximum time between automatic WAL checkpoints. "
+
+	currentValue, err := utils.StringToUint64(checkpointTimeout.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed checkpoint_timeout check: %v", err))
+		checkpointTimeout.GotError = true
+		return nil, err
+	}
+
+	// checkpoint_timeout is always reported in seconds
+	if currentValue < 900 {
+		checkpointTimeout.SuggestedValue = "900"
+		checkpointTimeout.Details += "Current value is less than 15 minutes. Checkpoints this frequent write the same pages over and over and cause more full page writes to WAL. Longer intervals increase crash recovery time, but 15 minutes is a commonly used balance. Suggestion is to set this to 15 minutes."
+	} else if currentValue > 3600 {
+		checkpointTimeout.SuggestedValue = "3600"
+		checkpointTimeout.Details += "Current value is more than an hour. Crash recovery may have to replay a large amount of WAL. Suggestion is to set this to an hour at most."
+	}
+
+	resetSuggestionIfEqual(&checkpointTimeout)
+	conf.settings["checkpoint_timeout"] = checkpointTimeout
+	return &checkpointTimeout, nil
+}
+
+// GENERALREC
+func (conf *Configuration) CheckCheckpointCompletionTarget(logger *utils.Logger) (*ResourceSetting, error) {
+	completionTarget := conf.settings["checkpoint_completion_target"]
+	completionTarget.Details = "This setting specifies the target of checkpoint completion, as a fraction of total time between checkpoints. "
+
+	currentValue, err := utils.StringToFloat32(completionTarget.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed checkpoint_completion_target check: %v", err))
+		completionTarget.GotError = true
+		return nil, err
+	}
+
+	if currentValue < 0.9 {
+		completionTarget.SuggestedValue = "0.9"
+		completionTarget.Details += "Current value is below 0.9. Lower values make checkpoints write out dirty buffers in shorter bursts, causing I/O spikes. Suggestion is to set this to 0.9 (the default since PostgreSQL 14) so that checkpoint I/O is spread across most of the checkpoint interval."
+		if defaultValue := conf.defaultValue("checkpoint_completion_target"); defaultValue != "0.9" {
+			completionTarget.Details += fmt.Sprintf(" On this PostgreSQL version the default is still %s, so it has to be set explicitly.", defaultValue)
+		}
+	}
+
+	resetSuggestionIfEqual(&completionTarget)
+	conf.settings["checkpoint_completion_target"] = completionTarget
+	return &completionTarget, nil
+}
+
+// Suggests the value PostgreSQL itself would choose for `wal_buffers = -1`:
+// 1/32 of shared_buffers, no less than 64kB and no more than one WAL segment (16MB)
+func (conf *Configuration) CheckWalBuffers(logger *utils.Logger) (*ResourceSetting, error) {
+	walBuffers := conf.settings["wal_buffers"]
+	walBuffers.Details = "This setting sets the amount of shared memory used for WAL data that has not yet been written to disk. "
+
+	sharedBuffers, err := conf.getSpecificPGSetting("shared_buffers", logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed wal_buffers check: %v", err))
+		walBuffers.GotError = true
+		return nil, err
+	}
+	sharedBuffersAsUnit, err := utils.ConvertBasedOnUnit(sharedBuffers.Value, sharedBuffers.Unit, walBuffers.Unit)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed wal_buffers check: %v", err))
+		walBuffers.GotError = true
+		return nil, err
+	}
+	lowerLimit, err := utils.ConvertBasedOnUnit("64", "kB", walBuffers.Unit)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed wal_buffers check: %v", err))
+		walBuffers.GotError = true
+		return nil, err
+	}
+	upperLimit, err := utils.ConvertBasedOnUnit("16", "MB", walBuffers.Unit)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed wal_buffers check: %v", err))
+		walBuffers.GotError = true
+		return nil, err
+	}
+	currentValue, err := utils.StringToFloat32(walBuffers.Value)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed wal_buffers check: %v", err))
+		walBuffers.GotError = true
+		return nil, err
+	}
+
+	suggestion := sharedBuffersAsUnit / 32
+	if suggestion < lowerLimit {
+		suggestion = lowerLimit
+	} else if suggestion > upperLimit {
+		suggestion = upperLimit
+	}
+
+	if currentValue < suggestion {
+		walBuffers.SuggestedValue = utils.Float32ToString(suggestion)
+		walBuffers.Details += fmt.Sprintf("Current value is lower than 1/32 of shared_buffers (%s%s). On busy servers WAL buffers that are too small have to be flushed on every commit. Suggestion is to use 1/32 of shared_buffers, capped at the size of one WAL segment (16MB).", sharedBuffers.Value, sharedBuffers.Unit)
+	} else if currentValue > upperLimit {
+		walBuffers.SuggestedValue = utils.Float32ToString(suggestion)
+		walBuffers.Details += "Current value is larger than one WAL segment (16MB). Values above this rarely help. Suggestion is to use 1/32 of shared_buffers, capped at 16MB."
+	}
+
+	resetSuggestionIfEqual(&walBuffers)
+	conf.settings["wal_buffers"] = walBuffers
+	return &walBuffers, nil
+}
+
+func (conf *Configuration) CheckWalCompression(logger *utils.Logger) (*ResourceSetting, error) {
+	walCompression := conf.settings["wal_compression"]
+	walCompression.Details = "This setting enables compression of full page images written to WAL. "
+
+	stats, err := conf.getCheckpointStats(logger)
+	if err != nil {
+		logger.LogError(fmt.Errorf("Failed wal_compression check: %v", err))
+		walCompression.GotError = true
+		return nil, err
+	}
+
+	if walCompression.Value != "off" {
+		resetSuggestionIfEqual(&walCompression)
+		conf.settings["wal_compression"] = walCompression
+		return &walCompression, nil
+	}
+
+	if stats.total() >= minCheckpointSample && stats.requestedRatio() > requestedCheckpointThreshold {
+		walCompression.Details += fmt.Sprintf("%.0f%% of checkpoints recorded in %s were requested, meaning this server writes a lot of WAL. Compressing full page images trades some CPU for less WAL I/O and smaller archives. ", stats.requestedRatio()*100, stats.source)
+	} else {
+		walCompression.Details += "Compressing full page images trades some CPU for less WAL I/O and smaller archives. "
+	}
+
+	// Before PostgreSQL 15 this was a boolean, afterwards an enum of compression methods
+	if strings.Contains(walCompression.EnumVals, "lz4") {
+		walCompression.Details += "Suggestion is to use lz4, which compresses well at a low CPU cost."
+		setEnumTypeSuggestedValue(&walCompression, "lz4")
+	} else {
+		walCompression.Details += "Suggestion is to turn it on."
+		walCompression.SuggestedValue = "on"
+	}
+
+	resetSuggestionIfEqual(&walCompression)
+	conf.settings["wal_compression"] = walCompression
+	return &walCompression, nil
+}
@@ -0,0 +1,463 @@
+// Changes that run later instead of right away, e.g. restarts that have to wait
+// for a maintenance window. Changes and windows are kept in a JSON file so they
+// survive restarts of PostgreScrutiniser, and are run one at a time from a goroutine.
+// APIs that schedule changes register an `Executor` for their kind of change.
+package schedule
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+const DefaultStoreFile = "/usr/local/postgrescrutiniser/confs/schedule.json"
+
+// How often due changes are looked for. Newly scheduled changes are picked up right away.
+const checkInterval = 15 * time.Second
+
+var (
+	ErrChangeNotFound  = errors.New("scheduled change not found")
+	ErrNotPending      = errors.New("change already started, finished or was cancelled")
+	ErrWindowNotFound  = errors.New("maintenance window not found")
+	ErrWindowInUse     = errors.New("pending changes are scheduled into the maintenance window")
+	ErrInvalidSchedule = errors.New("invalid schedule")
+)
+
+// Runs a change and reports what was done
+type Executor func(change *ScheduledChange) (*ChangeOutcome, error)
+
+// Contents of the store file
+type store struct {
+	Windows []MaintenanceWindow `json:"windows"`
+	Changes []ScheduledChange   `json:"changes"`
+}
+
+type Scheduler struct {
+	mutex     sync.Mutex
+	path      string // file changes and windows are persisted to
+	store     store
+	executors map[ChangeKind]Executor
+	wake      chan struct{} // signals that a change was scheduled
+	logger    *utils.Logger
+}
+
+// Creates a scheduler with changes and windows read from @path. Nothing runs until `Start()`.
+func NewScheduler(path string, logger *utils.Logger) *Scheduler {
+	scheduler := &Scheduler{
+		path:      path,
+		store:     store{Windows: []MaintenanceWindow{}, Changes: []ScheduledChange{}},
+		executors: make(map[ChangeKind]Executor),
+		wake:      make(chan struct{}, 1),
+		logger:    logger,
+	}
+	scheduler.load()
+	return scheduler
+}
+
+// Registers function that runs changes of @kind
+func (scheduler *Scheduler) RegisterExecutor(kind ChangeKind, executor Executor) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	scheduler.executors[kind] = executor
+}
+
+// Starts running due changes in the background
+func (scheduler *Scheduler) Start() {
+	go scheduler.run()
+}
+
+func (scheduler *Scheduler) load() {
+	content, err := os.ReadFile(scheduler.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			scheduler.logger.LogError(fmt.Errorf("could not read scheduled changes from %s: %v", scheduler.path, err))
+		}
+		return
+	}
+	loaded := store{}
+	if err := json.Unmarshal(content, &loaded); err != nil {
+		// Keep the file around rather than overwriting it on the next save
+		scheduler.logger.LogError(fmt.Errorf("could not parse scheduled changes in %s, moving it to %s.invalid: %v", scheduler.path, scheduler.path, err))
+		os.Rename(scheduler.path, scheduler.path+".invalid")
+		return
+	}
+	if loaded.Windows != nil {
+		scheduler.store.Windows = loaded.Windows
+	}
+	if loaded.Changes != nil {
+		scheduler.store.Changes = loaded.Changes
+	}
+
+	// A change that was running when we stopped may or may not have gone through
+	interrupted := false
+	for i := range scheduler.store.Changes {
+		change := &scheduler.store.Changes[i]
+		if change.Status == Running {
+			finish(change, nil, fmt.Errorf("PostgreScrutiniser stopped while the change was running, check the current configuration before scheduling it again"))
+			interrupted = true
+		}
+	}
+	if interrupted {
+		scheduler.save()
+	}
+}
+
+// Writes changes and windows to the store file. Caller has to hold the mutex.
+func (scheduler *Scheduler) save() error {
+	content, err := json.MarshalIndent(scheduler.store, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(scheduler.path), 0755); err != nil {
+		scheduler.logger.LogError(fmt.Errorf("could not create directory for %s: %v", scheduler.path, err))
+		return err
+	}
+
+	// Write to a temporary file first so a crash can't leave a half written store
+	temporary := scheduler.path + ".tmp"
+	if err := os.WriteFile(temporary, content, 0600); err != nil {
+		scheduler.logger.LogError(fmt.Errorf("could not save scheduled changes to %s: %v", temporary, err))
+		return err
+	}
+	if err := os.Rename(temporary, scheduler.path); err != nil {
+		scheduler.logger.LogError(fmt.Errorf("could not save scheduled changes to %s: %v", scheduler.path, err))
+		return err
+	}
+	return nil
+}
+
+////////////////////////
+// Changes
+
+/*
+Schedules @change to run at @applyAt or in the next occurrence of @window. Exactly one of them has to be given.
+@change - kind of change and what it changes, everything else is filled in
+*/
+func (scheduler *Scheduler) Schedule(change ScheduledChange, applyAt *time.Time, window *string) (*ScheduledChange, error) {
+	if (applyAt == nil) == (window == nil) {
+		return nil, fmt.Errorf("%w: either apply_at or window has to be given", ErrInvalidSchedule)
+	}
+
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	if _, ok := scheduler.executors[change.Kind]; !ok {
+		return nil, fmt.Errorf("%w: %s changes can't be scheduled", ErrInvalidSchedule, change.Kind)
+	}
+
+	// 1. Work out when the change runs
+	now := time.Now()
+	if applyAt != nil {
+		if applyAt.Before(now.Add(-time.Minute)) {
+			return nil, fmt.Errorf("%w: apply_at %s is in the past", ErrInvalidSchedule, applyAt.Format(time.RFC3339))
+		}
+		change.RunAt = *applyAt
+	} else {
+		maintenanceWindow := scheduler.findWindow(*window)
+		if maintenanceWindow == nil {
+			return nil, fmt.Errorf("%w: %s", ErrWindowNotFound, *window)
+		}
+		runAt, err := maintenanceWindow.next(now)
+		if err != nil {
+			return nil, err
+		}
+		change.RunAt = runAt
+		change.Window = window
+	}
+
+	// 2. Persist it
+	id, err := newChangeId()
+	if err != nil {
+		return nil, err
+	}
+	change.Id = id
+	change.Status = Pending
+	change.CreatedAt = now
+	change.StartedAt, change.FinishedAt, change.Outcome = nil, nil, nil
+	scheduler.store.Changes = append(scheduler.store.Changes, change)
+	if err := scheduler.save(); err != nil {
+		scheduler.store.Changes = scheduler.store.Changes[:len(scheduler.store.Changes)-1]
+		return nil, err
+	}
+
+	// 3. Wake up the scheduler in case the change is due right away
+	select {
+	case scheduler.wake <- struct{}{}:
+	default:
+	}
+	return &change, nil
+}
+
+// Lists changes, soonest first. Only changes with @status are listed if it's given.
+func (scheduler *Scheduler) Changes(status *ChangeStatus) []ScheduledChange {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	changes := []ScheduledChange{}
+	for _, change := range scheduler.store.Changes {
+		if status == nil || change.Status == *status {
+			changes = append(changes, change)
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].RunAt.Before(changes[j].RunAt) })
+	return changes
+}
+
+// Returns change @id along with its outcome, if it ran
+func (scheduler *Scheduler) Change(id string) (*ScheduledChange, error) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	change := scheduler.findChange(id)
+	if change == nil {
+		return nil, fmt.Errorf("%w: %s", ErrChangeNotFound, id)
+	}
+	found := *change
+	return &found, nil
+}
+
+// Cancels change @id if it hasn't started yet
+func (scheduler *Scheduler) Cancel(id string) (*ScheduledChange, error) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	change := scheduler.findChange(id)
+	if change == nil {
+		return nil, fmt.Errorf("%w: %s", ErrChangeNotFound, id)
+	}
+	if change.Status != Pending {
+		return nil, fmt.Errorf("%w: %s is %s", ErrNotPending, id, change.Status)
+	}
+	change.Status = Cancelled
+	if err := scheduler.save(); err != nil {
+		change.Status = Pending
+		return nil, err
+	}
+	cancelled := *change
+	return &cancelled, nil
+}
+
+// User @change is recorded under in the audit log when it runs
+func CreatedBy(change *ScheduledChange) string {
+	if change.CreatedBy == nil || *change.CreatedBy == "" {
+		return "scheduler"
+	}
+	return *change.CreatedBy
+}
+
+// Caller has to hold the mutex
+func (scheduler *Scheduler) findChange(id string) *ScheduledChange {
+	for i := range scheduler.store.Changes {
+		if scheduler.store.Changes[i].Id == id {
+			return &scheduler.store.Changes[i]
+		}
+	}
+	return nil
+}
+
+func newChangeId() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("could not generate id for scheduled change: %v", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+////////////////////////
+// Maintenance windows
+
+// Lists maintenance windows by name
+func (scheduler *Scheduler) Windows() []MaintenanceWindow {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	windows := append([]MaintenanceWindow{}, scheduler.store.Windows...)
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Name < windows[j].Name })
+	return windows
+}
+
+// Creates or replaces window @name. Pending changes scheduled into it are moved to its next occurrence.
+func (scheduler *Scheduler) PutWindow(name string, schedule MaintenanceWindowSchedule) (*MaintenanceWindow, error) {
+	window, err := newMaintenanceWindow(name, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	// Work on copies so nothing changes if the store can't be saved
+	windows := append([]MaintenanceWindow{}, scheduler.store.Windows...)
+	if scheduler.findWindow(name) != nil {
+		for i := range windows {
+			if windows[i].Name == name {
+				windows[i] = *window
+			}
+		}
+	} else {
+		windows = append(windows, *window)
+	}
+	changes := append([]ScheduledChange{}, scheduler.store.Changes...)
+	now := time.Now()
+	for i := range changes {
+		if changes[i].Status == Pending && changes[i].Window != nil && *changes[i].Window == name {
+			if changes[i].RunAt, err = window.next(now); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	previous := scheduler.store
+	scheduler.store = store{Windows: windows, Changes: changes}
+	if err := scheduler.save(); err != nil {
+		scheduler.store = previous
+		return nil, err
+	}
+	return window, nil
+}
+
+// Removes window @name unless pending changes are scheduled into it
+func (scheduler *Scheduler) DeleteWindow(name string) error {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	if scheduler.findWindow(name) == nil {
+		return fmt.Errorf("%w: %s", ErrWindowNotFound, name)
+	}
+	for _, change := range scheduler.store.Changes {
+		if change.Status == Pending && change.Window != nil && *change.Window == name {
+			return fmt.Errorf("%w: %s is scheduled into %s", ErrWindowInUse, change.Id, name)
+		}
+	}
+
+	windows := []MaintenanceWindow{}
+	for _, window := range scheduler.store.Windows {
+		if window.Name != name {
+			windows = append(windows, window)
+		}
+	}
+	previous := scheduler.store.Windows
+	scheduler.store.Windows = windows
+	if err := scheduler.save(); err != nil {
+		scheduler.store.Windows = previous
+		return err
+	}
+	return nil
+}
+
+// Caller has to hold the mutex
+func (scheduler *Scheduler) findWindow(name string) *MaintenanceWindow {
+	for i := range scheduler.store.Windows {
+		if scheduler.store.Windows[i].Name == name {
+			return &scheduler.store.Windows[i]
+		}
+	}
+	return nil
+}
+
+////////////////////////
+// Running changes
+
+func (scheduler *Scheduler) run() {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		for scheduler.runNext() {
+		}
+		select {
+		case <-ticker.C:
+		case <-scheduler.wake:
+		}
+	}
+}
+
+// Runs the earliest change that is due. Returns false if nothing was.
+func (scheduler *Scheduler) runNext() bool {
+	scheduler.mutex.Lock()
+
+	// 1. Find the earliest due change
+	now := time.Now()
+	var change *ScheduledChange
+	for i := range scheduler.store.Changes {
+		candidate := &scheduler.store.Changes[i]
+		if candidate.Status == Pending && !candidate.RunAt.After(now) && (change == nil || candidate.RunAt.Before(change.RunAt)) {
+			change = candidate
+		}
+	}
+	if change == nil {
+		scheduler.mutex.Unlock()
+		return false
+	}
+
+	// 2. Windows that closed while PostgreScrutiniser wasn't running are not used
+	if change.Window != nil {
+		if window := scheduler.findWindow(*change.Window); window != nil {
+			if runAt, err := window.next(now); err == nil && runAt.After(now) {
+				scheduler.logger.LogWarning(fmt.Errorf("scheduled change %s missed maintenance window %s, moved to %s", change.Id, window.Name, runAt.Format(time.RFC3339)))
+				change.RunAt = runAt
+				scheduler.save()
+				scheduler.mutex.Unlock()
+				return true
+			}
+		}
+	}
+
+	// 3. Mark it running so it can't be cancelled or picked up twice
+	change.Status = Running
+	change.StartedAt = &now
+	scheduler.save()
+	running := *change
+	executor := scheduler.executors[change.Kind]
+	scheduler.mutex.Unlock()
+
+	// 4. Run without holding the mutex, restarts can take a while
+	outcome, err := execute(executor, &running)
+	if err != nil {
+		scheduler.logger.LogError(fmt.Errorf("scheduled change %s failed: %v", running.Id, err))
+	}
+
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	if change = scheduler.findChange(running.Id); change != nil {
+		finish(change, outcome, err)
+		scheduler.save()
+	}
+	return true
+}
+
+// Runs @change with @executor, turning a panic into an error so the scheduler keeps going
+func execute(executor Executor, change *ScheduledChange) (outcome *ChangeOutcome, err error) {
+	if executor == nil {
+		return nil, fmt.Errorf("no executor registered for %s changes", change.Kind)
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			outcome, err = nil, fmt.Errorf("%s change panicked: %v", change.Kind, recovered)
+		}
+	}()
+	return executor(change)
+}
+
+// Records @outcome of @change, which failed if @err is set
+func finish(change *ScheduledChange, outcome *ChangeOutcome, err error) {
+	if outcome == nil {
+		outcome = &ChangeOutcome{Steps: []ConfigChangeStep{}}
+	}
+	change.Status = Succeeded
+	if err != nil {
+		message := err.Error()
+		outcome.Error = &message
+		change.Status = Failed
+	}
+	finished := time.Now()
+	change.FinishedAt = &finished
+	change.Outcome = outcome
+}
@@ -0,0 +1,216 @@
+// Package schedule provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.12.4 DO NOT EDIT.
+package schedule
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/deepmap/oapi-codegen/pkg/runtime"
+	"github.com/gin-gonic/gin"
+)
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+
+	// (GET /maintenance-windows)
+	GetMaintenanceWindows(c *gin.Context)
+
+	// (DELETE /maintenance-windows/{window_name})
+	DeleteMaintenanceWindow(c *gin.Context, windowName WindowName)
+
+	// (PUT /maintenance-windows/{window_name})
+	PutMaintenanceWindow(c *gin.Context, windowName WindowName)
+
+	// (GET /scheduled-changes)
+	GetScheduledChanges(c *gin.Context, params GetScheduledChangesParams)
+
+	// (DELETE /scheduled-changes/{change_id})
+	CancelScheduledChange(c *gin.Context, changeId ChangeId)
+
+	// (GET /scheduled-changes/{change_id})
+	GetScheduledChange(c *gin.Context, changeId ChangeId)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandler       func(*gin.Context, error, int)
+}
+
+type MiddlewareFunc func(c *gin.Context)
+
+// GetMaintenanceWindows operation middleware
+func (siw *ServerInterfaceWrapper) GetMaintenanceWindows(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetMaintenanceWindows(c)
+}
+
+// DeleteMaintenanceWindow operation middleware
+func (siw *ServerInterfaceWrapper) DeleteMaintenanceWindow(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "window_name" -------------
+	var windowName WindowName
+
+	err = runtime.BindStyledParameter("simple", false, "window_name", c.Param("window_name"), &windowName)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter window_name: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.DeleteMaintenanceWindow(c, windowName)
+}
+
+// PutMaintenanceWindow operation middleware
+func (siw *ServerInterfaceWrapper) PutMaintenanceWindow(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "window_name" -------------
+	var windowName WindowName
+
+	err = runtime.BindStyledParameter("simple", false, "window_name", c.Param("window_name"), &windowName)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter window_name: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.PutMaintenanceWindow(c, windowName)
+}
+
+// GetScheduledChanges operation middleware
+func (siw *ServerInterfaceWrapper) GetScheduledChanges(c *gin.Context) {
+
+	var err error
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetScheduledChangesParams
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", c.Request.URL.Query(), &params.Status)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter status: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetScheduledChanges(c, params)
+}
+
+// CancelScheduledChange operation middleware
+func (siw *ServerInterfaceWrapper) CancelScheduledChange(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "change_id" -------------
+	var changeId ChangeId
+
+	err = runtime.BindStyledParameter("simple", false, "change_id", c.Param("change_id"), &changeId)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter change_id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.CancelScheduledChange(c, changeId)
+}
+
+// GetScheduledChange operation middleware
+func (siw *ServerInterfaceWrapper) GetScheduledChange(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "change_id" -------------
+	var changeId ChangeId
+
+	err = runtime.BindStyledParameter("simple", false, "change_id", c.Param("change_id"), &changeId)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter change_id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{""})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.GetScheduledChange(c, changeId)
+}
+
+// GinServerOptions provides options for the Gin server.
+type GinServerOptions struct {
+	BaseURL      string
+	Middlewares  []MiddlewareFunc
+	ErrorHandler func(*gin.Context, error, int)
+}
+
+// RegisterHandlers creates http.Handler with routing matching OpenAPI spec.
+func RegisterHandlers(router *gin.Engine, si ServerInterface) *gin.Engine {
+	return RegisterHandlersWithOptions(router, si, GinServerOptions{})
+}
+
+// RegisterHandlersWithOptions creates http.Handler with additional options
+func RegisterHandlersWithOptions(router *gin.Engine, si ServerInterface, options GinServerOptions) *gin.Engine {
+
+	errorHandler := options.ErrorHandler
+
+	if errorHandler == nil {
+		errorHandler = func(c *gin.Context, err error, statusCode int) {
+			c.JSON(statusCode, gin.H{"msg": err.Error()})
+		}
+	}
+
+	wrapper := ServerInterfaceWrapper{
+		Handler:            si,
+		HandlerMiddlewares: options.Middlewares,
+		ErrorHandler:       errorHandler,
+	}
+
+	router.GET(options.BaseURL+"/maintenance-windows", wrapper.GetMaintenanceWindows)
+
+	router.DELETE(options.BaseURL+"/maintenance-windows/:window_name", wrapper.DeleteMaintenanceWindow)
+
+	router.PUT(options.BaseURL+"/maintenance-windows/:window_name", wrapper.PutMaintenanceWindow)
+
+	router.GET(options.BaseURL+"/scheduled-changes", wrapper.GetScheduledChanges)
+
+	router.DELETE(options.BaseURL+"/scheduled-changes/:change_id", wrapper.CancelScheduledChange)
+
+	router.GET(options.BaseURL+"/scheduled-changes/:change_id", wrapper.GetScheduledChange)
+
+	return router
+}
@@ -0,0 +1,110 @@
+/*
+This is where the implementation of automatically
+generated scheduled changes route goes
+*/
+package schedule
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduleImpl struct {
+	Scheduler *Scheduler
+	Logger    *utils.Logger
+}
+
+// Lists scheduled changes, soonest first
+func (impl *ScheduleImpl) GetScheduledChanges(c *gin.Context, params GetScheduledChangesParams) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+	c.JSON(http.StatusAccepted, impl.Scheduler.Changes(params.Status))
+}
+
+// Returns a single scheduled change, with its outcome once it ran
+func (impl *ScheduleImpl) GetScheduledChange(c *gin.Context, changeId ChangeId) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	change, err := impl.Scheduler.Change(changeId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, &ErrorMessage{ErrorMessage: err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, change)
+}
+
+// Cancels a change that hasn't started yet
+func (impl *ScheduleImpl) CancelScheduledChange(c *gin.Context, changeId ChangeId) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	change, err := impl.Scheduler.Cancel(changeId)
+	switch {
+	case errors.Is(err, ErrChangeNotFound):
+		c.JSON(http.StatusNotFound, &ErrorMessage{ErrorMessage: err.Error()})
+	case errors.Is(err, ErrNotPending):
+		c.JSON(http.StatusConflict, &ErrorMessage{ErrorMessage: err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, &ErrorMessage{ErrorMessage: "Could not cancel change. See /var/log/postgrescrutiniser/error.log for more details"})
+	default:
+		c.JSON(http.StatusOK, change)
+	}
+}
+
+// Lists maintenance windows
+func (impl *ScheduleImpl) GetMaintenanceWindows(c *gin.Context) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+	c.JSON(http.StatusAccepted, impl.Scheduler.Windows())
+}
+
+// Creates or replaces a maintenance window
+func (impl *ScheduleImpl) PutMaintenanceWindow(c *gin.Context, windowName WindowName) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	schedule := MaintenanceWindowSchedule{}
+	if err := c.BindJSON(&schedule); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, &ErrorMessage{ErrorMessage: "incorrect payload format"})
+		return
+	}
+
+	window, err := impl.Scheduler.PutWindow(windowName, schedule)
+	if errors.Is(err, ErrInvalidWindow) {
+		c.JSON(http.StatusBadRequest, &ErrorMessage{ErrorMessage: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &ErrorMessage{ErrorMessage: "Could not save maintenance window. See /var/log/postgrescrutiniser/error.log for more details"})
+		return
+	}
+	c.JSON(http.StatusOK, window)
+}
+
+// Removes a maintenance window no pending change is scheduled into
+func (impl *ScheduleImpl) DeleteMaintenanceWindow(c *gin.Context, windowName WindowName) {
+	if len(c.Errors) > 0 || c.Writer.Status() >= 400 {
+		return
+	}
+
+	err := impl.Scheduler.DeleteWindow(windowName)
+	switch {
+	case errors.Is(err, ErrWindowNotFound):
+		c.JSON(http.StatusNotFound, &ErrorMessage{ErrorMessage: err.Error()})
+	case errors.Is(err, ErrWindowInUse):
+		c.JSON(http.StatusConflict, &ErrorMessage{ErrorMessage: err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, &ErrorMessage{ErrorMessage: "Could not remove maintenance window. See /var/log/postgrescrutiniser/error.log for more details"})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,130 @@
+// Package schedule provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.12.4 DO NOT EDIT.
+package schedule
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Base64 encoded, gzipped, json marshaled Swagger object
+var swaggerSpec = []string{
+
+	"H4sIAAAAAAAC/+xaX3PcthH/KjtoZvpC3Z0UxY7VJ8VJY7Wxo0ZOPVNbvYOIvSMiEqCBhWRGc9+9A/Dv",
+	"kZAsdxyNOvWTdSSwWOz+dve3S9+wVBelVqjIsqMbVnLDCyQ04VeacbXBE+H/FmhTI0uSWrEjdiJAr4Ey",
+	"BJtmKFyOAurVLGHSryg5ZSxhihfIjhpJSylYwgy+d9KgYEdkHCbMSyi4P4Oq0i+2ZKTasO02YddSCX39",
+	"KggZ6+CftloUXCpCxVWKUO9hCcMPvCjzINEpwas9JTcZxRWsNy3Dr7tULDkRGr//32/53u/He/9a7D1b",
+	"7p3f7CdPDrdfsWRyiW27PZj0B2O0eYnW8k24Uml0iYYkhrfo3y6L/vXUIr1qb0fLz7uz9cVvmBLbJo3d",
+	"/y5V8CEqV/h9vCzzamndZoPWG9OGK1vSBpcXPL105UBYe3Yr7GdHqS5uU37qpjdZFVxU74Y1lzkKFhHv",
+	"NeCGUMRkIGVo4FRb2hg8+8dPcM0t9Ds6cRda58hVkKfzHEW40L0kCinUnwn85cBvAq5E0Lw0eCW1s1DW",
+	"i+37fMYd6Vmq1TooUjoKW6J6WMIyGEgSFuGPrwyu2RH707wPvnmDkbmXKTfPg7HOCEu27WRyY3g1AcHQ",
+	"CMMrtwffDosz4uTsEBglKuG9kTDjlKr/si5NEUWQ3zkv9ZHmz7oDKGcdvqZYEZz4BbeRqP6+eVMnl04E",
+	"SAsethIFrLVJwuvrTOcIae4soQG5BoUyeLUVD0obMH6RtLCRV6hiwFNNehnki4wbb0e3XqOxUbDqPKL8",
+	"L/6oOxWPyWoWo1he8dyNVDl4crB/eDjdNkJBk7jGoqLOHyNs4h2etl5rgWEw11ywLkhZwjLkOWXLNMP0",
+	"soFegztvm6VFIqk2NgoQgcRlbmNhySlElNAKE9AGrrMKJN2RNgI+rR1kyy72RjZqrtVv6RWZ2ClhH/Y2",
+	"eq956EjmdjYw2eD1nixKbagpDhk7YhtJmbuYpbqY/5jri8ouvt6ft4kmNY6kkhbN3JsLlZgH6UHbQRl7",
+	"U1exSORUNhY1la1DImwDXaKyoFUCeIWmAsErHx9YlFSx5H6Z6BrxUviEM05ACRPOcH/yspDKEUYUeqGv",
+	"IddqM1TKktfSq5YAJyi0JeBetWGt3j9YdOd5Y2zQ3BKjuzV9iouA04lir2XNGrxFxgZL4MWLo5cvh+qw",
+	"xcHRYhGTT7LA37WKZIGT41fH4F+Df+8PWwVlVjuCf3Der/N/ylxJZ+8b4MH97eUijhjoFYv9CcDOGvr2",
+	"mYGW45pAO/qfxNojRE6y+9Qfb9FcoRkZ+7+G162AiqGo4/x1RpxipyaRSxXl7d+Fl9AQTgEXFax22eeq",
+	"IYt250Yx9rXcf/L02TdPnj7dP4hZOjXIfTHkwZ1rbQr/FxOccM8b9K49F9VU9V8tGiBfo/q2p+e2CRhM",
+	"tREoQKrwnDshCXK9gesMla9jxqkon1j7mpB9oqpSRHqEhEllyUd4BF/NmyEhN64JYP9M4Jq7nKAVcSu8",
+	"moUxrS6bbuNOltv3JduE6b6l+Pimtv/YBpLa2GtC7tX4ijOowWoHrpOKNPA2kLlBKPSVd6kGSRYUfqB3",
+	"SqepMwYbYwwC39OUQlqLIgGcbWZwgSl3FmFa6v1a31s0rPqdYsk9Xdxw+0+Che14/cet2fQAPQsN7eDE",
+	"oj2X79msj9tJJzkM3fv1O+NeIVICrjsutKvVy0nXP/S698+urz+aBcN8IuC3s+JOEukgF8uKbQ0b0OYi",
+	"0E1yyPxrL5Uy531vpD8hSLRORTiydwimzkiqfIkumryK3KA5dpT1v/7aYuJvb16zZtYQWHB42185Iyrr",
+	"cYRUax1x8WiQY+H49GQGq9Pj189fwNyg1c6kuApt8er019cwr7P1/GaQ7rcr4GmKJbXY4LR657uwVe2g",
+	"lQ8u4xTkPPRsyhLyMEwynsgBv+bVXzoNfEheemFagZD2EpwimXsnV2C4CnFEkkJGmkYdS9gVGltfb3+2",
+	"mC1CsilR8VKyI/Z1eJQE2h7MOx/Qo71a3/B8g5Ek85O0ZCODJ9tpn3IFFzjFoK+Uocj6yRr7EenlmJU1",
+	"A5lSK1s7/mBx4P9JtV8XlAlRmAYx899s3a31Y6p7hd6025jOGrbJ6NpN7wSten7P4WL/k7S7S6mdAVnk",
+	"/Nf6ElXIu1JtfINY8NynRRR1Y1mXpYfSxin8UGJKKKAef/klxDc2cKrG8ezcP42Ba34zmDtua4zlSLHJ",
+	"AvrSZIFHEFczEqWhGd+0CVDaj2Hv+3DaBH4s2RkEv43bqF8yH4xpt+cT6B5GKnRfQk24mXhcODpcHD6Y",
+	"Jq90xygkZUCZtBCYc9Dj2YPpcbqDnjr5jrhSz368ct8sFg+m3Fnd7VgpsI20xxvtCStdpGI8D0TCerAZ",
+	"LHOe3hLPMxi7YuQGSe9UlK1CT1bfqUm0nzr6A0L9vUNL32lRfTY33D6k2G63448z20m6WfxxisRAMUhl",
+	"lneJ7OEi40Rd8VyKQVw+njT6JUPcwQfan2KvbZnupprjL602Aau1QkuwlsZSjFme7U5q7DTcd0/6WeUV",
+	"5NJSl3r6mtQ1ROHz6XuHpuq/n3Yv72fH3eZze/4QdHc8tfpCdh8S3POb7v8A3El0n4cvi74w1utrcpvV",
+	"85NmGgIV0gxOyDPc0B4247YAXG6h/zo5Dola+igqPrkGdv8vIgLcz5fwJnideuR5P+no7/z/TKTb0U+X",
+	"tKR4aBrd+ITnBrmoWsgm0E54w0fNscO+FMpbqXS0Iv6C5IzyWWJcFZPa+Z4RN2Nl0GF2S2C4ukeN/KzZ",
+	"4OAhs8HjrlaPIxM82po5GPgG0A1HvW/PPbTqD341JJ3Jm5Hu0Xye65TnmbZ09O3i28Wcl5Jtz7f/GQAs",
+	"+3DF2ycAAA==",
+}
+
+// GetSwagger returns the content of the embedded swagger specification file
+// or error if failed to decode
+func decodeSpec() ([]byte, error) {
+	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error base64 decoding spec: %s", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var rawSpec = decodeSpecCached()
+
+// a naive cached of a decoded swagger spec
+func decodeSpecCached() func() ([]byte, error) {
+	data, err := decodeSpec()
+	return func() ([]byte, error) {
+		return data, err
+	}
+}
+
+// Constructs a synthetic filesystem for resolving external references when loading openapi specifications.
+func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
+	var res = make(map[string]func() ([]byte, error))
+	if len(pathToFile) > 0 {
+		res[pathToFile] = rawSpec
+	}
+
+	return res
+}
+
+// GetSwagger returns the Swagger specification corresponding to the generated code
+// in this file. The external references of Swagger specification are resolved.
+// The logic of resolving external references is tightly connected to "import-mapping" feature.
+// Externally referenced files must be embedded in the corresponding golang packages.
+// Urls can be supported but this task was out of the scope.
+func GetSwagger() (swagger *openapi3.T, err error) {
+	var resolvePath = PathToRawSpec("")
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, url *url.URL) ([]byte, error) {
+		var pathToFile = url.String()
+		pathToFile = path.Clean(pathToFile)
+		getSpec, ok := resolvePath[pathToFile]
+		if !ok {
+			err1 := fmt.Errorf("path not found: %s", pathToFile)
+			return nil, err1
+		}
+		return getSpec()
+	}
+	var specData []byte
+	specData, err = rawSpec()
+	if err != nil {
+		return
+	}
+	swagger, err = loader.LoadFromData(specData)
+	if err != nil {
+		return
+	}
+	return
+}
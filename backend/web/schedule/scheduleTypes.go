@@ -0,0 +1,156 @@
+// Package schedule provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.12.4 DO NOT EDIT.
+package schedule
+
+import (
+	"time"
+
+	"github.com/Globys031/PostgreScrutiniser/backend/utils"
+)
+
+const (
+	BearerAuthScopes = "bearerAuth.Scopes"
+)
+
+// Defines values for ChangeKind.
+const (
+	ApplySuggestions ChangeKind = "apply_suggestions"
+	RestoreBackup    ChangeKind = "restore_backup"
+)
+
+// Defines values for ChangeStatus.
+const (
+	Cancelled ChangeStatus = "cancelled"
+	Failed    ChangeStatus = "failed"
+	Pending   ChangeStatus = "pending"
+	Running   ChangeStatus = "running"
+	Succeeded ChangeStatus = "succeeded"
+)
+
+// Defines values for Weekday.
+const (
+	Fri Weekday = "fri"
+	Mon Weekday = "mon"
+	Sat Weekday = "sat"
+	Sun Weekday = "sun"
+	Thu Weekday = "thu"
+	Tue Weekday = "tue"
+	Wed Weekday = "wed"
+)
+
+// ErrorMessage defines model for ErrorMessage.
+type ErrorMessage struct {
+	ErrorMessage string `json:"error_message"`
+}
+
+// ChangeKind defines model for changeKind.
+type ChangeKind string
+
+// ChangeOutcome defines model for changeOutcome.
+type ChangeOutcome struct {
+	// Error Why the change failed
+	Error *string `json:"error,omitempty"`
+
+	// Restarted Whether PostgreSQL was restarted
+	Restarted bool `json:"restarted"`
+
+	// RolledBack Whether PostgreSQL didn't come back and the previous postgresql.auto.conf was put back
+	RolledBack bool               `json:"rolled_back"`
+	Steps      []ConfigChangeStep `json:"steps"`
+}
+
+// ChangeStatus defines model for changeStatus.
+type ChangeStatus string
+
+// ChangeSuggestion defines model for changeSuggestion.
+type ChangeSuggestion struct {
+	// Database Database the suggestion is applied for, the whole cluster if neither database nor role is given
+	Database *string `json:"database,omitempty"`
+	Name     string  `json:"name"`
+
+	// Role Role the suggestion is applied for
+	Role           *string `json:"role,omitempty"`
+	SuggestedValue string  `json:"suggested_value"`
+}
+
+// ConfigChangeStep defines model for configChangeStep.
+type ConfigChangeStep = utils.ChangeStep
+
+// MaintenanceWindow defines model for maintenanceWindow.
+type MaintenanceWindow struct {
+	// Days Days the window opens on, every day if empty
+	Days []Weekday `json:"days"`
+
+	// DurationMinutes How long the window stays open, at most a day
+	DurationMinutes int    `json:"duration_minutes"`
+	Name            string `json:"name"`
+
+	// Start Time of day the window opens, HH:MM
+	Start string `json:"start"`
+
+	// Timezone IANA time zone of `start`
+	Timezone string `json:"timezone"`
+}
+
+// MaintenanceWindowSchedule defines model for maintenanceWindowSchedule.
+type MaintenanceWindowSchedule struct {
+	// Days Days the window opens on, every day if left out
+	Days *[]Weekday `json:"days,omitempty"`
+
+	// DurationMinutes How long the window stays open, at most a day
+	DurationMinutes int `json:"duration_minutes"`
+
+	// Start Time of day the window opens, HH:MM
+	Start string `json:"start"`
+
+	// Timezone IANA time zone of `start`, time zone of the server if left out
+	Timezone *string `json:"timezone,omitempty"`
+}
+
+// ScheduledChange defines model for scheduledChange.
+type ScheduledChange struct {
+	// BackupName Backup restored by `restore_backup` changes
+	BackupName *string   `json:"backup_name,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// CreatedBy User that scheduled the change, recorded in the audit log when it runs
+	CreatedBy  *string    `json:"created_by,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Id         string     `json:"id"`
+
+	// Instance Instance the change runs on, the default instance if left out
+	Instance *string        `json:"instance,omitempty"`
+	Kind     ChangeKind     `json:"kind"`
+	Outcome  *ChangeOutcome `json:"outcome,omitempty"`
+
+	// RunAt When the change runs. Changes scheduled into a window are moved to its next
+	// occurrence if the window was missed, e.g. because PostgreScrutiniser wasn't running
+	RunAt     time.Time    `json:"run_at"`
+	StartedAt *time.Time   `json:"started_at,omitempty"`
+	Status    ChangeStatus `json:"status"`
+
+	// Suggestions Suggestions applied by `apply_suggestions` changes
+	Suggestions *[]ChangeSuggestion `json:"suggestions,omitempty"`
+
+	// Window Maintenance window the change was scheduled into
+	Window *string `json:"window,omitempty"`
+}
+
+// Weekday defines model for weekday.
+type Weekday string
+
+// ChangeId defines model for changeId.
+type ChangeId = string
+
+// WindowName defines model for windowName.
+type WindowName = string
+
+// GetScheduledChangesParams defines parameters for GetScheduledChanges.
+type GetScheduledChangesParams struct {
+	// Status Only list changes with this status
+	Status *ChangeStatus `form:"status,omitempty" json:"status,omitempty"`
+}
+
+// PutMaintenanceWindowJSONRequestBody defines body for PutMaintenanceWindow for application/json ContentType.
+type PutMaintenanceWindowJSONRequestBody = MaintenanceWindowSchedule
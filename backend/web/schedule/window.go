@@ -0,0 +1,107 @@
+// Maintenance windows changes can be scheduled into. A window opens at the same
+// time of day on selected weekdays and stays open for at most a day.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+var ErrInvalidWindow = errors.New("invalid maintenance window")
+
+var windowNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+var weekdays = map[Weekday]time.Weekday{
+	Mon: time.Monday,
+	Tue: time.Tuesday,
+	Wed: time.Wednesday,
+	Thu: time.Thursday,
+	Fri: time.Friday,
+	Sat: time.Saturday,
+	Sun: time.Sunday,
+}
+
+// Builds window @name from the body of `PUT /maintenance-windows/{window_name}`
+func newMaintenanceWindow(name string, schedule MaintenanceWindowSchedule) (*MaintenanceWindow, error) {
+	window := &MaintenanceWindow{
+		Name:            name,
+		Days:            []Weekday{},
+		Start:           schedule.Start,
+		DurationMinutes: schedule.DurationMinutes,
+		Timezone:        time.Local.String(),
+	}
+	if schedule.Days != nil {
+		window.Days = *schedule.Days
+	}
+	if schedule.Timezone != nil && *schedule.Timezone != "" {
+		window.Timezone = *schedule.Timezone
+	}
+	if err := window.validate(); err != nil {
+		return nil, err
+	}
+	return window, nil
+}
+
+func (window *MaintenanceWindow) validate() error {
+	if !windowNamePattern.MatchString(window.Name) {
+		return fmt.Errorf("%w: name has to match %s", ErrInvalidWindow, windowNamePattern)
+	}
+	for _, day := range window.Days {
+		if _, ok := weekdays[day]; !ok {
+			return fmt.Errorf("%w: unknown day %q, expected mon, tue, wed, thu, fri, sat or sun", ErrInvalidWindow, day)
+		}
+	}
+	if _, err := time.Parse("15:04", window.Start); err != nil {
+		return fmt.Errorf("%w: start %q has to be a time of day in HH:MM format", ErrInvalidWindow, window.Start)
+	}
+	if window.DurationMinutes < 1 || window.DurationMinutes > 24*60 {
+		return fmt.Errorf("%w: duration has to be between 1 and 1440 minutes", ErrInvalidWindow)
+	}
+	if _, err := time.LoadLocation(window.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown time zone %q", ErrInvalidWindow, window.Timezone)
+	}
+	return nil
+}
+
+// Whether the window opens on @day. Windows without days open every day.
+func (window *MaintenanceWindow) opensOn(day time.Weekday) bool {
+	if len(window.Days) == 0 {
+		return true
+	}
+	for _, windowDay := range window.Days {
+		if weekdays[windowDay] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns @after if the window is open at that time, otherwise when it opens next
+func (window *MaintenanceWindow) next(after time.Time) (time.Time, error) {
+	location, err := time.LoadLocation(window.Timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: unknown time zone %q", ErrInvalidWindow, window.Timezone)
+	}
+	start, err := time.Parse("15:04", window.Start)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: start %q has to be a time of day in HH:MM format", ErrInvalidWindow, window.Start)
+	}
+	duration := time.Duration(window.DurationMinutes) * time.Minute
+
+	// Windows last at most a day, so one that opened yesterday may still be open
+	local := after.In(location)
+	for day := -1; day <= 7; day++ {
+		date := local.AddDate(0, 0, day)
+		opens := time.Date(date.Year(), date.Month(), date.Day(), start.Hour(), start.Minute(), 0, 0, location)
+		if !window.opensOn(opens.Weekday()) || !opens.Add(duration).After(after) {
+			continue
+		}
+		if opens.Before(after) {
+			return after, nil
+		}
+		return opens, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: %s never opens", ErrInvalidWindow, window.Name)
+}